@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/metricstream"
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// awsRegionPattern matches AWS region names (eg. eu-west-3, us-gov-west-1, cn-north-1)
+var awsRegionPattern = regexp.MustCompile(`^[a-z]{2}(-gov|-iso[a-z]?)?-[a-z]+-\d+$`)
+
+var (
+	errNoRegion             = errors.New("aws-regions must contain at least one region")
+	errInvalidRegion        = errors.New("invalid AWS region")
+	errInvalidRoleArn       = errors.New("aws-assume-role-arn must be an AWS IAM role ARN")
+	errInvalidListenAddress = errors.New("invalid listen-address")
+	errInvalidLogFormat     = errors.New("log-format must be text or json")
+	errInvalidMetricFormat  = errors.New("metric-stream-format must be json or opentelemetry0.7")
+	errInvalidTopSQL        = errors.New("performance-insights-top-sql must be between 1 and 25")
+	errInvalidThreshold     = errors.New("rightsizing-threshold must be between 0 and 1")
+	errUnknownModuleKeys    = errors.New("unknown keys")
+)
+
+func newCheckConfigCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check-config",
+		Short: "Validate configuration and print the effective configuration",
+		Long: `Strictly decode configuration from flags, environment variables and configuration file,
+	validate it and print the effective configuration with the source of each parameter.
+	Exit with a non-zero code if the configuration is invalid.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !checkConfig(cmd.OutOrStdout(), cmd.Flags()) {
+				os.Exit(configErrorExitCode)
+			}
+		},
+	}
+}
+
+// checkConfig prints the effective configuration and its errors, returns true if the configuration is valid
+func checkConfig(w io.Writer, flags *pflag.FlagSet) bool {
+	// Configuration file errors are ignored at startup, read it again to report them
+	if viper.ConfigFileUsed() != "" {
+		err := viper.ReadInConfig()
+		if err != nil {
+			fmt.Fprintln(w, "ERROR: Unable to read configuration file:", err)
+
+			return false
+		}
+	}
+
+	var configuration exporterConfig
+
+	err := viper.UnmarshalExact(&configuration)
+	if err != nil {
+		fmt.Fprintln(w, "ERROR: Unable to decode configuration:", err)
+
+		return false
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "PARAMETER\tVALUE\tSOURCE")
+
+	for _, parameter := range getRedactedConfiguration(configuration) {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", parameter.Name, parameter.Value, getParameterSource(flags, parameter.Name))
+	}
+
+	_ = table.Flush()
+
+	errs := validateConfiguration(configuration)
+	errs = append(errs, validateProbeModules(configuration)...)
+
+	for _, err := range errs {
+		fmt.Fprintln(w, "ERROR:", err)
+	}
+
+	if len(errs) > 0 {
+		return false
+	}
+
+	fmt.Fprintln(w, "Configuration is valid")
+
+	return true
+}
+
+// getParameterSource returns where the parameter value comes from, by precedence order
+func getParameterSource(flags *pflag.FlagSet, name string) string {
+	switch {
+	case flags.Changed(name):
+		return "flag"
+	case os.Getenv(getEnvName(name)) != "":
+		return "env"
+	case viper.InConfig(name):
+		return "file"
+	default:
+		return "default"
+	}
+}
+
+// validateConfiguration returns errors of parameters that would fail at startup or at AWS call time
+func validateConfiguration(configuration exporterConfig) []error {
+	errs := validateCollectorConfiguration(configuration)
+
+	if _, port, err := net.SplitHostPort(configuration.ListenAddress); err != nil {
+		errs = append(errs, fmt.Errorf("%w '%s': %w", errInvalidListenAddress, configuration.ListenAddress, err))
+	} else if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		errs = append(errs, fmt.Errorf("%w '%s': invalid port", errInvalidListenAddress, configuration.ListenAddress))
+	}
+
+	errs = append(errs, validateTLS(configuration)...)
+
+	if configuration.LogFormat != "text" && configuration.LogFormat != "json" {
+		errs = append(errs, fmt.Errorf("%w: '%s'", errInvalidLogFormat, configuration.LogFormat))
+	}
+
+	if configuration.MetricStreamEnabled && configuration.MetricStreamFormat != metricstream.FormatJSON && configuration.MetricStreamFormat != metricstream.FormatOpenTelemetry {
+		errs = append(errs, fmt.Errorf("%w: '%s'", errInvalidMetricFormat, configuration.MetricStreamFormat))
+	}
+
+	return errs
+}
+
+// validateCollectorConfiguration returns errors of parameters used by collectors, which can be overridden by probe modules
+func validateCollectorConfiguration(configuration exporterConfig) []error {
+	var errs []error
+
+	if len(configuration.AWSRegions) == 0 {
+		errs = append(errs, errNoRegion)
+	}
+
+	for _, region := range configuration.AWSRegions {
+		if !awsRegionPattern.MatchString(region) {
+			errs = append(errs, fmt.Errorf("%w: '%s'", errInvalidRegion, region))
+		}
+	}
+
+	if configuration.AWSAssumeRoleArn != "" {
+		roleArn, err := arn.Parse(configuration.AWSAssumeRoleArn)
+		if err != nil || roleArn.Service != "iam" || !strings.HasPrefix(roleArn.Resource, "role/") {
+			errs = append(errs, fmt.Errorf("%w: '%s'", errInvalidRoleArn, configuration.AWSAssumeRoleArn))
+		}
+	}
+
+	if configuration.CollectPerformanceInsights && (configuration.PerformanceInsightsTopSQL < 1 || configuration.PerformanceInsightsTopSQL > 25) {
+		errs = append(errs, fmt.Errorf("%w: %d", errInvalidTopSQL, configuration.PerformanceInsightsTopSQL))
+	}
+
+	if configuration.RightsizingEnabled && (configuration.RightsizingThreshold <= 0 || configuration.RightsizingThreshold > 1) {
+		errs = append(errs, fmt.Errorf("%w: %g", errInvalidThreshold, configuration.RightsizingThreshold))
+	}
+
+	return errs
+}
+
+// validateTLS checks TLS certificate and key are set together, readable and not combined with a web configuration file
+func validateTLS(configuration exporterConfig) []error {
+	var errs []error
+
+	if (configuration.TLSCertPath == "") != (configuration.TLSKeyPath == "") {
+		errs = append(errs, webserver.ErrTLSPair)
+	}
+
+	for _, path := range []string{configuration.TLSCertPath, configuration.TLSKeyPath} {
+		if path == "" {
+			continue
+		}
+
+		if _, err := os.Stat(path); err != nil {
+			errs = append(errs, fmt.Errorf("can't read TLS file: %w", err))
+		}
+	}
+
+	if configuration.WebConfigFile != "" {
+		if configuration.TLSCertPath != "" || configuration.TLSKeyPath != "" {
+			errs = append(errs, webserver.ErrTLSConflict)
+		}
+
+		err := webserver.ValidateWebConfig(configuration.WebConfigFile)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// validateProbeModules rejects unknown keys of probe modules and validates their effective configuration
+func validateProbeModules(configuration exporterConfig) []error {
+	var errs []error
+
+	known := make(map[string]bool)
+
+	value := reflect.TypeOf(configuration)
+	for i := 0; i < value.NumField(); i++ {
+		known[value.Field(i).Tag.Get("mapstructure")] = true
+	}
+
+	for name := range viper.GetStringMap("probe-modules") {
+		var unknown []string
+
+		for key := range viper.GetStringMap("probe-modules." + name) {
+			if !known[key] {
+				unknown = append(unknown, key)
+			}
+		}
+
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			errs = append(errs, fmt.Errorf("probe module %s: %w: %s", name, errUnknownModuleKeys, strings.Join(unknown, ", ")))
+		}
+	}
+
+	modules, err := getProbeModules(configuration)
+	if err != nil {
+		return append(errs, err)
+	}
+
+	// Errors inherited from the exporter configuration are already reported
+	inherited := make(map[string]bool)
+	for _, err := range validateCollectorConfiguration(configuration) {
+		inherited[err.Error()] = true
+	}
+
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, err := range validateCollectorConfiguration(modules[name]) {
+			if !inherited[err.Error()] {
+				errs = append(errs, fmt.Errorf("probe module %s: %w", name, err))
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runCheckConfig checks the configuration of flags, environment variables and configuration file content like check-config command
+func runCheckConfig(t *testing.T, args []string, env map[string]string, file string) (bool, string) {
+	t.Helper()
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	cfgFile = ""
+	t.Cleanup(func() { cfgFile = "" })
+
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+
+	if file != "" {
+		path := filepath.Join(t.TempDir(), "prometheus-rds-exporter.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(file), 0o600), "Configuration file must be written")
+
+		args = append(args, "--config", path)
+	}
+
+	cmd, err := NewRootCommand()
+	require.NoError(t, err, "Root command must be initialized")
+
+	flags := cmd.PersistentFlags()
+	require.NoError(t, flags.Parse(args), "Flags must be parsed")
+
+	initConfig()
+
+	var output bytes.Buffer
+	valid := checkConfig(&output, flags)
+
+	return valid, output.String()
+}
+
+func TestCheckConfig(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		file    string
+		valid   bool
+		message string
+	}{
+		{name: "defaults", valid: true, message: "Configuration is valid"},
+		{name: "valid file", file: "aws-regions: [eu-west-3, us-gov-west-1]\ncollect-quotas: true\n", valid: true, message: "Configuration is valid"},
+		{name: "unknown key", file: "aws-region: eu-west-3\n", message: "Unable to decode configuration"},
+		{name: "invalid file", file: "aws-regions: [eu-west-3\n", message: "Unable to read configuration file"},
+		{name: "bad region", args: []string{"--aws-regions", "europe"}, message: errInvalidRegion.Error() + ": 'europe'"},
+		{name: "no region", file: "aws-regions: []\n", message: errNoRegion.Error()},
+		{name: "bad role ARN", args: []string{"--aws-assume-role-arn", "arn:aws:s3:::my-bucket"}, message: errInvalidRoleArn.Error()},
+		{name: "malformed role ARN", args: []string{"--aws-assume-role-arn", "my-role"}, message: errInvalidRoleArn.Error()},
+		{name: "valid role ARN", args: []string{"--aws-assume-role-arn", "arn:aws-cn:iam::123456789012:role/exporter"}, valid: true, message: "Configuration is valid"},
+		{name: "listen address without port", args: []string{"--listen-address", "localhost"}, message: errInvalidListenAddress.Error() + " 'localhost'"},
+		{name: "listen address with bad port", args: []string{"--listen-address", ":99999"}, message: errInvalidListenAddress.Error() + " ':99999': invalid port"},
+		{name: "TLS certificate without key", args: []string{"--tls-cert-path", "/etc/exporter/tls.crt"}, message: webserver.ErrTLSPair.Error()},
+		{name: "TLS key without certificate", env: map[string]string{"PROMETHEUS_RDS_EXPORTER_TLS_KEY_PATH": "/etc/exporter/tls.key"}, message: webserver.ErrTLSPair.Error()},
+		{name: "bad log format", args: []string{"--log-format", "xml"}, message: errInvalidLogFormat.Error() + ": 'xml'"},
+		{name: "bad log format in env", env: map[string]string{"PROMETHEUS_RDS_EXPORTER_LOG_FORMAT": "logfmt"}, message: errInvalidLogFormat.Error() + ": 'logfmt'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			valid, output := runCheckConfig(t, tc.args, tc.env, tc.file)
+
+			assert.Equal(t, tc.valid, valid, "Validity mismatch:\n%s", output)
+			assert.Contains(t, output, tc.message, "Output should report the result")
+		})
+	}
+}
+
+func TestCheckConfigMetricStreamWithBasicAuth(t *testing.T) {
+	webConfigPath := filepath.Join(t.TempDir(), "web-config.yaml")
+	require.NoError(t, os.WriteFile(webConfigPath, []byte("basic_auth_users:\n  prometheus: $2y$10$QOauhQNbBCuQDKes6eFzPeMqBSjb7Mr5DUmpZ/VcEd00UAV/LDeSi\n"), 0o600), "Web configuration file must be written")
+
+	valid, output := runCheckConfig(t, []string{"--metric-stream-enabled", "--web-config-file", webConfigPath}, nil, "")
+	assert.True(t, valid, "Metric streams should be received with basic authentication:\n%s", output)
+}
+
+func TestCheckConfigSources(t *testing.T) {
+	_, output := runCheckConfig(t,
+		[]string{"--debug", "--log-format", "json"},
+		map[string]string{"PROMETHEUS_RDS_EXPORTER_LOG_FORMAT": "text", "PROMETHEUS_RDS_EXPORTER_METRICS_PATH": "/rds"},
+		"metrics-path: /file\ncollect-quotas: false\nlisten-address: 127.0.0.1:9043\n",
+	)
+
+	testCases := []struct {
+		parameter string
+		value     string
+		source    string
+	}{
+		{parameter: "debug", value: "true", source: "flag"},
+		{parameter: "log-format", value: "json", source: "flag"},  // flags take precedence over environment variables
+		{parameter: "metrics-path", value: "/rds", source: "env"}, // environment variables take precedence over configuration file
+		{parameter: "collect-quotas", value: "false", source: "file"},
+		{parameter: "listen-address", value: "127.0.0.1:9043", source: "file"},
+		{parameter: "collect-usages", value: "true", source: "default"},
+	}
+
+	for _, tc := range testCases {
+		pattern := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(tc.parameter) + `\s+` + regexp.QuoteMeta(tc.value) + `\s+` + tc.source + `$`)
+		assert.Regexp(t, pattern, output, "%s should be %s from %s", tc.parameter, tc.value, tc.source)
+	}
+}
+
+func TestGetEnvName(t *testing.T) {
+	assert.Equal(t, "PROMETHEUS_RDS_EXPORTER_AWS_ASSUME_ROLE_ARN", getEnvName("aws-assume-role-arn"), "Environment variable name mismatch")
+}
@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+
+	instancetypes "github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/exporter"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/metricstream"
+	rdsmetrics "github.com/TeiNam/prometheus-rds-exporter/internal/app/rds"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/rightsizing"
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/pi"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collectorDependencies contains resources shared by all collectors
+type collectorDependencies struct {
+	instanceClassCatalog instancetypes.Catalog
+	instanceTypesCache   *instancetypes.Cache
+	metricStreamStore    *metricstream.Store
+}
+
+// newCollectorDependencies loads the instance class catalog and the instance types cache
+// A broken cache file is ignored, it will be replaced with fresh AWS EC2 API responses
+func newCollectorDependencies(logger *slog.Logger, configuration exporterConfig) (collectorDependencies, error) {
+	instanceClassCatalog, err := instancetypes.LoadCatalog(configuration.InstanceClassCatalogPath)
+	if err != nil {
+		return collectorDependencies{}, fmt.Errorf("can't load instance class catalog: %w", err)
+	}
+
+	instanceTypesCache, err := instancetypes.NewCache(configuration.InstanceTypesCachePath, configuration.InstanceTypesCacheTTL)
+	if err != nil {
+		logger.Warn("can't load instance types cache", "reason", err)
+	}
+
+	return collectorDependencies{
+		instanceClassCatalog: instanceClassCatalog,
+		instanceTypesCache:   instanceTypesCache,
+	}, nil
+}
+
+// newCollectorConfiguration returns the collector configuration of the exporter configuration
+func newCollectorConfiguration(configuration exporterConfig) exporter.Configuration {
+	return exporter.Configuration{
+		AuroraStoragePricing: rdsmetrics.AuroraStoragePricing{
+			StandardStoragePerGB:    configuration.AuroraStandardStoragePrice,
+			StandardIOPerMillion:    configuration.AuroraStandardIOPrice,
+			IOOptimizedStoragePerGB: configuration.AuroraIOOptimizedPrice,
+		},
+		CloudWatchLookback:         configuration.CloudWatchLookback,
+		CloudWatchMaxStaleness:     configuration.CloudWatchMaxStaleness,
+		CloudWatchUseTimestamps:    configuration.CloudWatchUseTimestamps,
+		CollectInstanceMetrics:     configuration.CollectInstanceMetrics,
+		CollectInstanceTypes:       configuration.CollectInstanceTypes,
+		CollectInstanceTags:        configuration.CollectInstanceTags,
+		CollectLogsSize:            configuration.CollectLogsSize,
+		CollectMaintenances:        configuration.CollectMaintenances,
+		CollectPerformanceInsights: configuration.CollectPerformanceInsights,
+		CollectQuotas:              configuration.CollectQuotas,
+		CollectUsages:              configuration.CollectUsages,
+		PerformanceInsightsTopSQL:  configuration.PerformanceInsightsTopSQL,
+		QuotasAllowList:            configuration.QuotasAllowList,
+	}
+}
+
+// newCollector returns a collector of the AWS account and region of the AWS configuration
+func newCollector(logger *slog.Logger, configuration exporterConfig, cfg aws.Config, dependencies collectorDependencies) (*exporter.RdsCollector, error) {
+	awsAccountID, awsRegion, err := getAWSSessionInformation(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("can't identify AWS account and/or region: %w", err)
+	}
+
+	logger.Info("Successfully initialized AWS configuration", "accountID", awsAccountID, "awsRegion", awsRegion)
+
+	rdsClient := rds.NewFromConfig(cfg)
+	ec2Client := ec2.NewFromConfig(cfg)
+	cloudWatchClient := cloudwatch.NewFromConfig(cfg)
+	servicequotasClient := servicequotas.NewFromConfig(cfg)
+	piClient := pi.NewFromConfig(cfg)
+
+	collector := exporter.NewCollector(*logger, newCollectorConfiguration(configuration), awsAccountID, awsRegion, rdsClient, ec2Client, cloudWatchClient, servicequotasClient, piClient)
+	collector.SetInstanceClasses(dependencies.instanceClassCatalog, dependencies.instanceTypesCache)
+
+	if configuration.RightsizingEnabled {
+		collector.SetRightsizing(rightsizing.NewAdvisor(rightsizing.Configuration{
+			Window:     configuration.RightsizingWindow,
+			Resolution: rightsizing.DefaultResolution,
+			MinSamples: configuration.RightsizingMinSamples,
+			Threshold:  configuration.RightsizingThreshold,
+		}, dependencies.instanceClassCatalog))
+	}
+
+	if dependencies.metricStreamStore != nil {
+		collector.SetMetricStream(dependencies.metricStreamStore)
+	}
+
+	return collector, nil
+}
+
+// collectorSettings contains the parameters a region collector is built with, the collector is rebuilt on reload when they change
+// Readiness parameters are updated in place, so they are not part of the settings
+type collectorSettings struct {
+	region        string
+	roleArn       string
+	roleSession   string
+	configuration exporter.Configuration
+	rightsizing   rightsizing.Configuration
+	rightsizingOn bool
+}
+
+func newCollectorSettings(configuration exporterConfig, region string) collectorSettings {
+	return collectorSettings{
+		region:        region,
+		roleArn:       configuration.AWSAssumeRoleArn,
+		roleSession:   configuration.AWSAssumeRoleSession,
+		configuration: newCollectorConfiguration(configuration),
+		rightsizing: rightsizing.Configuration{
+			Window:     configuration.RightsizingWindow,
+			Resolution: rightsizing.DefaultResolution,
+			MinSamples: configuration.RightsizingMinSamples,
+			Threshold:  configuration.RightsizingThreshold,
+		},
+		rightsizingOn: configuration.RightsizingEnabled,
+	}
+}
+
+// regionCollector is the collector of a region and the settings it was built with
+type regionCollector struct {
+	settings  collectorSettings
+	collector *exporter.RdsCollector
+	target    webserver.Target
+	readiness *regionReadiness
+}
+
+// collectorSet contains collectors of configured regions, replaced on configuration reload
+type collectorSet struct {
+	logger       *slog.Logger
+	dependencies collectorDependencies
+	newRegion    func(configuration exporterConfig, settings collectorSettings) (*regionCollector, error) // Builds the collector of a region, replaced in tests
+
+	mutex         sync.RWMutex
+	configuration exporterConfig
+	regions       []*regionCollector
+}
+
+func newCollectorSet(logger *slog.Logger, dependencies collectorDependencies) *collectorSet {
+	s := &collectorSet{
+		logger:       logger,
+		dependencies: dependencies,
+	}
+	s.newRegion = s.newRegionCollector
+
+	return s
+}
+
+// Load builds collectors of the configuration regions
+// Collectors whose settings didn't change are kept with their counters and their readiness parameters are updated, on error the current collectors are kept
+func (s *collectorSet) Load(configuration exporterConfig) error {
+	s.mutex.RLock()
+	current := make(map[string]*regionCollector, len(s.regions))
+	for _, r := range s.regions {
+		current[r.settings.region] = r
+	}
+	s.mutex.RUnlock()
+
+	regions := make([]*regionCollector, 0, len(configuration.AWSRegions))
+
+	for _, region := range configuration.AWSRegions {
+		settings := newCollectorSettings(configuration, region)
+
+		if r, found := current[region]; found && reflect.DeepEqual(r.settings, settings) {
+			regions = append(regions, r)
+
+			continue
+		}
+
+		r, err := s.newRegion(configuration, settings)
+		if err != nil {
+			return err
+		}
+
+		regions = append(regions, r)
+	}
+
+	for _, r := range regions {
+		r.readiness.SetMaxAge(configuration.ReadinessMaxAge)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.configuration = configuration
+	s.regions = regions
+
+	return nil
+}
+
+func (s *collectorSet) newRegionCollector(configuration exporterConfig, settings collectorSettings) (*regionCollector, error) {
+	s.logger.Info("Initializing AWS configuration for region", "region", settings.region)
+
+	cfg, err := getAWSConfiguration(s.logger, settings.roleArn, settings.roleSession, settings.region)
+	if err != nil {
+		return nil, fmt.Errorf("can't initialize AWS configuration for region %s: %w", settings.region, err)
+	}
+
+	collector, err := newCollector(s.logger, configuration, cfg, s.dependencies)
+	if err != nil {
+		return nil, fmt.Errorf("can't initialize collector for region %s: %w", settings.region, err)
+	}
+
+	registry := prometheus.NewRegistry()
+
+	err = registry.Register(collector)
+	if err != nil {
+		return nil, fmt.Errorf("can't register collector for region %s: %w", settings.region, err)
+	}
+
+	s.logger.Info("Collector registered for region", "region", settings.region)
+
+	return &regionCollector{
+		settings:  settings,
+		collector: collector,
+		target:    webserver.Target{AccountID: collector.AccountID(), Region: collector.Region(), Gatherer: registry},
+		readiness: newRegionReadiness(cfg, collector, configuration.ReadinessMaxAge),
+	}, nil
+}
+
+// Configuration returns the configuration of the last successful load
+func (s *collectorSet) Configuration() exporterConfig {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.configuration
+}
+
+func (s *collectorSet) Collectors() []*exporter.RdsCollector {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	collectors := make([]*exporter.RdsCollector, len(s.regions))
+	for i, r := range s.regions {
+		collectors[i] = r.collector
+	}
+
+	return collectors
+}
+
+func (s *collectorSet) Targets() []webserver.Target {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	targets := make([]webserver.Target, len(s.regions))
+	for i, r := range s.regions {
+		targets[i] = r.target
+	}
+
+	return targets
+}
+
+func (s *collectorSet) Readiness() []webserver.ReadinessCheck {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	checks := make([]webserver.ReadinessCheck, len(s.regions))
+	for i, r := range s.regions {
+		checks[i] = r.readiness.check
+	}
+
+	return checks
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/exporter"
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/infra/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_rds "github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloudwatch_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/cloudwatch/mock"
+	ec2_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2/mock"
+	pi_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/pi/mock"
+	rds_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/rds/mock"
+	servicequotas_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/servicequotas/mock"
+	aws_rds_types "github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+var (
+	errRegionUnavailable = errors.New("region unavailable")
+	errFetch             = errors.New("fetch failed")
+)
+
+// newTestCollectorSet returns a collector set building collectors with mocked AWS clients
+// Collectors of regions named "unavailable" can't be built, AWS RDS API calls of regions named "failing" fail with maintenances collection
+func newTestCollectorSet(t *testing.T) (*collectorSet, *int) {
+	t.Helper()
+
+	logger, err := logger.New(false, "text")
+	require.NoError(t, err, "Logger must be initialized")
+
+	builds := 0
+	set := newCollectorSet(logger, collectorDependencies{})
+	set.newRegion = func(configuration exporterConfig, settings collectorSettings) (*regionCollector, error) {
+		if settings.region == "unavailable" {
+			return nil, errRegionUnavailable
+		}
+
+		builds++
+
+		rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rds_mock.NewRdsInstance()}}}
+		if settings.region == "failing" {
+			rdsClient.Error = errFetch
+		}
+
+		collector := exporter.NewCollector(*logger, settings.configuration, "123456789012", settings.region, rdsClient, ec2_mock.EC2Client{}, cloudwatch_mock.CloudwatchClient{}, servicequotas_mock.ServiceQuotasClient{}, pi_mock.PIClient{})
+
+		registry := prometheus.NewRegistry()
+		require.NoError(t, registry.Register(collector), "Collector must be registered")
+
+		return &regionCollector{
+			settings:  settings,
+			collector: collector,
+			target:    webserver.Target{AccountID: collector.AccountID(), Region: collector.Region(), Gatherer: registry},
+			readiness: newRegionReadiness(aws.Config{}, collector, configuration.ReadinessMaxAge),
+		}, nil
+	}
+
+	return set, &builds
+}
+
+func TestCollectorSetLoad(t *testing.T) {
+	set, builds := newTestCollectorSet(t)
+
+	configuration := exporterConfig{AWSRegions: []string{"eu-west-1", "eu-west-3"}}
+	require.NoError(t, set.Load(configuration), "Initial load should succeed")
+	assert.Equal(t, 2, *builds, "A collector should be built per region")
+
+	collectors := set.Collectors()
+	require.Len(t, collectors, 2, "A collector should be loaded per region")
+	testutil.CollectAndCount(collectors[0])
+
+	rdsAPICalls := collectors[0].GetStatistics().RDSAPIcalls
+	require.NotZero(t, rdsAPICalls, "Scrape should call AWS RDS API")
+
+	// Parameters that are not collector settings don't rebuild collectors
+	configuration.ServiceDiscoveryTags = []string{"Team"}
+	require.NoError(t, set.Load(configuration), "Reload should succeed")
+	assert.Equal(t, 2, *builds, "Unchanged collectors should not be rebuilt")
+	assert.Same(t, collectors[0], set.Collectors()[0], "Unchanged collector should be kept")
+	assert.Equal(t, rdsAPICalls, set.Collectors()[0].GetStatistics().RDSAPIcalls, "Counters of unchanged collector should be kept")
+	assert.Equal(t, []string{"Team"}, set.Configuration().ServiceDiscoveryTags, "Configuration should be replaced")
+
+	// Readiness parameters are updated without rebuilding collectors
+	configuration.ReadinessMaxAge = 10 * time.Minute
+	require.NoError(t, set.Load(configuration), "Reload should succeed")
+	assert.Equal(t, 2, *builds, "Readiness parameters should not rebuild collectors")
+	assert.Same(t, collectors[0], set.Collectors()[0], "Collector should be kept when readiness parameters change")
+	assert.Equal(t, int64(10*time.Minute), set.regions[0].readiness.maxAge.Load(), "Readiness maximum age should be updated")
+
+	// Only collectors of new regions are built
+	configuration.AWSRegions = []string{"eu-west-1", "us-east-1"}
+	require.NoError(t, set.Load(configuration), "Reload should succeed")
+	assert.Equal(t, 3, *builds, "Only the new region collector should be built")
+	assert.Same(t, collectors[0], set.Collectors()[0], "Collector of a kept region should be kept")
+	assert.Equal(t, []string{"eu-west-1", "us-east-1"}, targetRegions(set.Targets()), "Removed region should not be served")
+
+	// Changed collector settings rebuild collectors with fresh counters
+	configuration.CollectQuotas = true
+	require.NoError(t, set.Load(configuration), "Reload should succeed")
+	assert.Equal(t, 5, *builds, "Collectors with changed settings should be rebuilt")
+	assert.NotSame(t, collectors[0], set.Collectors()[0], "Collector with changed settings should be replaced")
+	assert.Zero(t, set.Collectors()[0].GetStatistics().RDSAPIcalls, "Rebuilt collector should have fresh counters")
+
+	// Failed loads keep current collectors and configuration
+	current := set.Collectors()
+	err := set.Load(exporterConfig{AWSRegions: []string{"eu-west-1", "unavailable"}})
+	require.ErrorIs(t, err, errRegionUnavailable, "Load should fail")
+	assert.Equal(t, current, set.Collectors(), "Current collectors should be kept on error")
+	assert.True(t, set.Configuration().CollectQuotas, "Current configuration should be kept on error")
+}
+
+func targetRegions(targets []webserver.Target) []string {
+	regions := make([]string, len(targets))
+	for i, target := range targets {
+		regions[i] = target.Region
+	}
+
+	return regions
+}
@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/doctor"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/exporter"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/iam"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/infra/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatch_types "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/pi"
+	pi_types "github.com/aws/aws-sdk-go-v2/service/pi/types"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	// doctorReferenceRegion is enabled in all AWS accounts, credentials accepted there but rejected in a region mean the region is disabled
+	doctorReferenceRegion = "us-east-1"
+
+	// doctorDBIdentifier is a non-existent instance, APIs requiring an instance return a not found error once the caller is authorized
+	doctorDBIdentifier = "prometheus-rds-exporter-doctor"
+
+	rdsMinRecords = 20 // Minimum page size of AWS RDS API
+
+	doctorLookback       = 10 * time.Minute
+	doctorMetricPeriod   = 300 // Standard resolution of AWS RDS Cloudwatch metrics
+	doctorPIMetricPeriod = 60  // Smallest period of AWS Performance Insights metrics
+)
+
+var (
+	errNoCredentials   = errors.New("no AWS credentials found")
+	errNoCheck         = fmt.Errorf("%w: no check available for this action", doctor.ErrSkipped)
+	errNoPIInstance    = fmt.Errorf("%w: no instance with Performance Insights enabled", doctor.ErrSkipped)
+	errDoctorFailures  = errors.New("checks failed")
+	errIdentityMissing = errors.New("identity check failed")
+)
+
+func newDoctorCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check AWS credentials, permissions and connectivity of enabled collectors",
+		Long: `Resolve AWS credentials and assumed role, then call each AWS API used by the enabled collectors
+	with minimal inputs in every configured region and print an actionable report.
+	Exit with a non-zero code if a check fails.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var c exporterConfig
+
+			err := viper.Unmarshal(&c)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "ERROR: Unable to decode configuration:", err)
+				os.Exit(configErrorExitCode)
+			}
+
+			logger, err := logger.NewWithOutput(os.Stderr, c.Debug, c.LogFormat)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "ERROR: Fail to initialize logger:", err)
+				os.Exit(configErrorExitCode)
+			}
+
+			err = runDoctor(cmd.OutOrStdout(), logger, c)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "ERROR:", err)
+				os.Exit(awsErrorExitCode)
+			}
+		},
+	}
+}
+
+// runDoctor checks each configured region and writes reports, it returns an error if a check failed
+func runDoctor(w io.Writer, logger *slog.Logger, configuration exporterConfig) error {
+	var actions []string
+	for _, statement := range iam.NewPolicy(exporter.RequiredPermissions(newCollectorConfiguration(configuration), getPartition(configuration))).Statement {
+		actions = append(actions, statement.Action...)
+	}
+
+	failedRegions := 0
+
+	for i, region := range configuration.AWSRegions {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		report := checkRegion(logger, configuration, region, actions)
+		report.Write(w)
+
+		if report.Failed() {
+			failedRegions++
+		}
+	}
+
+	if failedRegions > 0 {
+		return fmt.Errorf("%w in %d region(s)", errDoctorFailures, failedRegions)
+	}
+
+	return nil
+}
+
+// checkRegion resolves credentials and identity, then checks actions if the identity is known
+func checkRegion(logger *slog.Logger, configuration exporterConfig, region string, actions []string) doctor.RegionReport {
+	report := doctor.RegionReport{Region: region}
+
+	ctx := context.Background()
+
+	cfg, err := getAWSConfiguration(logger, configuration.AWSAssumeRoleArn, configuration.AWSAssumeRoleSession, region)
+	if err != nil {
+		report.Results = append(report.Results, doctor.Result{Action: "configuration", Category: doctor.CategoryError, Err: err})
+
+		return report
+	}
+
+	credentialsAction := "credentials"
+	if configuration.AWSAssumeRoleArn != "" {
+		credentialsAction = "sts:AssumeRole"
+	}
+
+	report.Results = doctor.Run(ctx, []doctor.Check{{Action: credentialsAction, Call: func(ctx context.Context) error {
+		if cfg.Credentials == nil {
+			return errNoCredentials
+		}
+
+		_, err := cfg.Credentials.Retrieve(ctx)
+
+		return err
+	}}})
+
+	if report.Failed() {
+		return report
+	}
+
+	identity := doctor.Run(ctx, []doctor.Check{{Action: "sts:GetCallerIdentity", Call: func(ctx context.Context) error {
+		var err error
+
+		report.AccountID, report.Identity, err = getCallerIdentity(ctx, cfg)
+
+		return err
+	}}})[0]
+
+	if identity.Category == doctor.CategoryInvalidCredentials && region != doctorReferenceRegion {
+		reference := cfg.Copy()
+		reference.Region = doctorReferenceRegion
+
+		referenceIdentity := doctor.Run(ctx, []doctor.Check{{Action: "sts:GetCallerIdentity", Call: func(ctx context.Context) error {
+			_, _, err := getCallerIdentity(ctx, reference)
+
+			return err
+		}}})[0]
+
+		if !referenceIdentity.Failed() {
+			identity.Category = doctor.CategoryRegionDisabled
+		}
+	}
+
+	report.Results = append(report.Results, identity)
+
+	if identity.Failed() {
+		return report
+	}
+
+	checks := newDoctorChecks(cfg)
+
+	for _, action := range actions {
+		check, found := checks[action]
+		if !found {
+			check = doctor.Check{Action: action, Call: func(context.Context) error { return errNoCheck }}
+		}
+
+		report.Results = append(report.Results, doctor.Run(ctx, []doctor.Check{check})...)
+	}
+
+	return report
+}
+
+func getCallerIdentity(ctx context.Context, cfg aws.Config) (string, string, error) {
+	output, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", errIdentityMissing, err)
+	}
+
+	return aws.ToString(output.Account), aws.ToString(output.Arn), nil
+}
+
+// newDoctorChecks returns checks of AWS API actions used by collectors, indexed by AWS IAM action
+func newDoctorChecks(cfg aws.Config) map[string]doctor.Check {
+	rdsClient := rds.NewFromConfig(cfg)
+	cloudWatchClient := cloudwatch.NewFromConfig(cfg)
+	servicequotasClient := servicequotas.NewFromConfig(cfg)
+	ec2Client := ec2.NewFromConfig(cfg)
+	piClient := pi.NewFromConfig(cfg)
+
+	checks := []doctor.Check{
+		{Action: "rds:DescribeDBInstances", Call: func(ctx context.Context) error {
+			_, err := rdsClient.DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{MaxRecords: aws.Int32(rdsMinRecords)})
+
+			return err
+		}},
+		{Action: "rds:DescribeDBClusters", Call: func(ctx context.Context) error {
+			_, err := rdsClient.DescribeDBClusters(ctx, &rds.DescribeDBClustersInput{MaxRecords: aws.Int32(rdsMinRecords)})
+
+			return err
+		}},
+		{Action: "rds:DescribeDBLogFiles", AllowedErrors: []string{"DBInstanceNotFound"}, Call: func(ctx context.Context) error {
+			_, err := rdsClient.DescribeDBLogFiles(ctx, &rds.DescribeDBLogFilesInput{DBInstanceIdentifier: aws.String(doctorDBIdentifier)})
+
+			return err
+		}},
+		{Action: "rds:DescribePendingMaintenanceActions", Call: func(ctx context.Context) error {
+			_, err := rdsClient.DescribePendingMaintenanceActions(ctx, &rds.DescribePendingMaintenanceActionsInput{MaxRecords: aws.Int32(rdsMinRecords)})
+
+			return err
+		}},
+		{Action: "cloudwatch:GetMetricData", Call: func(ctx context.Context) error {
+			_, err := cloudWatchClient.GetMetricData(ctx, &cloudwatch.GetMetricDataInput{
+				StartTime: aws.Time(time.Now().Add(-doctorLookback)),
+				EndTime:   aws.Time(time.Now()),
+				MetricDataQueries: []cloudwatch_types.MetricDataQuery{{
+					Id: aws.String("doctor"),
+					MetricStat: &cloudwatch_types.MetricStat{
+						Metric: &cloudwatch_types.Metric{Namespace: aws.String("AWS/RDS"), MetricName: aws.String("CPUUtilization")},
+						Period: aws.Int32(doctorMetricPeriod),
+						Stat:   aws.String("Average"),
+					},
+				}},
+			})
+
+			return err
+		}},
+		{Action: "cloudwatch:ListMetrics", Call: func(ctx context.Context) error {
+			_, err := cloudWatchClient.ListMetrics(ctx, &cloudwatch.ListMetricsInput{Namespace: aws.String("AWS/Usage")})
+
+			return err
+		}},
+		{Action: "servicequotas:ListServiceQuotas", Call: func(ctx context.Context) error {
+			_, err := servicequotasClient.ListServiceQuotas(ctx, &servicequotas.ListServiceQuotasInput{ServiceCode: aws.String("rds"), MaxResults: aws.Int32(1)})
+
+			return err
+		}},
+		{Action: "servicequotas:ListAWSDefaultServiceQuotas", Call: func(ctx context.Context) error {
+			_, err := servicequotasClient.ListAWSDefaultServiceQuotas(ctx, &servicequotas.ListAWSDefaultServiceQuotasInput{ServiceCode: aws.String("rds"), MaxResults: aws.Int32(1)})
+
+			return err
+		}},
+		{Action: "ec2:DescribeInstanceTypes", AllowedErrors: []string{"DryRunOperation"}, Call: func(ctx context.Context) error {
+			_, err := ec2Client.DescribeInstanceTypes(ctx, &ec2.DescribeInstanceTypesInput{DryRun: aws.Bool(true), InstanceTypes: []ec2_types.InstanceType{ec2_types.InstanceTypeT3Micro}})
+
+			return err
+		}},
+		{Action: "pi:GetResourceMetrics", Call: func(ctx context.Context) error {
+			identifier, err := getPerformanceInsightsInstance(ctx, rdsClient)
+			if err != nil {
+				return err
+			}
+
+			_, err = piClient.GetResourceMetrics(ctx, &pi.GetResourceMetricsInput{
+				ServiceType:     pi_types.ServiceTypeRds,
+				Identifier:      aws.String(identifier),
+				StartTime:       aws.Time(time.Now().Add(-doctorLookback)),
+				EndTime:         aws.Time(time.Now()),
+				PeriodInSeconds: aws.Int32(doctorPIMetricPeriod),
+				MetricQueries:   []pi_types.MetricQuery{{Metric: aws.String("db.load.avg")}},
+			})
+
+			return err
+		}},
+		{Action: "pi:DescribeDimensionKeys", Call: func(ctx context.Context) error {
+			identifier, err := getPerformanceInsightsInstance(ctx, rdsClient)
+			if err != nil {
+				return err
+			}
+
+			_, err = piClient.DescribeDimensionKeys(ctx, &pi.DescribeDimensionKeysInput{
+				ServiceType: pi_types.ServiceTypeRds,
+				Identifier:  aws.String(identifier),
+				StartTime:   aws.Time(time.Now().Add(-doctorLookback)),
+				EndTime:     aws.Time(time.Now()),
+				Metric:      aws.String("db.load.avg"),
+				GroupBy:     &pi_types.DimensionGroup{Group: aws.String("db.wait_event"), Limit: aws.Int32(1)},
+			})
+
+			return err
+		}},
+	}
+
+	indexed := make(map[string]doctor.Check, len(checks))
+	for _, check := range checks {
+		indexed[check.Action] = check
+	}
+
+	return indexed
+}
+
+// getPerformanceInsightsInstance returns the resource ID of an instance with Performance Insights enabled
+// AWS Performance Insights API requires an existing instance, checks are skipped if there is none
+func getPerformanceInsightsInstance(ctx context.Context, client *rds.Client) (string, error) {
+	paginator := rds.NewDescribeDBInstancesPaginator(client, &rds.DescribeDBInstancesInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("can't find an instance with Performance Insights enabled: %w", err)
+		}
+
+		for _, instance := range output.DBInstances {
+			if aws.ToBool(instance.PerformanceInsightsEnabled) && instance.DbiResourceId != nil {
+				return *instance.DbiResourceId, nil
+			}
+		}
+	}
+
+	return "", errNoPIInstance
+}
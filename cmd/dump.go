@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/infra/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Output formats of the dump command
+const (
+	dumpFormatPrometheus  = "prom"
+	dumpFormatJSON        = "json"
+	dumpFormatOpenMetrics = "openmetrics"
+
+	dumpFileMode = 0o644 // Output files are readable by node_exporter
+)
+
+var (
+	errInvalidDumpFormat = errors.New("format must be prom, json or openmetrics")
+	errCollect           = errors.New("metrics collection failed")
+)
+
+// dumpSample is a sample of the JSON output format
+type dumpSample struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Labels      map[string]string `json:"labels"`
+	Value       float64           `json:"value"`
+	TimestampMs int64             `json:"timestampMs,omitempty"`
+}
+
+func newDumpCommand() *cobra.Command {
+	var (
+		regions []string
+		format  string
+		output  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dump",
+		Short: "Collect metrics once and write them to standard output or a file",
+		Long: `Run a single collection of the configured regions and write metrics to standard output,
+	or atomically to a file for node_exporter textfile collector.
+	Sample timestamps are removed from prom files, since the textfile collector rejects them.
+	Exit with a non-zero code if the collection fails.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var c exporterConfig
+
+			err := viper.Unmarshal(&c)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "ERROR: Unable to decode configuration:", err)
+				os.Exit(configErrorExitCode)
+			}
+
+			if len(regions) > 0 {
+				c.AWSRegions = regions
+			}
+
+			os.Exit(dump(c, format, output))
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&regions, "region", nil, "AWS regions to collect, default to aws-regions parameter")
+	cmd.Flags().StringVar(&format, "format", dumpFormatPrometheus, "Output format (prom, json or openmetrics)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output file, replaced atomically (default to standard output)")
+
+	return cmd
+}
+
+// dump collects metrics of the configuration regions once, writes them to output and returns the exit code
+// Standard output is reserved for metrics, so logs are written to standard error
+func dump(configuration exporterConfig, format string, output string) int {
+	logger, err := logger.NewWithOutput(os.Stderr, configuration.Debug, configuration.LogFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR: Fail to initialize logger:", err)
+
+		return configErrorExitCode
+	}
+
+	if format != dumpFormatPrometheus && format != dumpFormatJSON && format != dumpFormatOpenMetrics {
+		logger.Error("invalid output format", "reason", fmt.Errorf("%w: '%s'", errInvalidDumpFormat, format))
+
+		return configErrorExitCode
+	}
+
+	dependencies, err := newCollectorDependencies(logger, configuration)
+	if err != nil {
+		logger.Error("can't load instance classes", "reason", err)
+
+		return configErrorExitCode
+	}
+
+	collectors := newCollectorSet(logger, dependencies)
+
+	err = collectors.Load(configuration)
+	if err != nil {
+		logger.Error("can't initialize collectors", "reason", err)
+
+		return awsErrorExitCode
+	}
+
+	return writeDump(logger, collectors, format, output)
+}
+
+// writeDump gathers metrics of collectors once, writes them to output and returns the exit code
+func writeDump(logger *slog.Logger, collectors *collectorSet, format string, output string) int {
+	var gatherers prometheus.Gatherers
+	for _, target := range collectors.Targets() {
+		gatherers = append(gatherers, target.Gatherer)
+	}
+
+	start := time.Now()
+
+	families, err := gatherers.Gather()
+	if err != nil {
+		logger.Error("can't gather metrics", "reason", err)
+
+		return exporterErrorExitCode
+	}
+
+	// node_exporter textfile collector rejects samples with timestamps, like Cloudwatch timestamps of cloudwatch-use-timestamps
+	if output != "" && format == dumpFormatPrometheus {
+		stripTimestamps(families)
+	}
+
+	var buffer bytes.Buffer
+
+	err = encodeMetrics(&buffer, families, format)
+	if err != nil {
+		logger.Error("can't encode metrics", "reason", err)
+
+		return exporterErrorExitCode
+	}
+
+	err = writeOutput(output, buffer.Bytes())
+	if err != nil {
+		logger.Error("can't write metrics", "reason", err)
+
+		return exporterErrorExitCode
+	}
+
+	// Metrics are written even if a data source failed, they contain rds_exporter_errors_total and up metrics
+	var errs []error
+	for _, collector := range collectors.Collectors() {
+		for _, err := range collector.FetchErrors(start) {
+			errs = append(errs, fmt.Errorf("region %s: %w", collector.Region(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		logger.Error("collection failed", "reason", fmt.Errorf("%w: %w", errCollect, errors.Join(errs...)))
+
+		return exporterErrorExitCode
+	}
+
+	return 0
+}
+
+// stripTimestamps removes timestamps of samples, so they are timestamped by the scraper
+func stripTimestamps(families []*dto.MetricFamily) {
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			metric.TimestampMs = nil
+		}
+	}
+}
+
+func encodeMetrics(w io.Writer, families []*dto.MetricFamily, format string) error {
+	if format == dumpFormatJSON {
+		return encodeJSONMetrics(w, families)
+	}
+
+	expositionFormat := expfmt.NewFormat(expfmt.TypeTextPlain)
+	if format == dumpFormatOpenMetrics {
+		expositionFormat = expfmt.NewFormat(expfmt.TypeOpenMetrics)
+	}
+
+	encoder := expfmt.NewEncoder(w, expositionFormat)
+
+	for _, family := range families {
+		err := encoder.Encode(family)
+		if err != nil {
+			return fmt.Errorf("can't encode %s: %w", family.GetName(), err)
+		}
+	}
+
+	if closer, ok := encoder.(expfmt.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// encodeJSONMetrics writes gauge, counter and untyped samples as a JSON array
+func encodeJSONMetrics(w io.Writer, families []*dto.MetricFamily) error {
+	samples := []dumpSample{}
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			sample := dumpSample{
+				Name:        family.GetName(),
+				Type:        strings.ToLower(family.GetType().String()),
+				Labels:      make(map[string]string, len(metric.GetLabel())),
+				TimestampMs: metric.GetTimestampMs(),
+			}
+
+			for _, label := range metric.GetLabel() {
+				sample.Labels[label.GetName()] = label.GetValue()
+			}
+
+			switch family.GetType() {
+			case dto.MetricType_GAUGE:
+				sample.Value = metric.GetGauge().GetValue()
+			case dto.MetricType_COUNTER:
+				sample.Value = metric.GetCounter().GetValue()
+			case dto.MetricType_UNTYPED:
+				sample.Value = metric.GetUntyped().GetValue()
+			case dto.MetricType_SUMMARY, dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				continue
+			}
+
+			samples = append(samples, sample)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(samples)
+}
+
+// writeOutput writes content to standard output if path is empty
+// Files are written to a temporary file renamed to path, so readers like node_exporter textfile collector never see a partial file
+func writeOutput(path string, content []byte) error {
+	if path == "" {
+		_, err := os.Stdout.Write(content)
+
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("can't create temporary file: %w", err)
+	}
+
+	// Removal fails once the temporary file is renamed
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	_, err = file.Write(content)
+	if err != nil {
+		_ = file.Close()
+
+		return fmt.Errorf("can't write temporary file: %w", err)
+	}
+
+	err = file.Chmod(dumpFileMode)
+	if err != nil {
+		_ = file.Close()
+
+		return fmt.Errorf("can't set permissions of temporary file: %w", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return fmt.Errorf("can't close temporary file: %w", err)
+	}
+
+	err = os.Rename(file.Name(), path)
+	if err != nil {
+		return fmt.Errorf("can't replace %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTimestampedFamilies() []*dto.MetricFamily {
+	return []*dto.MetricFamily{{
+		Name: proto.String("rds_cpu_usage_percent_average"),
+		Help: proto.String("Instance CPU used"),
+		Type: dto.MetricType_GAUGE.Enum(),
+		Metric: []*dto.Metric{{
+			Label:       []*dto.LabelPair{{Name: proto.String("dbidentifier"), Value: proto.String("db-test")}},
+			Gauge:       &dto.Gauge{Value: proto.Float64(42)},
+			TimestampMs: proto.Int64(1700000000000),
+		}},
+	}}
+}
+
+func TestEncodeMetrics(t *testing.T) {
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{format: dumpFormatPrometheus, expected: "rds_cpu_usage_percent_average{dbidentifier=\"db-test\"} 42 1700000000000\n"},
+		{format: dumpFormatOpenMetrics, expected: "rds_cpu_usage_percent_average{dbidentifier=\"db-test\"} 42.0 1.7e+09\n# EOF\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			var buffer bytes.Buffer
+
+			require.NoError(t, encodeMetrics(&buffer, newTimestampedFamilies(), tc.format), "Metrics should be encoded")
+			assert.Contains(t, buffer.String(), "# TYPE rds_cpu_usage_percent_average gauge\n", "Type should be written")
+			assert.Contains(t, buffer.String(), tc.expected, "Sample mismatch")
+		})
+	}
+
+	t.Run(dumpFormatJSON, func(t *testing.T) {
+		var buffer bytes.Buffer
+
+		require.NoError(t, encodeMetrics(&buffer, newTimestampedFamilies(), dumpFormatJSON), "Metrics should be encoded")
+
+		var samples []dumpSample
+		require.NoError(t, json.Unmarshal(buffer.Bytes(), &samples), "Output should be JSON")
+		assert.Equal(t, []dumpSample{{
+			Name:        "rds_cpu_usage_percent_average",
+			Type:        "gauge",
+			Labels:      map[string]string{"dbidentifier": "db-test"},
+			Value:       42,
+			TimestampMs: 1700000000000,
+		}}, samples, "Samples mismatch")
+	})
+}
+
+func TestStripTimestamps(t *testing.T) {
+	families := newTimestampedFamilies()
+	stripTimestamps(families)
+
+	var buffer bytes.Buffer
+
+	require.NoError(t, encodeMetrics(&buffer, families, dumpFormatPrometheus), "Metrics should be encoded")
+	assert.Contains(t, buffer.String(), "rds_cpu_usage_percent_average{dbidentifier=\"db-test\"} 42\n", "Timestamp should be removed")
+}
+
+func TestWriteOutput(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rds.prom")
+
+	require.NoError(t, os.WriteFile(path, []byte("old\n"), 0o600), "Previous file must be written")
+	require.NoError(t, writeOutput(path, []byte("new\n")), "Output should be written")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err, "Output should be readable")
+	assert.Equal(t, "new\n", string(content), "Previous file should be replaced")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err, "Output should exist")
+	assert.Equal(t, os.FileMode(dumpFileMode), info.Mode().Perm(), "Output should be readable by node_exporter")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err, "Directory should be readable")
+	assert.Len(t, entries, 1, "Temporary file should not be left")
+
+	err = writeOutput(filepath.Join(dir, "missing", "rds.prom"), []byte("new\n"))
+	assert.Error(t, err, "Output in missing directory should fail")
+}
+
+func TestWriteDump(t *testing.T) {
+	testCases := []struct {
+		name     string
+		regions  []string
+		format   string
+		exitCode int
+	}{
+		{name: "prom", regions: []string{"eu-west-3"}, format: dumpFormatPrometheus, exitCode: 0},
+		{name: "openmetrics", regions: []string{"eu-west-3"}, format: dumpFormatOpenMetrics, exitCode: 0},
+		{name: "json", regions: []string{"eu-west-3"}, format: dumpFormatJSON, exitCode: 0},
+		{name: "fetch error", regions: []string{"eu-west-3", "failing"}, format: dumpFormatPrometheus, exitCode: exporterErrorExitCode},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			set, _ := newTestCollectorSet(t)
+			require.NoError(t, set.Load(exporterConfig{AWSRegions: tc.regions, CollectMaintenances: true}), "Collectors should be loaded")
+
+			path := filepath.Join(t.TempDir(), "rds.prom")
+
+			exitCode := writeDump(set.logger, set, tc.format, path)
+			assert.Equal(t, tc.exitCode, exitCode, "Exit code mismatch")
+
+			// Metrics are written even if a data source failed
+			content, err := os.ReadFile(path)
+			require.NoError(t, err, "Output should be written")
+			assert.Contains(t, string(content), "rds_exporter_errors_total", "Output should contain exporter metrics")
+		})
+	}
+}
+
+func TestWriteDumpWithoutTimestamps(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(timestampedCollector{})
+
+	set, _ := newTestCollectorSet(t)
+	require.NoError(t, set.Load(exporterConfig{AWSRegions: []string{"eu-west-3"}}), "Collectors should be loaded")
+	set.regions[0].target.Gatherer = registry
+
+	path := filepath.Join(t.TempDir(), "rds.prom")
+	require.Equal(t, 0, writeDump(set.logger, set, dumpFormatPrometheus, path), "Dump should succeed")
+
+	families, err := new(expfmt.TextParser).TextToMetricFamilies(bytes.NewReader(readFile(t, path)))
+	require.NoError(t, err, "Output should be Prometheus text format")
+	require.Contains(t, families, "rds_cpu_usage_percent_average", "Sample should be written")
+	assert.Nil(t, families["rds_cpu_usage_percent_average"].GetMetric()[0].TimestampMs, "Timestamps should be removed from files")
+}
+
+// timestampedCollector returns a sample timestamped like Cloudwatch metrics with cloudwatch-use-timestamps
+type timestampedCollector struct{}
+
+var (
+	timestampedDesc     = prometheus.NewDesc("rds_cpu_usage_percent_average", "Instance CPU used", nil, nil)
+	prometheusTimestamp = time.UnixMilli(1700000000000)
+)
+
+func (timestampedCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- timestampedDesc
+}
+
+func (timestampedCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.NewMetricWithTimestamp(prometheusTimestamp, prometheus.MustNewConstMetric(timestampedDesc, prometheus.GaugeValue, 42))
+}
+
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err, "File should be readable")
+
+	return content
+}
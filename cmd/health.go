@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/exporter"
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// credentialsCheckInterval is the minimum interval between two AWS STS checks of a region credentials
+const credentialsCheckInterval = time.Minute
+
+// regionReadiness checks AWS credentials of a region and the last fetches of its collector
+type regionReadiness struct {
+	cfg       aws.Config
+	collector *exporter.RdsCollector
+	maxAge    atomic.Int64 // Maximum age of the last successful fetch of a ready source, updated on reload
+
+	mutex       sync.Mutex
+	checkedAt   time.Time
+	credentials webserver.CheckStatus
+}
+
+func newRegionReadiness(cfg aws.Config, collector *exporter.RdsCollector, maxAge time.Duration) *regionReadiness {
+	r := &regionReadiness{
+		cfg:       cfg,
+		collector: collector,
+	}
+	r.SetMaxAge(maxAge)
+
+	return r
+}
+
+// SetMaxAge replaces the maximum age of the last successful fetch of a ready source
+func (r *regionReadiness) SetMaxAge(maxAge time.Duration) {
+	r.maxAge.Store(int64(maxAge))
+}
+
+func (r *regionReadiness) check(_ context.Context) webserver.RegionReadiness {
+	now := time.Now()
+	maxAge := time.Duration(r.maxAge.Load())
+
+	readiness := webserver.RegionReadiness{
+		Region:      r.collector.Region(),
+		AccountID:   r.collector.AccountID(),
+		Credentials: r.checkCredentials(now),
+		Sources:     make(map[string]webserver.CheckStatus),
+	}
+
+	for source, status := range r.collector.SourceStatuses() {
+		sourceStatus := webserver.CheckStatus{
+			Ready: status.LastError == nil && now.Sub(status.LastSuccess) <= maxAge,
+		}
+
+		if !status.LastSuccess.IsZero() {
+			lastSuccess := status.LastSuccess
+			sourceStatus.LastSuccess = &lastSuccess
+		}
+
+		if status.LastError != nil {
+			sourceStatus.Error = status.LastError.Error()
+		}
+
+		readiness.Sources[source] = sourceStatus
+	}
+
+	// Optional sources (eg. quotas, Performance Insights) are reported but only AWS RDS instances are required
+	// Metrics are fetched on scrape, exporters never scraped are ready with valid credentials so Prometheus can scrape them through a Kubernetes service
+	rds, fetched := readiness.Sources[exporter.SourceRDS]
+	readiness.Ready = readiness.Credentials.Ready && (!fetched || rds.Ready)
+
+	return readiness
+}
+
+// checkCredentials returns the status of region credentials, AWS STS is called at most once per credentialsCheckInterval
+func (r *regionReadiness) checkCredentials(now time.Time) webserver.CheckStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if now.Sub(r.checkedAt) < credentialsCheckInterval {
+		return r.credentials
+	}
+
+	r.checkedAt = now
+
+	_, _, err := getAWSSessionInformation(r.cfg)
+	if err != nil {
+		r.credentials.Ready = false
+		r.credentials.Error = err.Error()
+
+		return r.credentials
+	}
+
+	r.credentials = webserver.CheckStatus{Ready: true, LastSuccess: &now}
+
+	return r.credentials
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/exporter"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/iam"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Output formats of the iam-policy command
+const (
+	policyFormatJSON      = "json"
+	policyFormatTerraform = "terraform"
+
+	terraformPolicyName = "prometheus_rds_exporter"
+)
+
+var errInvalidPolicyFormat = errors.New("format must be json or terraform")
+
+func newIAMPolicyCommand() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "iam-policy",
+		Short: "Generate the AWS IAM policy required by enabled collectors",
+		Long: `Generate the least-privilege AWS IAM policy required by the collectors enabled in the configuration,
+	as a policy document or a Terraform aws_iam_policy_document data source.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var c exporterConfig
+
+			err := viper.Unmarshal(&c)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "ERROR: Unable to decode configuration:", err)
+				os.Exit(configErrorExitCode)
+			}
+
+			policy, err := generateIAMPolicy(c, format)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "ERROR: Unable to generate IAM policy:", err)
+				os.Exit(configErrorExitCode)
+			}
+
+			fmt.Fprint(cmd.OutOrStdout(), policy)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", policyFormatJSON, "Output format (json or terraform)")
+
+	return cmd
+}
+
+// getPartition returns the AWS partition of the configuration regions
+// Regions of a configuration share credentials, so they belong to a single partition
+func getPartition(configuration exporterConfig) string {
+	if len(configuration.AWSRegions) == 0 {
+		return iam.DefaultPartition
+	}
+
+	return iam.Partition(configuration.AWSRegions[0])
+}
+
+// generateIAMPolicy returns the policy required by collectors of the configuration and of its probe modules
+func generateIAMPolicy(configuration exporterConfig, format string) (string, error) {
+	if format != policyFormatJSON && format != policyFormatTerraform {
+		return "", fmt.Errorf("%w: '%s'", errInvalidPolicyFormat, format)
+	}
+
+	statements := exporter.RequiredPermissions(newCollectorConfiguration(configuration), getPartition(configuration))
+
+	if configuration.ProbeEnabled {
+		modules, err := getProbeModules(configuration)
+		if err != nil {
+			return "", err
+		}
+
+		names := make([]string, 0, len(modules))
+		for name := range modules {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			statements = append(statements, exporter.RequiredPermissions(newCollectorConfiguration(modules[name]), getPartition(modules[name]))...)
+		}
+	}
+
+	policy := iam.NewPolicy(statements)
+
+	if format == policyFormatTerraform {
+		return policy.Terraform(terraformPolicyName), nil
+	}
+
+	document, err := policy.JSON()
+	if err != nil {
+		return "", err
+	}
+
+	return string(document), nil
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateIAMPolicyPartition(t *testing.T) {
+	testCases := []struct {
+		regions  []string
+		resource string
+	}{
+		{regions: nil, resource: `"arn:aws:rds:*:*:db:*"`},
+		{regions: []string{"eu-west-3"}, resource: `"arn:aws:rds:*:*:db:*"`},
+		{regions: []string{"cn-north-1", "cn-northwest-1"}, resource: `"arn:aws-cn:rds:*:*:db:*"`},
+		{regions: []string{"us-gov-west-1"}, resource: `"arn:aws-us-gov:rds:*:*:cluster:*"`},
+	}
+
+	for _, tc := range testCases {
+		policy, err := generateIAMPolicy(exporterConfig{AWSRegions: tc.regions}, policyFormatJSON)
+		require.NoError(t, err, "Policy should be generated")
+		assert.Contains(t, policy, tc.resource, "Resources of %v should belong to their partition", tc.regions)
+	}
+
+	_, err := generateIAMPolicy(exporterConfig{}, "yaml")
+	assert.ErrorIs(t, err, errInvalidPolicyFormat, "Unknown format should fail")
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+)
+
+// newInventoryFunc returns instances of region collectors served by the inventory and service discovery endpoints
+// tags are instance tags added as service discovery labels
+func newInventoryFunc(collectors *collectorSet, tags []string) webserver.InventoryFunc {
+	return func() []webserver.InventoryInstance {
+		var instances []webserver.InventoryInstance
+
+		for _, collector := range collectors.Collectors() {
+			for _, instance := range collector.Inventory() {
+				instances = append(instances, webserver.InventoryInstance{
+					AccountID:    instance.AccountID,
+					Region:       instance.Region,
+					DBIdentifier: instance.DBIdentifier,
+					Engine:       instance.RDS.Engine,
+					Tags:         instance.RDS.Tags,
+					Endpoint:     instance.Endpoint(),
+					Labels:       instance.TargetLabels(tags),
+					Details:      instance,
+				})
+			}
+		}
+
+		return instances
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+	"github.com/mitchellh/mapstructure"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+)
+
+// getProbeModules returns probe modules defined in the probe-modules section of the configuration file
+// Modules override parameters of the exporter configuration, the default module is the exporter configuration
+func getProbeModules(configuration exporterConfig) (map[string]exporterConfig, error) {
+	modules := map[string]exporterConfig{
+		webserver.DefaultProbeModule: configuration,
+	}
+
+	for name := range viper.GetStringMap("probe-modules") {
+		module := configuration
+
+		// Slices and maps are replaced rather than updated in place, they share their storage with the exporter configuration
+		err := viper.UnmarshalKey("probe-modules."+name, &module, viper.DecoderConfigOption(func(config *mapstructure.DecoderConfig) {
+			config.ZeroFields = true
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("can't decode probe module %s: %w", name, err)
+		}
+
+		modules[name] = module
+	}
+
+	return modules, nil
+}
+
+// newProbeCollectorFactory returns a factory of collectors assuming the target AWS IAM role in the requested region
+// An empty target uses the exporter AWS credentials
+func newProbeCollectorFactory(logger *slog.Logger, modules map[string]exporterConfig, dependencies collectorDependencies) webserver.ProbeCollectorFactory {
+	return func(target string, region string, module string) (prometheus.Collector, error) {
+		configuration, found := modules[module]
+		if !found {
+			return nil, fmt.Errorf("%w: %s", webserver.ErrUnknownProbeModule, module)
+		}
+
+		cfg, err := getAWSConfiguration(logger, target, configuration.AWSAssumeRoleSession, region)
+		if err != nil {
+			return nil, err
+		}
+
+		collector, err := newCollector(logger, configuration, cfg, dependencies)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Info("Probe collector initialized", "target", target, "region", region, "module", module)
+
+		return collector, nil
+	}
+}
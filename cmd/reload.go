@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+)
+
+// ReloadPath is the path of the configuration reload endpoint
+const ReloadPath = "/-/reload"
+
+// restartParameters lists parameters read at startup only, their changes are ignored on reload
+var restartParameters = []string{
+	"debug", "log-format", "listen-address", "metrics-path", "tls-cert-path", "tls-key-path", "web-config-file",
+	"instance-class-catalog-path", "instance-types-cache-path", "instance-types-cache-ttl",
+	"metric-stream-enabled", "metric-stream-path", "metric-stream-format", "metric-stream-access-key",
+	"probe-enabled", "probe-path", "probe-modules", "sd-tags", "reload-endpoint-enabled", "watch-config-file",
+}
+
+// reloader reloads the configuration file and rebuilds collectors whose settings changed
+type reloader struct {
+	logger     *slog.Logger
+	collectors *collectorSet
+	mutex      sync.Mutex
+
+	lastReloadSuccessful prometheus.Gauge
+	lastReloadSuccess    prometheus.Gauge
+}
+
+func newReloader(logger *slog.Logger, collectors *collectorSet, registerer prometheus.Registerer) (*reloader, error) {
+	r := &reloader{
+		logger:     logger,
+		collectors: collectors,
+		lastReloadSuccessful: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "rds_exporter_config_last_reload_successful",
+			Help: "Whether the last configuration reload attempt was successful",
+		}),
+		lastReloadSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "rds_exporter_config_last_reload_success_timestamp_seconds",
+			Help: "Timestamp of the last successful configuration reload",
+		}),
+	}
+
+	for _, collector := range []prometheus.Collector{r.lastReloadSuccessful, r.lastReloadSuccess} {
+		err := registerer.Register(collector)
+		if err != nil {
+			return nil, fmt.Errorf("can't register reload metrics: %w", err)
+		}
+	}
+
+	// Initial configuration is loaded before the reloader is created
+	r.lastReloadSuccessful.Set(1)
+	r.lastReloadSuccess.SetToCurrentTime()
+
+	return r, nil
+}
+
+// Reload reads the configuration file again and applies it to collectors
+func (r *reloader) Reload() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	err := r.reload()
+	if err != nil {
+		r.lastReloadSuccessful.Set(0)
+		r.logger.Error("configuration reload failed, keeping current configuration", "reason", err)
+
+		return err
+	}
+
+	r.lastReloadSuccessful.Set(1)
+	r.lastReloadSuccess.SetToCurrentTime()
+	r.logger.Info("configuration reloaded", "file", viper.ConfigFileUsed())
+
+	return nil
+}
+
+func (r *reloader) reload() error {
+	err := viper.ReadInConfig()
+
+	var notFound viper.ConfigFileNotFoundError
+	if err != nil && !errors.As(err, &notFound) {
+		return fmt.Errorf("can't read configuration file: %w", err)
+	}
+
+	var configuration exporterConfig
+
+	err = viper.Unmarshal(&configuration)
+	if err != nil {
+		return fmt.Errorf("can't decode configuration: %w", err)
+	}
+
+	for _, name := range restoreParameters(r.collectors.Configuration(), &configuration, restartParameters) {
+		r.logger.Warn("configuration parameter change requires a restart, ignoring it", "parameter", name)
+	}
+
+	return r.collectors.Load(configuration)
+}
+
+// WatchSignal reloads the configuration on SIGHUP
+func (r *reloader) WatchSignal() {
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, syscall.SIGHUP) // kill -SIGHUP XXXX
+
+	go func() {
+		for range signalChan {
+			r.logger.Info("received SIGHUP, reloading configuration")
+			_ = r.Reload()
+		}
+	}()
+}
+
+// WatchFile reloads the configuration when the configuration file at path changes
+// The directory is watched because editors replace files and Kubernetes swaps ConfigMap symlinks instead of writing them
+// Changes only trigger Reload, so the file is read under the reload mutex like SIGHUP and endpoint reloads
+// stop closes the watcher and waits for the running reload
+func (r *reloader) WatchFile(path string) (stop func(), err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("can't create configuration file watcher: %w", err)
+	}
+
+	file := filepath.Clean(path)
+
+	err = watcher.Add(filepath.Dir(file))
+	if err != nil {
+		watcher.Close()
+
+		return nil, fmt.Errorf("can't watch configuration file %s: %w", file, err)
+	}
+
+	realPath, _ := filepath.EvalSymlinks(file)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				currentPath, _ := filepath.EvalSymlinks(file)
+				written := filepath.Clean(event.Name) == file && (event.Has(fsnotify.Write) || event.Has(fsnotify.Create))
+				swapped := currentPath != "" && currentPath != realPath
+
+				if !written && !swapped {
+					continue
+				}
+
+				realPath = currentPath
+
+				r.logger.Info("configuration file changed, reloading configuration", "file", file)
+				_ = r.Reload()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				r.logger.Warn("configuration file watch failed", "file", file, "reason", err)
+			}
+		}
+	}()
+
+	stop = func() {
+		watcher.Close()
+		<-done
+	}
+
+	return stop, nil
+}
+
+// ServeHTTP reloads the configuration on POST requests
+func (r *reloader) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost && req.Method != http.MethodPut {
+		http.Error(w, "only POST or PUT requests allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	err := r.Reload()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to reload configuration: %s", err), http.StatusInternalServerError)
+
+		return
+	}
+
+	_, _ = w.Write([]byte("OK\n"))
+}
+
+// restoreParameters sets parameters of configuration to their current value and returns names of the changed ones
+func restoreParameters(current exporterConfig, configuration *exporterConfig, parameters []string) []string {
+	selected := make(map[string]bool, len(parameters))
+	for _, name := range parameters {
+		selected[name] = true
+	}
+
+	var changed []string
+
+	currentValue := reflect.ValueOf(current)
+	newValue := reflect.ValueOf(configuration).Elem()
+
+	for i := 0; i < currentValue.NumField(); i++ {
+		name := currentValue.Type().Field(i).Tag.Get("mapstructure")
+		if !selected[name] || reflect.DeepEqual(currentValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			continue
+		}
+
+		changed = append(changed, name)
+		newValue.Field(i).Set(currentValue.Field(i))
+	}
+
+	return changed
+}
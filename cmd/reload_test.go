@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreParameters(t *testing.T) {
+	current := exporterConfig{Debug: false, ListenAddress: ":9043", MetricPath: "/metrics", AWSRegions: []string{"eu-west-1"}}
+	configuration := exporterConfig{Debug: true, ListenAddress: ":9000", MetricPath: "/metrics", AWSRegions: []string{"eu-west-3"}}
+
+	changed := restoreParameters(current, &configuration, []string{"debug", "listen-address", "metrics-path"})
+
+	assert.Equal(t, []string{"debug", "listen-address"}, changed, "Only changed parameters should be reported, in declaration order")
+	assert.False(t, configuration.Debug, "Changed parameter should be restored")
+	assert.Equal(t, ":9043", configuration.ListenAddress, "Changed parameter should be restored")
+	assert.Equal(t, []string{"eu-west-3"}, configuration.AWSRegions, "Parameters that are not selected should be applied")
+
+	assert.Empty(t, restoreParameters(current, &configuration, restartParameters), "Restored configuration should not have changes")
+}
+
+func TestRestartParametersExist(t *testing.T) {
+	parameters := make(map[string]bool)
+	for _, setting := range getRedactedConfiguration(exporterConfig{}) {
+		parameters[setting.Name] = true
+	}
+
+	for _, name := range restartParameters {
+		assert.True(t, parameters[name], "restart parameter %s should be a configuration parameter", name)
+	}
+}
+
+// writeConfiguration writes a configuration file with regions, like an editor replacing the file
+func writeConfiguration(t *testing.T, path string, regions string) {
+	t.Helper()
+
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte("aws-regions: "+regions+"\n"), 0o600), "Configuration file must be written")
+	require.NoError(t, os.Rename(tmp, path), "Configuration file must be replaced")
+}
+
+func newTestReloader(t *testing.T, path string) (*reloader, *collectorSet) {
+	t.Helper()
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.SetConfigFile(path)
+
+	set, _ := newTestCollectorSet(t)
+	require.NoError(t, set.Load(exporterConfig{AWSRegions: []string{"eu-west-1"}}), "Initial load should succeed")
+
+	r, err := newReloader(set.logger, set, prometheus.NewRegistry())
+	require.NoError(t, err, "Reloader must be initialized")
+
+	return r, set
+}
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prometheus-rds-exporter.yaml")
+	writeConfiguration(t, path, "[eu-west-1]")
+
+	r, set := newTestReloader(t, path)
+	stop, err := r.WatchFile(path)
+	require.NoError(t, err, "Configuration file should be watched")
+	t.Cleanup(stop)
+
+	writeConfiguration(t, path, "[eu-west-3]")
+
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"eu-west-3"}, set.Configuration().AWSRegions)
+	}, 5*time.Second, 10*time.Millisecond, "Replaced configuration file should be reloaded")
+}
+
+func TestWatchFileWithSymlinks(t *testing.T) {
+	// Kubernetes mounts ConfigMaps as symlinks to a data directory swapped on update
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prometheus-rds-exporter.yaml")
+
+	for _, version := range []string{"v1", "v2"} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, version), 0o700), "Data directory must be created")
+	}
+
+	writeConfiguration(t, filepath.Join(dir, "v1", "prometheus-rds-exporter.yaml"), "[eu-west-1]")
+	writeConfiguration(t, filepath.Join(dir, "v2", "prometheus-rds-exporter.yaml"), "[eu-west-3]")
+	require.NoError(t, os.Symlink("v1", filepath.Join(dir, "..data")), "Data symlink must be created")
+	require.NoError(t, os.Symlink(filepath.Join("..data", "prometheus-rds-exporter.yaml"), path), "File symlink must be created")
+
+	r, set := newTestReloader(t, path)
+	stop, err := r.WatchFile(path)
+	require.NoError(t, err, "Configuration file should be watched")
+	t.Cleanup(stop)
+
+	require.NoError(t, os.Symlink("v2", filepath.Join(dir, "..data_tmp")), "Data symlink must be created")
+	require.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")), "Data symlink must be swapped")
+
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"eu-west-3"}, set.Configuration().AWSRegions)
+	}, 5*time.Second, 10*time.Millisecond, "Swapped configuration file should be reloaded")
+}
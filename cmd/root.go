@@ -1,25 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
-	"github.com/TeiNam/prometheus-rds-exporter/internal/app/exporter"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/metricstream"
 	"github.com/TeiNam/prometheus-rds-exporter/internal/infra/build"
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
 	"github.com/TeiNam/prometheus-rds-exporter/internal/infra/logger"
-	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
-	"github.com/aws/aws-sdk-go-v2/service/ec2"
-	"github.com/aws/aws-sdk-go-v2/service/rds"
-	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
-	dto "github.com/prometheus/client_model/go"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"log/slog"
 )
 
 const (
@@ -31,31 +27,57 @@ const (
 
 var cfgFile string
 
-type exporterConfig struct {
-	Debug                  bool     `mapstructure:"debug"`
-	LogFormat              string   `mapstructure:"log-format"`
-	TLSCertPath            string   `mapstructure:"tls-cert-path"`
-	TLSKeyPath             string   `mapstructure:"tls-key-path"`
-	MetricPath             string   `mapstructure:"metrics-path"`
-	ListenAddress          string   `mapstructure:"listen-address"`
-	AWSAssumeRoleSession   string   `mapstructure:"aws-assume-role-session"`
-	AWSAssumeRoleArn       string   `mapstructure:"aws-assume-role-arn"`
-	CollectInstanceMetrics bool     `mapstructure:"collect-instance-metrics"`
-	CollectInstanceTags    bool     `mapstructure:"collect-instance-tags"`
-	CollectInstanceTypes   bool     `mapstructure:"collect-instance-types"`
-	CollectLogsSize        bool     `mapstructure:"collect-logs-size"`
-	CollectMaintenances    bool     `mapstructure:"collect-maintenances"`
-	CollectQuotas          bool     `mapstructure:"collect-quotas"`
-	CollectUsages          bool     `mapstructure:"collect-usages"`
-	AWSRegions             []string `mapstructure:"aws-regions"`
-}
+// envPrefix is the prefix of environment variables overriding parameters (eg. PROMETHEUS_RDS_EXPORTER_LOG_FORMAT)
+const envPrefix = "prometheus_rds_exporter"
 
-type loggerWrapper struct {
-	logger *slog.Logger
-}
+// envKeyReplacer replaces characters of parameter names not allowed in environment variable names
+var envKeyReplacer = strings.NewReplacer("-", "_")
 
-func (lw *loggerWrapper) Println(v ...interface{}) {
-	lw.logger.Info(fmt.Sprintln(v...))
+type exporterConfig struct {
+	Debug                      bool           `mapstructure:"debug"`
+	LogFormat                  string         `mapstructure:"log-format"`
+	TLSCertPath                string         `mapstructure:"tls-cert-path"`
+	TLSKeyPath                 string         `mapstructure:"tls-key-path"`
+	WebConfigFile              string         `mapstructure:"web-config-file"`
+	MetricPath                 string         `mapstructure:"metrics-path"`
+	ListenAddress              string         `mapstructure:"listen-address"`
+	AWSAssumeRoleSession       string         `mapstructure:"aws-assume-role-session"`
+	AWSAssumeRoleArn           string         `mapstructure:"aws-assume-role-arn"`
+	AuroraIOOptimizedPrice     float64        `mapstructure:"aurora-io-optimized-storage-price"`
+	AuroraStandardIOPrice      float64        `mapstructure:"aurora-standard-io-price"`
+	AuroraStandardStoragePrice float64        `mapstructure:"aurora-standard-storage-price"`
+	CloudWatchLookback         time.Duration  `mapstructure:"cloudwatch-lookback"`
+	CloudWatchMaxStaleness     time.Duration  `mapstructure:"cloudwatch-max-staleness"`
+	CloudWatchUseTimestamps    bool           `mapstructure:"cloudwatch-use-timestamps"`
+	CollectInstanceMetrics     bool           `mapstructure:"collect-instance-metrics"`
+	CollectInstanceTags        bool           `mapstructure:"collect-instance-tags"`
+	CollectInstanceTypes       bool           `mapstructure:"collect-instance-types"`
+	CollectLogsSize            bool           `mapstructure:"collect-logs-size"`
+	CollectMaintenances        bool           `mapstructure:"collect-maintenances"`
+	CollectPerformanceInsights bool           `mapstructure:"collect-performance-insights"`
+	CollectQuotas              bool           `mapstructure:"collect-quotas"`
+	CollectUsages              bool           `mapstructure:"collect-usages"`
+	InstanceClassCatalogPath   string         `mapstructure:"instance-class-catalog-path"`
+	InstanceTypesCachePath     string         `mapstructure:"instance-types-cache-path"`
+	InstanceTypesCacheTTL      time.Duration  `mapstructure:"instance-types-cache-ttl"`
+	MetricStreamAccessKey      string         `mapstructure:"metric-stream-access-key"`
+	MetricStreamEnabled        bool           `mapstructure:"metric-stream-enabled"`
+	MetricStreamFormat         string         `mapstructure:"metric-stream-format"`
+	MetricStreamPath           string         `mapstructure:"metric-stream-path"`
+	PerformanceInsightsTopSQL  int32          `mapstructure:"performance-insights-top-sql"`
+	ProbeEnabled               bool           `mapstructure:"probe-enabled"`
+	ProbePath                  string         `mapstructure:"probe-path"`
+	QuotasAllowList            []string       `mapstructure:"quotas-allow-list"`
+	ReadinessMaxAge            time.Duration  `mapstructure:"readiness-max-age"`
+	ReloadEndpointEnabled      bool           `mapstructure:"reload-endpoint-enabled"`
+	RightsizingEnabled         bool           `mapstructure:"rightsizing-enabled"`
+	RightsizingMinSamples      int            `mapstructure:"rightsizing-min-samples"`
+	RightsizingThreshold       float64        `mapstructure:"rightsizing-threshold"`
+	RightsizingWindow          time.Duration  `mapstructure:"rightsizing-window"`
+	ServiceDiscoveryTags       []string       `mapstructure:"sd-tags"`
+	WatchConfigFile            bool           `mapstructure:"watch-config-file"`
+	ProbeModules               map[string]any `mapstructure:"probe-modules"` // Decoded by getProbeModules, declared for strict decoding
+	AWSRegions                 []string       `mapstructure:"aws-regions"`
 }
 
 func run(configuration exporterConfig) {
@@ -65,79 +87,109 @@ func run(configuration exporterConfig) {
 		panic(err)
 	}
 
-	registries := make(map[string]*prometheus.Registry)
+	err = webserver.ValidateWebConfig(configuration.WebConfigFile)
+	if err != nil {
+		logger.Error("can't load web configuration", "reason", err)
+		os.Exit(configErrorExitCode)
+	}
 
-	for _, region := range configuration.AWSRegions {
-		logger.Info("Initializing AWS configuration for region", "region", region)
-		cfg, err := getAWSConfiguration(logger, configuration.AWSAssumeRoleArn, configuration.AWSAssumeRoleSession, region)
-		if err != nil {
-			logger.Error("can't initialize AWS configuration for region", "region", region, "reason", err)
-			os.Exit(awsErrorExitCode)
-		}
+	collectorDependencies, err := newCollectorDependencies(logger, configuration)
+	if err != nil {
+		logger.Error("can't load instance classes", "reason", err)
+		os.Exit(configErrorExitCode)
+	}
 
-		awsAccountID, awsRegion, err := getAWSSessionInformation(cfg)
+	var metricStreamStore *metricstream.Store
+	if configuration.MetricStreamEnabled {
+		metricStreamStore = metricstream.NewStore(configuration.CloudWatchMaxStaleness)
+		collectorDependencies.metricStreamStore = metricStreamStore
+	}
+
+	collectors := newCollectorSet(logger, collectorDependencies)
+
+	err = collectors.Load(configuration)
+	if err != nil {
+		logger.Error("can't initialize collectors", "reason", err)
+		os.Exit(awsErrorExitCode)
+	}
+
+	reloader, err := newReloader(logger, collectors, prometheus.DefaultRegisterer)
+	if err != nil {
+		logger.Error("can't initialize configuration reload", "reason", err)
+		os.Exit(configErrorExitCode)
+	}
+
+	reloader.WatchSignal()
+
+	if configuration.WatchConfigFile && viper.ConfigFileUsed() != "" {
+		_, err = reloader.WatchFile(viper.ConfigFileUsed())
 		if err != nil {
-			logger.Error("can't identify AWS account and/or region", "reason", err)
-			os.Exit(awsErrorExitCode)
+			logger.Error("can't watch configuration file", "reason", err)
+			os.Exit(configErrorExitCode)
 		}
 
-		logger.Info("Successfully initialized AWS configuration", "region", region, "accountID", awsAccountID, "awsRegion", awsRegion)
-
-		rdsClient := rds.NewFromConfig(cfg)
-		ec2Client := ec2.NewFromConfig(cfg)
-		cloudWatchClient := cloudwatch.NewFromConfig(cfg)
-		servicequotasClient := servicequotas.NewFromConfig(cfg)
-
-		collectorConfiguration := exporter.Configuration{
-			CollectInstanceMetrics: configuration.CollectInstanceMetrics,
-			CollectInstanceTypes:   configuration.CollectInstanceTypes,
-			CollectInstanceTags:    configuration.CollectInstanceTags,
-			CollectLogsSize:        configuration.CollectLogsSize,
-			CollectMaintenances:    configuration.CollectMaintenances,
-			CollectQuotas:          configuration.CollectQuotas,
-			CollectUsages:          configuration.CollectUsages,
+		logger.Info("Watching configuration file", "file", viper.ConfigFileUsed())
+	}
+
+	handlers := make(map[string]http.Handler)
+
+	var publicPaths []string
+
+	inventory := newInventoryFunc(collectors, configuration.ServiceDiscoveryTags)
+	inventoryHandler := webserver.NewInventoryHandler(inventory)
+	handlers[webserver.InventoryPath] = inventoryHandler
+	handlers[webserver.InventoryPath+"/"] = inventoryHandler
+	handlers[webserver.DiscoveryPath] = webserver.NewDiscoveryHandler(inventory)
+
+	if configuration.ReloadEndpointEnabled {
+		handlers[ReloadPath] = reloader
+	}
+
+	if metricStreamStore != nil {
+		metricStreamHandler, err := metricstream.NewHandler(*logger, metricStreamStore, metricstream.Configuration{
+			Format:    configuration.MetricStreamFormat,
+			AccessKey: configuration.MetricStreamAccessKey,
+		})
+		if err != nil {
+			logger.Error("can't initialize metric stream receiver", "reason", err)
+			os.Exit(configErrorExitCode)
 		}
 
-		collector := exporter.NewCollector(*logger, collectorConfiguration, awsAccountID, awsRegion, rdsClient, ec2Client, cloudWatchClient, servicequotasClient)
+		handlers[configuration.MetricStreamPath] = metricStreamHandler
+		publicPaths = append(publicPaths, configuration.MetricStreamPath) // AWS Firehose authenticates with the metric stream access key
+		logger.Info("Receiving Cloudwatch metric stream", "path", configuration.MetricStreamPath, "format", configuration.MetricStreamFormat)
+	}
 
-		registry := prometheus.NewRegistry()
-		err = registry.Register(collector)
+	if configuration.ProbeEnabled {
+		modules, err := getProbeModules(configuration)
 		if err != nil {
-			logger.Error("Failed to register collector", "region", region, "reason", err)
-			continue
+			logger.Error("can't load probe modules", "reason", err)
+			os.Exit(configErrorExitCode)
+		}
+
+		var defaultRegion string
+		if len(configuration.AWSRegions) > 0 {
+			defaultRegion = configuration.AWSRegions[0]
 		}
-		registries[region] = registry
-
-		logger.Info("Collector registered for region", "region", region)
-	}
-
-	mux := http.NewServeMux()
-	mux.Handle(configuration.MetricPath, promhttp.HandlerFor(prometheus.Gatherers{
-		prometheus.DefaultGatherer,
-		prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
-			var metrics []*dto.MetricFamily
-			for region, registry := range registries {
-				mfs, err := registry.Gather()
-				if err != nil {
-					logger.Error("Failed to gather metrics", "region", region, "reason", err)
-					continue
-				}
-				metrics = append(metrics, mfs...)
-			}
-			return metrics, nil
-		}),
-	}, promhttp.HandlerOpts{
-		ErrorLog:      &loggerWrapper{logger},
-		ErrorHandling: promhttp.ContinueOnError,
-	}))
 
-	server := &http.Server{
-		Addr:    configuration.ListenAddress,
-		Handler: mux,
+		handlers[configuration.ProbePath] = webserver.NewProbeHandler(*logger, defaultRegion, newProbeCollectorFactory(logger, modules, collectorDependencies))
+		logger.Info("Serving probe requests", "path", configuration.ProbePath, "modules", len(modules))
 	}
 
-	logger.Info("Starting web server", "address", configuration.ListenAddress)
-	err = server.ListenAndServe()
+	server := webserver.New(*logger, webserver.Config{
+		MetricPath:    configuration.MetricPath,
+		ListenAddress: configuration.ListenAddress,
+		TLSCertPath:   configuration.TLSCertPath,
+		TLSKeyPath:    configuration.TLSKeyPath,
+		WebConfigPath: configuration.WebConfigFile,
+		Targets:       collectors.Targets,
+		Readiness:     collectors.Readiness,
+		Status:        newStatusFunc(collectors),
+		Handlers:      handlers,
+		PublicPaths:   publicPaths,
+	})
+
+	err = server.Start(context.Background())
 	if err != nil {
 		logger.Error("web server error", "reason", err)
 		os.Exit(httpErrorExitCode)
@@ -164,99 +216,271 @@ func NewRootCommand() (*cobra.Command, error) {
 
 	cobra.OnInitialize(initConfig)
 
-	cmd.Flags().StringVarP(&cfgFile, "config", "c", "", "config file (default is $HOME/prometheus-rds-exporter.yaml)")
-	cmd.Flags().BoolP("debug", "d", false, "Enable debug mode")
-	cmd.Flags().StringP("log-format", "l", "json", "Log format (text or json)")
-	cmd.Flags().StringP("metrics-path", "", "/metrics", "Path under which to expose metrics")
-	cmd.Flags().StringP("tls-cert-path", "", "", "Path to TLS certificate")
-	cmd.Flags().StringP("tls-key-path", "", "", "Path to private key for TLS")
-	cmd.Flags().StringP("listen-address", "", ":9043", "Address to listen on for web interface")
-	cmd.Flags().StringP("aws-assume-role-arn", "", "", "AWS IAM ARN role to assume to fetch metrics")
-	cmd.Flags().StringP("aws-assume-role-session", "", "prometheus-rds-exporter", "AWS assume role session name")
-	cmd.Flags().BoolP("collect-instance-tags", "", true, "Collect AWS RDS tags")
-	cmd.Flags().BoolP("collect-instance-types", "", true, "Collect AWS instance types")
-	cmd.Flags().BoolP("collect-instance-metrics", "", true, "Collect AWS instance metrics")
-	cmd.Flags().BoolP("collect-logs-size", "", true, "Collect AWS instances logs size")
-	cmd.Flags().BoolP("collect-maintenances", "", true, "Collect AWS instances maintenances")
-	cmd.Flags().BoolP("collect-quotas", "", true, "Collect AWS RDS quotas")
-	cmd.Flags().BoolP("collect-usages", "", true, "Collect AWS RDS usages")
-	cmd.Flags().StringSliceP("aws-regions", "", []string{"ap-northeast-2"}, "AWS regions to fetch metrics from")
-
-	err := viper.BindPFlag("debug", cmd.Flags().Lookup("debug"))
+	cmd.AddCommand(newCheckConfigCommand())
+	cmd.AddCommand(newDoctorCommand())
+	cmd.AddCommand(newDumpCommand())
+	cmd.AddCommand(newIAMPolicyCommand())
+
+	cmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "config file (default is $HOME/prometheus-rds-exporter.yaml)")
+	cmd.PersistentFlags().BoolP("debug", "d", false, "Enable debug mode")
+	cmd.PersistentFlags().StringP("log-format", "l", "json", "Log format (text or json)")
+	cmd.PersistentFlags().StringP("metrics-path", "", "/metrics", "Path under which to expose metrics")
+	cmd.PersistentFlags().StringP("tls-cert-path", "", "", "Path to TLS certificate")
+	cmd.PersistentFlags().StringP("tls-key-path", "", "", "Path to private key for TLS")
+	cmd.PersistentFlags().BoolP("watch-config-file", "", false, "Reload configuration when the configuration file changes")
+	cmd.PersistentFlags().StringP("web-config-file", "", "", "Path to the web configuration file enabling TLS and authentication")
+	cmd.PersistentFlags().StringP("listen-address", "", ":9043", "Address to listen on for web interface")
+	cmd.PersistentFlags().StringP("aws-assume-role-arn", "", "", "AWS IAM ARN role to assume to fetch metrics")
+	cmd.PersistentFlags().StringP("aws-assume-role-session", "", "prometheus-rds-exporter", "AWS assume role session name")
+	cmd.PersistentFlags().DurationP("cloudwatch-lookback", "", 3*time.Minute, "Time window used to fetch AWS Cloudwatch datapoints")
+	cmd.PersistentFlags().DurationP("cloudwatch-max-staleness", "", 0, "Drop AWS Cloudwatch datapoints older than this duration (0 to disable)")
+	cmd.PersistentFlags().BoolP("cloudwatch-use-timestamps", "", false, "Use AWS Cloudwatch datapoint timestamps as sample timestamps")
+	cmd.PersistentFlags().BoolP("collect-instance-tags", "", true, "Collect AWS RDS tags")
+	cmd.PersistentFlags().BoolP("collect-instance-types", "", true, "Collect AWS instance types")
+	cmd.PersistentFlags().BoolP("collect-instance-metrics", "", true, "Collect AWS instance metrics")
+	cmd.PersistentFlags().BoolP("collect-logs-size", "", true, "Collect AWS instances logs size")
+	cmd.PersistentFlags().BoolP("collect-maintenances", "", true, "Collect AWS instances maintenances")
+	cmd.PersistentFlags().BoolP("collect-performance-insights", "", false, "Collect AWS Performance Insights top wait events and top SQL")
+	cmd.PersistentFlags().BoolP("metric-stream-enabled", "", false, "Read instance metrics from AWS Cloudwatch metric streams instead of polling AWS Cloudwatch API")
+	cmd.PersistentFlags().StringP("metric-stream-path", "", "/metric-stream", "Path under which to receive AWS Firehose deliveries of the metric stream")
+	cmd.PersistentFlags().StringP("metric-stream-format", "", "json", "Metric stream output format (json or opentelemetry0.7)")
+	cmd.PersistentFlags().StringP("metric-stream-access-key", "", "", "Access key configured on the AWS Firehose HTTP endpoint")
+	cmd.PersistentFlags().Int32P("performance-insights-top-sql", "", 10, "Number of top SQL digests to collect per instance (1-25)")
+	cmd.PersistentFlags().BoolP("probe-enabled", "", false, "Serve metrics of AWS IAM roles and regions requested on the probe path")
+	cmd.PersistentFlags().StringP("probe-path", "", "/probe", "Path under which to serve probe requests")
+	cmd.PersistentFlags().StringSliceP("sd-tags", "", []string{}, "Instance tags added as target labels by the Prometheus HTTP service discovery endpoint")
+	cmd.PersistentFlags().BoolP("collect-quotas", "", true, "Collect AWS RDS quotas")
+	cmd.PersistentFlags().BoolP("collect-usages", "", true, "Collect AWS RDS usages")
+	cmd.PersistentFlags().StringP("instance-class-catalog-path", "", "", "Path to a JSON file overriding the embedded instance class catalog")
+	cmd.PersistentFlags().StringP("instance-types-cache-path", "", "", "Path to the file caching AWS EC2 instance types between restarts (empty: in memory only)")
+	cmd.PersistentFlags().DurationP("instance-types-cache-ttl", "", 24*time.Hour, "Duration before cached AWS EC2 instance types are refreshed")
+	cmd.PersistentFlags().Float64P("aurora-standard-storage-price", "", 0.10, "Price in dollars per GB-month of Aurora Standard storage, used to estimate Aurora storage costs")
+	cmd.PersistentFlags().Float64P("aurora-standard-io-price", "", 0.20, "Price in dollars per million I/O requests of Aurora Standard storage, used to estimate Aurora storage costs")
+	cmd.PersistentFlags().Float64P("aurora-io-optimized-storage-price", "", 0.225, "Price in dollars per GB-month of Aurora I/O-Optimized storage, used to estimate Aurora storage costs")
+	cmd.PersistentFlags().StringSliceP("quotas-allow-list", "", []string{}, "AWS RDS quota codes to export (default all quotas)")
+	cmd.PersistentFlags().DurationP("readiness-max-age", "", 10*time.Minute, "Maximum age of the last successful AWS RDS fetch of a ready region collector")
+	cmd.PersistentFlags().BoolP("reload-endpoint-enabled", "", false, "Reload configuration on POST requests to /-/reload")
+	cmd.PersistentFlags().BoolP("rightsizing-enabled", "", false, "Recommend instance classes from utilization observed over the rightsizing window (requires instance metrics and instance types collection)")
+	cmd.PersistentFlags().DurationP("rightsizing-window", "", 7*24*time.Hour, "Time window of observed utilization used to recommend instance classes")
+	cmd.PersistentFlags().IntP("rightsizing-min-samples", "", 288, "Minimum number of 5 minutes samples in the rightsizing window to recommend an instance class")
+	cmd.PersistentFlags().Float64P("rightsizing-threshold", "", 0.8, "Utilization ratio above which a resource is saturated")
+	cmd.PersistentFlags().StringSliceP("aws-regions", "", []string{"ap-northeast-2"}, "AWS regions to fetch metrics from")
+
+	err := viper.BindPFlag("debug", cmd.PersistentFlags().Lookup("debug"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'debug' parameter: %w", err)
 	}
 
-	err = viper.BindPFlag("log-format", cmd.Flags().Lookup("log-format"))
+	err = viper.BindPFlag("log-format", cmd.PersistentFlags().Lookup("log-format"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'log-format' parameter: %w", err)
 	}
 
-	err = viper.BindPFlag("metrics-path", cmd.Flags().Lookup("metrics-path"))
+	err = viper.BindPFlag("metrics-path", cmd.PersistentFlags().Lookup("metrics-path"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'metrics-path' parameter: %w", err)
 	}
 
-	err = viper.BindPFlag("tls-cert-path", cmd.Flags().Lookup("tls-cert-path"))
+	err = viper.BindPFlag("tls-cert-path", cmd.PersistentFlags().Lookup("tls-cert-path"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'tls-cert-path' parameter: %w", err)
 	}
 
-	err = viper.BindPFlag("tls-key-path", cmd.Flags().Lookup("tls-key-path"))
+	err = viper.BindPFlag("tls-key-path", cmd.PersistentFlags().Lookup("tls-key-path"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'tls-key-path' parameter: %w", err)
 	}
 
-	err = viper.BindPFlag("listen-address", cmd.Flags().Lookup("listen-address"))
+	err = viper.BindPFlag("web-config-file", cmd.PersistentFlags().Lookup("web-config-file"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'web-config-file' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("listen-address", cmd.PersistentFlags().Lookup("listen-address"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'listen-address' parameter: %w", err)
 	}
 
-	err = viper.BindPFlag("aws-assume-role-arn", cmd.Flags().Lookup("aws-assume-role-arn"))
+	err = viper.BindPFlag("aws-assume-role-arn", cmd.PersistentFlags().Lookup("aws-assume-role-arn"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'aws-assume-role-arn' parameter: %w", err)
 	}
 
-	err = viper.BindPFlag("aws-assume-role-session", cmd.Flags().Lookup("aws-assume-role-session"))
+	err = viper.BindPFlag("aws-assume-role-session", cmd.PersistentFlags().Lookup("aws-assume-role-session"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'aws-assume-role-session' parameter: %w", err)
 	}
 
-	err = viper.BindPFlag("collect-instance-metrics", cmd.Flags().Lookup("collect-instance-metrics"))
+	err = viper.BindPFlag("cloudwatch-lookback", cmd.PersistentFlags().Lookup("cloudwatch-lookback"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'cloudwatch-lookback' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("cloudwatch-max-staleness", cmd.PersistentFlags().Lookup("cloudwatch-max-staleness"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'cloudwatch-max-staleness' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("cloudwatch-use-timestamps", cmd.PersistentFlags().Lookup("cloudwatch-use-timestamps"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'cloudwatch-use-timestamps' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("collect-instance-metrics", cmd.PersistentFlags().Lookup("collect-instance-metrics"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'collect-instance-metrics' parameter: %w", err)
 	}
 
-	err = viper.BindPFlag("collect-instance-tags", cmd.Flags().Lookup("collect-instance-tags"))
+	err = viper.BindPFlag("collect-instance-tags", cmd.PersistentFlags().Lookup("collect-instance-tags"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'collect-instance-tags' parameter: %w", err)
 	}
 
-	err = viper.BindPFlag("collect-instance-types", cmd.Flags().Lookup("collect-instance-types"))
+	err = viper.BindPFlag("collect-instance-types", cmd.PersistentFlags().Lookup("collect-instance-types"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'collect-instance-types' parameter: %w", err)
 	}
 
-	err = viper.BindPFlag("collect-quotas", cmd.Flags().Lookup("collect-quotas"))
+	err = viper.BindPFlag("collect-quotas", cmd.PersistentFlags().Lookup("collect-quotas"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'collect-quotas' parameter: %w", err)
 	}
 
-	err = viper.BindPFlag("collect-usages", cmd.Flags().Lookup("collect-usages"))
+	err = viper.BindPFlag("collect-usages", cmd.PersistentFlags().Lookup("collect-usages"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'collect-usages' parameter: %w", err)
 	}
 
-	err = viper.BindPFlag("collect-logs-size", cmd.Flags().Lookup("collect-logs-size"))
+	err = viper.BindPFlag("instance-class-catalog-path", cmd.PersistentFlags().Lookup("instance-class-catalog-path"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'instance-class-catalog-path' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("instance-types-cache-path", cmd.PersistentFlags().Lookup("instance-types-cache-path"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'instance-types-cache-path' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("instance-types-cache-ttl", cmd.PersistentFlags().Lookup("instance-types-cache-ttl"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'instance-types-cache-ttl' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("aurora-standard-storage-price", cmd.PersistentFlags().Lookup("aurora-standard-storage-price"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'aurora-standard-storage-price' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("aurora-standard-io-price", cmd.PersistentFlags().Lookup("aurora-standard-io-price"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'aurora-standard-io-price' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("aurora-io-optimized-storage-price", cmd.PersistentFlags().Lookup("aurora-io-optimized-storage-price"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'aurora-io-optimized-storage-price' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("quotas-allow-list", cmd.PersistentFlags().Lookup("quotas-allow-list"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'quotas-allow-list' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("aws-regions", cmd.PersistentFlags().Lookup("aws-regions"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'aws-regions' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("readiness-max-age", cmd.PersistentFlags().Lookup("readiness-max-age"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'readiness-max-age' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("reload-endpoint-enabled", cmd.PersistentFlags().Lookup("reload-endpoint-enabled"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'reload-endpoint-enabled' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("rightsizing-enabled", cmd.PersistentFlags().Lookup("rightsizing-enabled"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'rightsizing-enabled' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("rightsizing-window", cmd.PersistentFlags().Lookup("rightsizing-window"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'rightsizing-window' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("rightsizing-min-samples", cmd.PersistentFlags().Lookup("rightsizing-min-samples"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'rightsizing-min-samples' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("rightsizing-threshold", cmd.PersistentFlags().Lookup("rightsizing-threshold"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'rightsizing-threshold' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("collect-logs-size", cmd.PersistentFlags().Lookup("collect-logs-size"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'collect-logs-size' parameter: %w", err)
 	}
 
-	err = viper.BindPFlag("collect-maintenances", cmd.Flags().Lookup("collect-maintenances"))
+	err = viper.BindPFlag("collect-maintenances", cmd.PersistentFlags().Lookup("collect-maintenances"))
 	if err != nil {
 		return cmd, fmt.Errorf("failed to bind 'collect-maintenances' parameter: %w", err)
 	}
 
+	err = viper.BindPFlag("collect-performance-insights", cmd.PersistentFlags().Lookup("collect-performance-insights"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'collect-performance-insights' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("metric-stream-enabled", cmd.PersistentFlags().Lookup("metric-stream-enabled"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'metric-stream-enabled' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("metric-stream-path", cmd.PersistentFlags().Lookup("metric-stream-path"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'metric-stream-path' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("metric-stream-format", cmd.PersistentFlags().Lookup("metric-stream-format"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'metric-stream-format' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("metric-stream-access-key", cmd.PersistentFlags().Lookup("metric-stream-access-key"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'metric-stream-access-key' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("performance-insights-top-sql", cmd.PersistentFlags().Lookup("performance-insights-top-sql"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'performance-insights-top-sql' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("probe-enabled", cmd.PersistentFlags().Lookup("probe-enabled"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'probe-enabled' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("probe-path", cmd.PersistentFlags().Lookup("probe-path"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'probe-path' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("sd-tags", cmd.PersistentFlags().Lookup("sd-tags"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'sd-tags' parameter: %w", err)
+	}
+
+	err = viper.BindPFlag("watch-config-file", cmd.PersistentFlags().Lookup("watch-config-file"))
+	if err != nil {
+		return cmd, fmt.Errorf("failed to bind 'watch-config-file' parameter: %w", err)
+	}
+
 	return cmd, nil
 }
 
@@ -303,13 +527,18 @@ func initConfig() {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 
-	viper.SetEnvPrefix("prometheus_rds_exporter") // will be uppercased automatically
-	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	viper.SetEnvPrefix(envPrefix) // will be uppercased automatically
+	viper.SetEnvKeyReplacer(envKeyReplacer)
 	viper.AutomaticEnv()
 
 	regions := viper.GetStringSlice("aws-regions")
 	if len(regions) == 0 {
 		regions = []string{"ap-northeast-2"} // 기본값 설정
 	}
-	viper.Set("aws-regions", regions)
+	viper.SetDefault("aws-regions", regions)
+}
+
+// getEnvName returns the environment variable overriding a parameter, like viper.AutomaticEnv
+func getEnvName(name string) string {
+	return strings.ToUpper(envPrefix + "_" + envKeyReplacer.Replace(name))
 }
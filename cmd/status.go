@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/exporter"
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+)
+
+// redactedParameters lists configuration parameters containing secrets
+var redactedParameters = map[string]bool{
+	"metric-stream-access-key": true,
+}
+
+// newStatusFunc returns the status of the current configuration and region collectors
+func newStatusFunc(collectors *collectorSet) webserver.StatusFunc {
+	return func() webserver.Status {
+		configuration := collectors.Configuration()
+
+		status := webserver.Status{
+			Collectors:    getCollectorSettings(configuration),
+			Configuration: getRedactedConfiguration(configuration),
+		}
+
+		for _, collector := range collectors.Collectors() {
+			status.Regions = append(status.Regions, getRegionStatus(collector))
+		}
+
+		return status
+	}
+}
+
+func getRegionStatus(collector *exporter.RdsCollector) webserver.RegionStatus {
+	counters := collector.GetStatistics()
+
+	status := webserver.RegionStatus{
+		Region:    collector.Region(),
+		AccountID: collector.AccountID(),
+		Errors:    counters.Errors,
+		APICalls: []webserver.Count{
+			{Name: exporter.SourceCloudwatch, Value: counters.CloudwatchAPICalls},
+			{Name: exporter.SourceEC2, Value: counters.EC2APIcalls},
+			{Name: exporter.SourcePerformanceInsights, Value: counters.PerformanceInsightsAPICalls},
+			{Name: exporter.SourceRDS, Value: counters.RDSAPIcalls},
+			{Name: exporter.SourceServiceQuotas, Value: counters.ServiceQuotasAPICalls},
+			{Name: exporter.SourceUsage, Value: counters.UsageAPIcalls},
+		},
+	}
+
+	for source, sourceStatus := range collector.SourceStatuses() {
+		s := webserver.SourceStatus{
+			Name:        source,
+			LastRefresh: sourceStatus.LastSuccess,
+			Duration:    sourceStatus.LastDuration.Round(time.Millisecond),
+		}
+
+		if sourceStatus.LastError != nil {
+			s.Error = sourceStatus.LastError.Error()
+		}
+
+		status.Sources = append(status.Sources, s)
+	}
+
+	sort.Slice(status.Sources, func(i, j int) bool { return status.Sources[i].Name < status.Sources[j].Name })
+
+	engines := make(map[string]float64)
+	for _, instance := range collector.GetMetrics().RDS.Instances {
+		engines[instance.Engine]++
+	}
+
+	for engine, count := range engines {
+		status.Engines = append(status.Engines, webserver.Count{Name: engine, Value: count})
+	}
+
+	sort.Slice(status.Engines, func(i, j int) bool { return status.Engines[i].Name < status.Engines[j].Name })
+
+	return status
+}
+
+// getCollectorSettings returns whether each optional collector is enabled
+func getCollectorSettings(configuration exporterConfig) []webserver.Setting {
+	return []webserver.Setting{
+		{Name: "instance metrics", Value: strconv.FormatBool(configuration.CollectInstanceMetrics)},
+		{Name: "instance tags", Value: strconv.FormatBool(configuration.CollectInstanceTags)},
+		{Name: "instance types", Value: strconv.FormatBool(configuration.CollectInstanceTypes)},
+		{Name: "logs size", Value: strconv.FormatBool(configuration.CollectLogsSize)},
+		{Name: "maintenances", Value: strconv.FormatBool(configuration.CollectMaintenances)},
+		{Name: "metric stream", Value: strconv.FormatBool(configuration.MetricStreamEnabled)},
+		{Name: "performance insights", Value: strconv.FormatBool(configuration.CollectPerformanceInsights)},
+		{Name: "probe", Value: strconv.FormatBool(configuration.ProbeEnabled)},
+		{Name: "quotas", Value: strconv.FormatBool(configuration.CollectQuotas)},
+		{Name: "rightsizing", Value: strconv.FormatBool(configuration.RightsizingEnabled)},
+		{Name: "usages", Value: strconv.FormatBool(configuration.CollectUsages)},
+	}
+}
+
+// getRedactedConfiguration returns configuration parameters sorted by name, secrets are redacted
+func getRedactedConfiguration(configuration exporterConfig) []webserver.Setting {
+	var parameters []webserver.Setting
+
+	value := reflect.ValueOf(configuration)
+	for i := 0; i < value.NumField(); i++ {
+		name := value.Type().Field(i).Tag.Get("mapstructure")
+		if name == "" {
+			continue
+		}
+
+		parameter := fmt.Sprint(value.Field(i).Interface())
+		if redactedParameters[name] && parameter != "" {
+			parameter = "<redacted>"
+		}
+
+		parameters = append(parameters, webserver.Setting{Name: name, Value: parameter})
+	}
+
+	sort.Slice(parameters, func(i, j int) bool { return parameters[i].Name < parameters[j].Name })
+
+	return parameters
+}
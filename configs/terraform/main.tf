@@ -36,6 +36,17 @@ data "aws_iam_policy_document" "prometheus-rds-exporter" {
     ]
   }
 
+  statement {
+    sid    = "AllowClusterDescriptions"
+    effect = "Allow"
+    actions = [
+      "rds:DescribeDBClusters",
+    ]
+    resources = [
+      "arn:aws:rds:*:*:cluster:*",
+    ]
+  }
+
   statement {
     sid    = "AllowMaintenanceDescriptions"
     effect = "Allow"
@@ -50,34 +61,39 @@ data "aws_iam_policy_document" "prometheus-rds-exporter" {
     effect = "Allow"
     actions = [
       "cloudwatch:GetMetricData",
+      "cloudwatch:ListMetrics",
     ]
     resources = ["*"]
   }
 
   statement {
-    sid    = "AllowRDSUsageDescriptions"
+    sid    = "AllowQuotaDescriptions"
     effect = "Allow"
     actions = [
-      "rds:DescribeAccountAttributes",
+      "servicequotas:ListServiceQuotas",
+      "servicequotas:ListAWSDefaultServiceQuotas",
     ]
     resources = ["*"]
   }
 
   statement {
-    sid    = "AllowQuotaDescriptions"
+    sid    = "AllowInstanceTypesDescriptions"
     effect = "Allow"
     actions = [
-      "servicequotas:GetServiceQuota",
+      "ec2:DescribeInstanceTypes",
     ]
     resources = ["*"]
   }
 
   statement {
-    sid    = "AllowInstanceTypesDescriptions"
+    sid    = "AllowPerformanceInsightsMetrics"
     effect = "Allow"
     actions = [
-      "ec2:DescribeInstanceTypes",
+      "pi:GetResourceMetrics",
+      "pi:DescribeDimensionKeys",
+    ]
+    resources = [
+      "arn:aws:pi:*:*:metrics/rds/*",
     ]
-    resources = ["*"]
   }
 }
@@ -0,0 +1,69 @@
+// Package awserror classifies errors returned by AWS API calls into a bounded set of categories
+package awserror
+
+import (
+	"errors"
+	"net"
+
+	"github.com/aws/smithy-go"
+	"golang.org/x/exp/slices"
+)
+
+// Category classifies an error returned by an AWS API call
+type Category string
+
+const (
+	CategoryOK                 Category = "ok"
+	CategoryAccessDenied       Category = "access_denied"
+	CategoryThrottling         Category = "throttling"
+	CategoryUnreachable        Category = "unreachable"
+	CategoryRegionDisabled     Category = "region_disabled"
+	CategoryInvalidCredentials Category = "invalid_credentials"
+	CategoryError              Category = "error"
+)
+
+// AWS API error codes by category, error codes differ between AWS services
+var errorCodes = map[Category][]string{
+	CategoryAccessDenied: {
+		"AccessDenied", "AccessDeniedException", "UnauthorizedOperation", "NotAuthorizedException", "UnauthorizedException",
+	},
+	CategoryThrottling: {
+		"Throttling", "ThrottlingException", "ThrottledException", "RequestLimitExceeded", "TooManyRequestsException", "RequestThrottled",
+	},
+	CategoryRegionDisabled: {
+		"OptInRequired",
+	},
+	CategoryInvalidCredentials: {
+		"InvalidClientTokenId", "UnrecognizedClientException", "AuthFailure", "ExpiredToken", "ExpiredTokenException", "SignatureDoesNotMatch",
+	},
+}
+
+// Classify returns the category of an error returned by an AWS API call
+// allowedErrors are AWS API error codes classified as OK, like errors on non-existent resources or dry runs
+func Classify(err error, allowedErrors ...string) Category {
+	if err == nil {
+		return CategoryOK
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if slices.Contains(allowedErrors, apiErr.ErrorCode()) {
+			return CategoryOK
+		}
+
+		for category, codes := range errorCodes {
+			if slices.Contains(codes, apiErr.ErrorCode()) {
+				return category
+			}
+		}
+
+		return CategoryError
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return CategoryUnreachable
+	}
+
+	return CategoryError
+}
@@ -0,0 +1,36 @@
+package awserror_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/awserror"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		allowed  []string
+		expected awserror.Category
+	}{
+		{"success", nil, nil, awserror.CategoryOK},
+		{"access denied", &smithy.GenericAPIError{Code: "AccessDenied"}, nil, awserror.CategoryAccessDenied},
+		{"unauthorized operation", fmt.Errorf("wrapped: %w", &smithy.GenericAPIError{Code: "UnauthorizedOperation"}), nil, awserror.CategoryAccessDenied},
+		{"throttling", &smithy.GenericAPIError{Code: "ThrottlingException"}, nil, awserror.CategoryThrottling},
+		{"opt-in region", &smithy.GenericAPIError{Code: "OptInRequired"}, nil, awserror.CategoryRegionDisabled},
+		{"invalid token", &smithy.GenericAPIError{Code: "InvalidClientTokenId"}, nil, awserror.CategoryInvalidCredentials},
+		{"allowed error", &smithy.GenericAPIError{Code: "DryRunOperation"}, []string{"DryRunOperation"}, awserror.CategoryOK},
+		{"unknown error code", &smithy.GenericAPIError{Code: "InvalidParameterValue"}, nil, awserror.CategoryError},
+		{"unreachable endpoint", &net.DNSError{Err: "no such host", Name: "rds.eu-west-99.amazonaws.com"}, nil, awserror.CategoryUnreachable},
+		{"other error", errors.New("boom"), nil, awserror.CategoryError},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, awserror.Classify(tc.err, tc.allowed...), tc.name)
+	}
+}
@@ -0,0 +1,146 @@
+package cloudwatch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_cloudwatch "github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	aws_cloudwath_types "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// clusterMetricsLookback is the time window used to fetch Aurora cluster volume datapoints, published every 5 minutes
+const clusterMetricsLookback = time.Hour
+
+type CloudWatchClusterMetrics struct {
+	Clusters map[string]*ClusterMetrics
+}
+
+// ClusterMetrics contains Aurora cluster volume metrics
+type ClusterMetrics struct {
+	VolumeBytesUsed *float64
+	VolumeReadIOPs  *float64 // Billed read I/O operations per 5 minutes interval
+	VolumeWriteIOPs *float64 // Billed write I/O operations per 5 minutes interval
+}
+
+func (m *ClusterMetrics) Update(field string, value float64) error {
+	switch field {
+	case "VolumeBytesUsed":
+		m.VolumeBytesUsed = &value
+	case "VolumeReadIOPs":
+		m.VolumeReadIOPs = &value
+	case "VolumeWriteIOPs":
+		m.VolumeWriteIOPs = &value
+	default:
+		return fmt.Errorf("can't process '%s' metrics: %w", field, errUnknownMetric)
+	}
+
+	return nil
+}
+
+// getClusterCloudWatchMetricsName returns names of Cloudwatch metrics to collect for Aurora clusters
+func getClusterCloudWatchMetricsName() [3]string {
+	return [3]string{
+		"VolumeBytesUsed",
+		"VolumeReadIOPs",
+		"VolumeWriteIOPs",
+	}
+}
+
+// generateCloudWatchQueriesForClusters returns all cloudwatch queries for specified Aurora clusters
+// Dbidentifier of requests contains the cluster identifier
+func generateCloudWatchQueriesForClusters(clusterIdentifiers []string) map[string]CloudWatchMetricRequest {
+	queries := make(map[string]CloudWatchMetricRequest)
+
+	for i, clusterIdentifier := range clusterIdentifiers {
+		for _, metricName := range getClusterCloudWatchMetricsName() {
+			queryID := aws.String(getQueryID(metricName, i))
+
+			queries[*queryID] = CloudWatchMetricRequest{
+				Dbidentifier: clusterIdentifier,
+				MetricName:   metricName,
+				Query: aws_cloudwath_types.MetricDataQuery{
+					Id: queryID,
+					MetricStat: &aws_cloudwath_types.MetricStat{
+						Metric: &aws_cloudwath_types.Metric{
+							Namespace:  aws.String("AWS/RDS"),
+							MetricName: aws.String(metricName),
+							Dimensions: []aws_cloudwath_types.Dimension{
+								{
+									Name:  aws.String("DBClusterIdentifier"),
+									Value: aws.String(clusterIdentifier),
+								},
+							},
+						},
+						Stat:   aws.String("Average"),
+						Period: aws.Int32(CloudwatchUsagePeriod * Minute),
+					},
+				},
+			}
+		}
+	}
+
+	return queries
+}
+
+// GetRDSClusterMetrics returns Aurora cluster volume metrics
+func (c *RdsFetcher) GetRDSClusterMetrics(clusterIdentifiers []string) (CloudWatchClusterMetrics, error) {
+	metrics := make(map[string]*ClusterMetrics)
+
+	queries := generateCloudWatchQueriesForClusters(clusterIdentifiers)
+
+	ids := make([]string, 0, len(queries))
+	for id := range queries {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	endTime := aws.Time(time.Now())
+	startTime := aws.Time(endTime.Add(-clusterMetricsLookback))
+
+	for len(ids) > 0 {
+		chunk := ids[:min(len(ids), MaxQueriesPerCloudwatchRequest)]
+		ids = ids[len(chunk):]
+
+		input := &aws_cloudwatch.GetMetricDataInput{
+			StartTime: startTime,
+			EndTime:   endTime,
+			ScanBy:    "TimestampDescending",
+		}
+
+		for _, id := range chunk {
+			input.MetricDataQueries = append(input.MetricDataQueries, queries[id].Query)
+		}
+
+		resp, err := c.client.GetMetricData(context.TODO(), input)
+		c.statistics.CloudWatchAPICall++
+
+		if err != nil {
+			return CloudWatchClusterMetrics{}, fmt.Errorf("error calling GetMetricData: %w", err)
+		}
+
+		for _, m := range resp.MetricDataResults {
+			request, found := queries[aws.ToString(m.Id)]
+			if !found || len(m.Values) == 0 {
+				continue
+			}
+
+			if _, exists := metrics[request.Dbidentifier]; !exists {
+				metrics[request.Dbidentifier] = &ClusterMetrics{}
+			}
+
+			// Datapoints are sorted by descending timestamp, so first value is the most recent one
+			err = metrics[request.Dbidentifier].Update(request.MetricName, m.Values[0])
+			if err != nil {
+				return CloudWatchClusterMetrics{}, fmt.Errorf("failed to process metrics %s: %w", request.MetricName, err)
+			}
+		}
+	}
+
+	return CloudWatchClusterMetrics{
+		Clusters: metrics,
+	}, nil
+}
@@ -0,0 +1,56 @@
+package cloudwatch_test
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/cloudwatch"
+	cloudwatch_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/cloudwatch/mock"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/infra/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_cloudwatch_types "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRDSClusterMetrics(t *testing.T) {
+	client := cloudwatch_mock.CloudwatchClient{Metrics: []aws_cloudwatch_types.MetricDataResult{
+		{
+			Id:     aws.String("volumebytesused_0"),
+			Label:  aws.String("VolumeBytesUsed"),
+			Values: []float64{1073741824, 1024},
+		},
+		{
+			Id:     aws.String("volumereadiops_0"),
+			Label:  aws.String("VolumeReadIOPs"),
+			Values: []float64{300},
+		},
+		{
+			Id:     aws.String("volumewriteiops_0"),
+			Label:  aws.String("VolumeWriteIOPs"),
+			Values: []float64{},
+		},
+	}}
+
+	logger, err := logger.New(true, "text")
+	require.NoError(t, err, "Logger must be created")
+
+	fetcher := cloudwatch.NewRDSFetcher(client, *logger, cloudwatch.Configuration{})
+	result, err := fetcher.GetRDSClusterMetrics([]string{"cluster1"})
+
+	require.NoError(t, err, "GetRDSClusterMetrics must succeed")
+	assert.Equal(t, aws.Float64(1073741824), result.Clusters["cluster1"].VolumeBytesUsed, "Most recent datapoint must be used")
+	assert.Equal(t, aws.Float64(300), result.Clusters["cluster1"].VolumeReadIOPs, "VolumeReadIOPs mismatch")
+	assert.Nil(t, result.Clusters["cluster1"].VolumeWriteIOPs, "Metric without datapoint must be nil")
+	assert.Equal(t, float64(1), fetcher.GetStatistics().CloudWatchAPICall, "One API call expected")
+}
+
+func TestGetRDSClusterMetricsWithoutClusters(t *testing.T) {
+	fetcher := cloudwatch.NewRDSFetcher(cloudwatch_mock.CloudwatchClient{}, slog.Logger{}, cloudwatch.Configuration{})
+	result, err := fetcher.GetRDSClusterMetrics(nil)
+
+	require.NoError(t, err, "GetRDSClusterMetrics must succeed")
+	assert.Empty(t, result.Clusters, "No cluster metrics expected")
+	assert.Equal(t, float64(0), fetcher.GetStatistics().CloudWatchAPICall, "Cloudwatch API must not be called without clusters")
+}
@@ -10,6 +10,7 @@ import (
 
 type CloudwatchClient struct {
 	Metrics []aws_cloudwatch_types.MetricDataResult
+	Series  []aws_cloudwatch_types.Metric
 }
 
 // GetMetricData returns custom metrics
@@ -19,3 +20,8 @@ func (m CloudwatchClient) GetMetricData(ctx context.Context, input *aws_cloudwat
 
 	return response, nil
 }
+
+// ListMetrics returns custom metric series
+func (m CloudwatchClient) ListMetrics(ctx context.Context, input *aws_cloudwatch.ListMetricsInput, fn ...func(*aws_cloudwatch.Options)) (*aws_cloudwatch.ListMetricsOutput, error) {
+	return &aws_cloudwatch.ListMetricsOutput{Metrics: m.Series}, nil
+}
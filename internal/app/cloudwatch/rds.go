@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"regexp"
 	"strings"
 	"time"
 
@@ -18,39 +19,88 @@ const (
 	MaxQueriesPerCloudwatchRequest int   = 500
 	CloudwatchUsagePeriod          int32 = 5
 	Minute                         int32 = 60
+	DefaultLookback                      = 3 * time.Minute
 )
 
-var errUnknownMetric = errors.New("unknown metric")
+var (
+	errUnknownMetric         = errors.New("unknown metric")
+	invalidQueryIDCharacters = regexp.MustCompile(`[^a-z0-9_]+`)
+)
+
+type Configuration struct {
+	Lookback     time.Duration // Time window used to fetch datapoints
+	MaxStaleness time.Duration // Datapoints older than this value are dropped (0: disabled)
+}
+
+// Instance describes an RDS instance whose Cloudwatch metrics are collected
+type Instance struct {
+	DBIdentifier     string
+	Serverless       bool // Aurora Serverless v2 instances have additional capacity metrics
+	BurstableCPU     bool // Burstable performance instances have CPU credits
+	BurstableStorage bool // gp2 volumes have I/O credits
+}
 
 type CloudWatchMetrics struct {
 	Instances map[string]*RdsMetrics
 }
 
 type RdsMetrics struct {
-	CPUUtilization            *float64
-	DBLoad                    *float64
-	DBLoadCPU                 *float64
-	DBLoadNonCPU              *float64
-	DatabaseConnections       *float64
-	FreeStorageSpace          *float64
-	FreeableMemory            *float64
-	MaximumUsedTransactionIDs *float64
-	ReadIOPS                  *float64
-	ReadThroughput            *float64
-	ReplicaLag                *float64
-	ReplicationSlotDiskUsage  *float64
-	SwapUsage                 *float64
-	TransactionLogsDiskUsage  *float64
-	WriteIOPS                 *float64
-	WriteThroughput           *float64
-	BufferCacheHitRatio       *float64
-	Deadlocks                 *float64
-	Queries                   *float64
-	EngineUptime              *float64
-	SumBinaryLogSize          *float64
-	NumBinaryLogFiles         *float64
-	AuroraBinlogReplicaLag    *float64
-	BinLogDiskUsage           *float64
+	CPUUtilization             *float64
+	DBLoad                     *float64
+	DBLoadCPU                  *float64
+	DBLoadNonCPU               *float64
+	DatabaseConnections        *float64
+	FreeStorageSpace           *float64
+	FreeableMemory             *float64
+	MaximumUsedTransactionIDs  *float64
+	ReadIOPS                   *float64
+	ReadThroughput             *float64
+	ReplicaLag                 *float64
+	ReplicationSlotDiskUsage   *float64
+	SwapUsage                  *float64
+	TransactionLogsDiskUsage   *float64
+	WriteIOPS                  *float64
+	WriteThroughput            *float64
+	BufferCacheHitRatio        *float64
+	Deadlocks                  *float64
+	Queries                    *float64
+	EngineUptime               *float64
+	SumBinaryLogSize           *float64
+	NumBinaryLogFiles          *float64
+	AuroraBinlogReplicaLag     *float64
+	BinLogDiskUsage            *float64
+	ServerlessDatabaseCapacity *float64
+	ACUUtilization             *float64
+	EBSIOBalance               *float64
+	EBSByteBalance             *float64
+	BurstBalance               *float64
+	CPUCreditBalance           *float64
+	Timestamps                 map[string]time.Time // Datapoint timestamp of each metric
+	LatestTimestamp            *time.Time           // Most recent datapoint timestamp, including stale datapoints
+}
+
+// UpdateWithTimestamp updates the metric value and keeps track of its datapoint timestamp
+func (m *RdsMetrics) UpdateWithTimestamp(field string, value float64, timestamp time.Time) error {
+	err := m.Update(field, value)
+	if err != nil {
+		return err
+	}
+
+	if m.Timestamps == nil {
+		m.Timestamps = make(map[string]time.Time)
+	}
+
+	m.Timestamps[field] = timestamp
+	m.observeTimestamp(timestamp)
+
+	return nil
+}
+
+// observeTimestamp records the most recent datapoint timestamp received for the instance
+func (m *RdsMetrics) observeTimestamp(timestamp time.Time) {
+	if m.LatestTimestamp == nil || timestamp.After(*m.LatestTimestamp) {
+		m.LatestTimestamp = &timestamp
+	}
 }
 
 func (m *RdsMetrics) Update(field string, value float64) error {
@@ -103,6 +153,18 @@ func (m *RdsMetrics) Update(field string, value float64) error {
 		m.AuroraBinlogReplicaLag = &value
 	case "BinLogDiskUsage":
 		m.BinLogDiskUsage = &value
+	case "ServerlessDatabaseCapacity":
+		m.ServerlessDatabaseCapacity = &value
+	case "ACUUtilization":
+		m.ACUUtilization = &value
+	case "EBSIOBalance%":
+		m.EBSIOBalance = &value
+	case "EBSByteBalance%":
+		m.EBSByteBalance = &value
+	case "BurstBalance":
+		m.BurstBalance = &value
+	case "CPUCreditBalance":
+		m.CPUCreditBalance = &value
 	default:
 		return fmt.Errorf("can't process '%s' metrics: %w", field, errUnknownMetric)
 	}
@@ -110,6 +172,80 @@ func (m *RdsMetrics) Update(field string, value float64) error {
 	return nil
 }
 
+// Get returns the metric value if it has been received
+func (m *RdsMetrics) Get(field string) (float64, bool) {
+	var value *float64
+
+	switch field {
+	case "DBLoad":
+		value = m.DBLoad
+	case "DBLoadCPU":
+		value = m.DBLoadCPU
+	case "DBLoadNonCPU":
+		value = m.DBLoadNonCPU
+	case "CPUUtilization":
+		value = m.CPUUtilization
+	case "DatabaseConnections":
+		value = m.DatabaseConnections
+	case "FreeStorageSpace":
+		value = m.FreeStorageSpace
+	case "FreeableMemory":
+		value = m.FreeableMemory
+	case "SwapUsage":
+		value = m.SwapUsage
+	case "WriteIOPS":
+		value = m.WriteIOPS
+	case "ReadIOPS":
+		value = m.ReadIOPS
+	case "ReplicaLag":
+		value = m.ReplicaLag
+	case "ReplicationSlotDiskUsage":
+		value = m.ReplicationSlotDiskUsage
+	case "MaximumUsedTransactionIDs":
+		value = m.MaximumUsedTransactionIDs
+	case "ReadThroughput":
+		value = m.ReadThroughput
+	case "WriteThroughput":
+		value = m.WriteThroughput
+	case "TransactionLogsDiskUsage":
+		value = m.TransactionLogsDiskUsage
+	case "BufferCacheHitRatio":
+		value = m.BufferCacheHitRatio
+	case "Deadlocks":
+		value = m.Deadlocks
+	case "Queries":
+		value = m.Queries
+	case "EngineUptime":
+		value = m.EngineUptime
+	case "SumBinaryLogSize":
+		value = m.SumBinaryLogSize
+	case "NumBinaryLogFiles":
+		value = m.NumBinaryLogFiles
+	case "AuroraBinlogReplicaLag":
+		value = m.AuroraBinlogReplicaLag
+	case "BinLogDiskUsage":
+		value = m.BinLogDiskUsage
+	case "ServerlessDatabaseCapacity":
+		value = m.ServerlessDatabaseCapacity
+	case "ACUUtilization":
+		value = m.ACUUtilization
+	case "EBSIOBalance%":
+		value = m.EBSIOBalance
+	case "EBSByteBalance%":
+		value = m.EBSByteBalance
+	case "BurstBalance":
+		value = m.BurstBalance
+	case "CPUCreditBalance":
+		value = m.CPUCreditBalance
+	}
+
+	if value == nil {
+		return 0, false
+	}
+
+	return *value, true
+}
+
 // getCloudWatchMetricsName returns names of Cloudwatch metrics to collect
 func getCloudWatchMetricsName() [24]string {
 	return [24]string{
@@ -140,6 +276,30 @@ func getCloudWatchMetricsName() [24]string {
 	}
 }
 
+// getServerlessCloudWatchMetricsName returns names of Cloudwatch metrics only available for Aurora Serverless v2 instances
+func getServerlessCloudWatchMetricsName() [2]string {
+	return [2]string{
+		"ServerlessDatabaseCapacity",
+		"ACUUtilization",
+	}
+}
+
+// getProvisionedCloudWatchMetricsName returns names of Cloudwatch metrics only available for provisioned instances
+// AWS publishes EBS balances only for instance classes having burst EBS capacity
+func getProvisionedCloudWatchMetricsName() [2]string {
+	return [2]string{
+		"EBSIOBalance%",
+		"EBSByteBalance%",
+	}
+}
+
+// getQueryID returns a Cloudwatch query ID, which must start with a lowercase letter and contain only letters, numbers and underscores
+func getQueryID(metricName string, index int) string {
+	name := invalidQueryIDCharacters.ReplaceAllString(strings.ToLower(metricName), "")
+
+	return fmt.Sprintf("%s_%d", name, index)
+}
+
 // generateCloudWatchQueryForInstance return the cloudwatch query for a specific instance's metric
 func generateCloudWatchQueryForInstance(queryID *string, metricName string, dbIdentifier string) CloudWatchMetricRequest {
 	query := &aws_cloudwath_types.MetricDataQuery{
@@ -168,16 +328,33 @@ func generateCloudWatchQueryForInstance(queryID *string, metricName string, dbId
 }
 
 // generateCloudWatchQueriesForInstances returns all cloudwatch queries for specified instances
-func generateCloudWatchQueriesForInstances(dbIdentifiers []string) map[string]CloudWatchMetricRequest {
+func generateCloudWatchQueriesForInstances(instances []Instance) map[string]CloudWatchMetricRequest {
 	queries := make(map[string]CloudWatchMetricRequest)
 
 	metrics := getCloudWatchMetricsName()
+	serverlessMetrics := getServerlessCloudWatchMetricsName()
+	provisionedMetrics := getProvisionedCloudWatchMetricsName()
+
+	for i, instance := range instances {
+		metricNames := metrics[:]
+		if instance.Serverless {
+			metricNames = append(metricNames, serverlessMetrics[:]...)
+		} else {
+			metricNames = append(metricNames, provisionedMetrics[:]...)
+		}
+
+		if instance.BurstableCPU {
+			metricNames = append(metricNames, "CPUCreditBalance")
+		}
 
-	for i, dbIdentifier := range dbIdentifiers {
-		for _, metricName := range metrics {
-			queryID := aws.String(fmt.Sprintf("%s_%d", strings.ToLower(metricName), i))
+		if instance.BurstableStorage {
+			metricNames = append(metricNames, "BurstBalance")
+		}
+
+		for _, metricName := range metricNames {
+			queryID := aws.String(getQueryID(metricName, i))
 
-			query := generateCloudWatchQueryForInstance(queryID, metricName, dbIdentifier)
+			query := generateCloudWatchQueryForInstance(queryID, metricName, instance.DBIdentifier)
 
 			queries[*queryID] = query
 		}
@@ -186,17 +363,23 @@ func generateCloudWatchQueriesForInstances(dbIdentifiers []string) map[string]Cl
 	return queries
 }
 
-func NewRDSFetcher(client CloudWatchClient, logger slog.Logger) *RdsFetcher {
+func NewRDSFetcher(client CloudWatchClient, logger slog.Logger, configuration Configuration) *RdsFetcher {
+	if configuration.Lookback <= 0 {
+		configuration.Lookback = DefaultLookback
+	}
+
 	return &RdsFetcher{
-		client: client,
-		logger: &logger,
+		client:        client,
+		logger:        &logger,
+		configuration: configuration,
 	}
 }
 
 type RdsFetcher struct {
-	client     CloudWatchClient
-	statistics Statistics
-	logger     *slog.Logger
+	client        CloudWatchClient
+	statistics    Statistics
+	logger        *slog.Logger
+	configuration Configuration
 }
 
 func (c *RdsFetcher) GetStatistics() *Statistics {
@@ -235,23 +418,52 @@ func (c *RdsFetcher) updateMetricsWithCloudWatchQueriesResult(metrics map[string
 			metrics[val.Dbidentifier] = &RdsMetrics{}
 		}
 
-		if len(m.Values) > 0 {
+		if len(m.Values) == 0 {
+			continue
+		}
+
+		// Datapoints are sorted by descending timestamp, so first value is the most recent one
+		if len(m.Timestamps) == 0 {
 			err := metrics[val.Dbidentifier].Update(val.MetricName, m.Values[0])
 			if err != nil {
 				return fmt.Errorf("failed to process metrics %s: %w", val.MetricName, err)
 			}
+
+			continue
+		}
+
+		timestamp := m.Timestamps[0]
+		if c.isStale(timestamp, *endTime) {
+			c.logger.Debug("drop stale cloudwatch value", "metric", val.MetricName, "dbidentifier", val.Dbidentifier, "timestamp", timestamp)
+			metrics[val.Dbidentifier].observeTimestamp(timestamp)
+
+			continue
+		}
+
+		err := metrics[val.Dbidentifier].UpdateWithTimestamp(val.MetricName, m.Values[0], timestamp)
+		if err != nil {
+			return fmt.Errorf("failed to process metrics %s: %w", val.MetricName, err)
 		}
 	}
 
 	return nil
 }
 
-func (c *RdsFetcher) GetRDSInstanceMetrics(dbIdentifiers []string) (CloudWatchMetrics, error) {
+// isStale returns true if the datapoint is older than the configured maximum staleness
+func (c *RdsFetcher) isStale(timestamp time.Time, now time.Time) bool {
+	if c.configuration.MaxStaleness <= 0 {
+		return false
+	}
+
+	return now.Sub(timestamp) > c.configuration.MaxStaleness
+}
+
+func (c *RdsFetcher) GetRDSInstanceMetrics(instances []Instance) (CloudWatchMetrics, error) {
 	metrics := make(map[string]*RdsMetrics)
 
-	cloudWatchQueries := generateCloudWatchQueriesForInstances(dbIdentifiers)
-	startTime := aws.Time(time.Now().Add(-3 * time.Minute)) // Start time - 1 hour ago
-	endTime := aws.Time(time.Now())                         // End time - now
+	cloudWatchQueries := generateCloudWatchQueriesForInstances(instances)
+	endTime := aws.Time(time.Now())
+	startTime := aws.Time(endTime.Add(-c.configuration.Lookback))
 	chunkSize := MaxQueriesPerCloudwatchRequest
 
 	cloudWatchAPICalls := float64(0)
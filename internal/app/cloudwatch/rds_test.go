@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/TeiNam/prometheus-rds-exporter/internal/app/cloudwatch"
 	cloudwatch_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/cloudwatch/mock"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/infra/logger"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	aws_cloudwatch_types "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 
@@ -141,7 +143,7 @@ func generateMockedMetricsForInstance(id int, m cloudwatch.RdsMetrics) []aws_clo
 }
 
 func TestGetDBInstanceTypeInformation(t *testing.T) {
-	instancesName := []string{}
+	instancesName := []cloudwatch.Instance{}
 	data := []aws_cloudwatch_types.MetricDataResult{}
 
 	// Generate instances metrics
@@ -153,7 +155,7 @@ func TestGetDBInstanceTypeInformation(t *testing.T) {
 	i := 0
 
 	for id := range instances {
-		instancesName = append(instancesName, id)
+		instancesName = append(instancesName, cloudwatch.Instance{DBIdentifier: id})
 		instancesMetrics := generateMockedMetricsForInstance(i, instances[id])
 
 		data = append(data, instancesMetrics...)
@@ -161,7 +163,7 @@ func TestGetDBInstanceTypeInformation(t *testing.T) {
 	}
 
 	client := cloudwatch_mock.CloudwatchClient{Metrics: data}
-	fetcher := cloudwatch.NewRDSFetcher(client, slog.Logger{})
+	fetcher := cloudwatch.NewRDSFetcher(client, slog.Logger{}, cloudwatch.Configuration{})
 	result, err := fetcher.GetRDSInstanceMetrics(instancesName)
 
 	require.NoError(t, err, "GetRDSInstanceMetrics must succeed")
@@ -187,3 +189,92 @@ func TestGetDBInstanceTypeInformation(t *testing.T) {
 		assert.Equal(t, value.WriteThroughput, result.Instances[id].WriteThroughput, "WriteThroughput mismatch")
 	}
 }
+
+func TestStaleMetrics(t *testing.T) {
+	now := time.Now()
+	staleTimestamp := now.Add(-10 * time.Minute)
+
+	client := cloudwatch_mock.CloudwatchClient{Metrics: []aws_cloudwatch_types.MetricDataResult{
+		{
+			Id:         aws.String("cpuutilization_0"),
+			Label:      aws.String("CPUUtilization"),
+			Values:     []float64{10},
+			Timestamps: []time.Time{now},
+		},
+		{
+			Id:         aws.String("databaseconnections_0"),
+			Label:      aws.String("DatabaseConnections"),
+			Values:     []float64{42},
+			Timestamps: []time.Time{staleTimestamp},
+		},
+	}}
+
+	logger, _ := logger.New(true, "text")
+	configuration := cloudwatch.Configuration{Lookback: 15 * time.Minute, MaxStaleness: 5 * time.Minute}
+	fetcher := cloudwatch.NewRDSFetcher(client, *logger, configuration)
+	result, err := fetcher.GetRDSInstanceMetrics([]cloudwatch.Instance{{DBIdentifier: "db1"}})
+
+	require.NoError(t, err, "GetRDSInstanceMetrics must succeed")
+
+	instance := result.Instances["db1"]
+	assert.Equal(t, aws.Float64(10), instance.CPUUtilization, "Fresh datapoint must be kept")
+	assert.Equal(t, now, instance.Timestamps["CPUUtilization"], "Datapoint timestamp must be kept")
+	assert.Nil(t, instance.DatabaseConnections, "Stale datapoint must be dropped")
+	assert.Equal(t, now, *instance.LatestTimestamp, "Latest timestamp mismatch")
+}
+
+func TestServerlessMetrics(t *testing.T) {
+	client := cloudwatch_mock.CloudwatchClient{Metrics: []aws_cloudwatch_types.MetricDataResult{
+		{
+			Id:     aws.String("serverlessdatabasecapacity_0"),
+			Label:  aws.String("ServerlessDatabaseCapacity"),
+			Values: []float64{4.5},
+		},
+		{
+			Id:     aws.String("acuutilization_0"),
+			Label:  aws.String("ACUUtilization"),
+			Values: []float64{28.125},
+		},
+	}}
+
+	fetcher := cloudwatch.NewRDSFetcher(client, slog.Logger{}, cloudwatch.Configuration{})
+	result, err := fetcher.GetRDSInstanceMetrics([]cloudwatch.Instance{{DBIdentifier: "db1", Serverless: true}})
+
+	require.NoError(t, err, "GetRDSInstanceMetrics must succeed")
+	assert.Equal(t, aws.Float64(4.5), result.Instances["db1"].ServerlessDatabaseCapacity, "ServerlessDatabaseCapacity mismatch")
+	assert.Equal(t, aws.Float64(28.125), result.Instances["db1"].ACUUtilization, "ACUUtilization mismatch")
+}
+
+func TestBurstBalanceMetrics(t *testing.T) {
+	client := cloudwatch_mock.CloudwatchClient{Metrics: []aws_cloudwatch_types.MetricDataResult{
+		{
+			Id:     aws.String("ebsiobalance_0"),
+			Label:  aws.String("EBSIOBalance%"),
+			Values: []float64{99},
+		},
+		{
+			Id:     aws.String("ebsbytebalance_0"),
+			Label:  aws.String("EBSByteBalance%"),
+			Values: []float64{42},
+		},
+		{
+			Id:     aws.String("burstbalance_0"),
+			Label:  aws.String("BurstBalance"),
+			Values: []float64{100},
+		},
+		{
+			Id:     aws.String("cpucreditbalance_0"),
+			Label:  aws.String("CPUCreditBalance"),
+			Values: []float64{576},
+		},
+	}}
+
+	fetcher := cloudwatch.NewRDSFetcher(client, slog.Logger{}, cloudwatch.Configuration{})
+	result, err := fetcher.GetRDSInstanceMetrics([]cloudwatch.Instance{{DBIdentifier: "db1", BurstableCPU: true, BurstableStorage: true}})
+
+	require.NoError(t, err, "GetRDSInstanceMetrics must succeed")
+	assert.Equal(t, aws.Float64(99), result.Instances["db1"].EBSIOBalance, "EBSIOBalance% mismatch")
+	assert.Equal(t, aws.Float64(42), result.Instances["db1"].EBSByteBalance, "EBSByteBalance% mismatch")
+	assert.Equal(t, aws.Float64(100), result.Instances["db1"].BurstBalance, "BurstBalance mismatch")
+	assert.Equal(t, aws.Float64(576), result.Instances["db1"].CPUCreditBalance, "CPUCreditBalance mismatch")
+}
@@ -3,6 +3,7 @@ package cloudwatch
 import (
 	"context"
 
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/iam"
 	aws_cloudwatch "github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	aws_cloudwatch_types "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 )
@@ -19,4 +20,19 @@ type CloudWatchMetricRequest struct {
 
 type CloudWatchClient interface {
 	GetMetricData(context.Context, *aws_cloudwatch.GetMetricDataInput, ...func(*aws_cloudwatch.Options)) (*aws_cloudwatch.GetMetricDataOutput, error)
+	ListMetrics(context.Context, *aws_cloudwatch.ListMetricsInput, ...func(*aws_cloudwatch.Options)) (*aws_cloudwatch.ListMetricsOutput, error)
+}
+
+// InstancePermissions returns AWS IAM statements required by the instance and Aurora cluster metrics fetcher
+func InstancePermissions() []iam.Statement {
+	return []iam.Statement{
+		iam.Allow("AllowGettingCloudWatchMetrics", []string{"cloudwatch:GetMetricData"}, iam.AllResources),
+	}
+}
+
+// UsagePermissions returns AWS IAM statements required by the usage fetcher, which discovers usage metrics before getting them
+func UsagePermissions() []iam.Statement {
+	return []iam.Statement{
+		iam.Allow("AllowGettingCloudWatchMetrics", []string{"cloudwatch:GetMetricData", "cloudwatch:ListMetrics"}, iam.AllResources),
+	}
 }
@@ -2,31 +2,63 @@ package cloudwatch
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"strings"
+	"sort"
 	"time"
 
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/servicequotas"
 	converter "github.com/TeiNam/prometheus-rds-exporter/internal/app/unit"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	aws_cloudwatch "github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	aws_cloudwatch_types "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"golang.org/x/exp/maps"
 )
 
+const usageService = "RDS"
+
+// defaultUsageResources returns resources queried when no AWS/Usage series can be discovered
+func defaultUsageResources() []string {
+	resources := maps.Values(servicequotas.DefaultUsageResources)
+	sort.Strings(resources)
+
+	return resources
+}
+
+// ResourceUsage is the usage of an AWS RDS resource published in AWS/Usage namespace
+type ResourceUsage struct {
+	Resource string
+	Class    string
+	Value    float64
+}
+
 type UsageMetrics struct {
 	AllocatedStorage    float64
 	DBInstances         float64
 	ManualSnapshots     float64
 	ReservedDBInstances float64
+	Resources           []ResourceUsage // All discovered resource usages, values are reported as is by AWS
+}
+
+// Get returns the usage of a resource
+func (u UsageMetrics) Get(resource string, class string) (float64, bool) {
+	for _, usage := range u.Resources {
+		if usage.Resource == resource && usage.Class == class {
+			return usage.Value, true
+		}
+	}
+
+	return 0, false
 }
 
 func (u *UsageMetrics) Update(field string, value float64) error {
 	switch field {
-	case "AllocatedStorage":
+	case servicequotas.AllocatedStorageUsageResource:
 		u.AllocatedStorage = converter.GigaBytesToBytes(value)
-	case "DBInstances":
+	case servicequotas.DBInstancesUsageResource:
 		u.DBInstances = value
-	case "ManualSnapshots":
+	case servicequotas.ManualSnapshotsUsageResource:
 		u.ManualSnapshots = value
 	case "ReservedDBInstances":
 		u.ReservedDBInstances = value
@@ -37,79 +69,81 @@ func (u *UsageMetrics) Update(field string, value float64) error {
 	return nil
 }
 
-func generateCloudWatchDataQueriesForUsage(service string, metricsName []string) map[string]CloudWatchMetricRequest {
-	requests := make(map[string]CloudWatchMetricRequest)
-
-	for i, metricName := range metricsName {
-		id := aws.String(fmt.Sprintf("%s_%d", strings.ToLower(metricName), i))
-		query := &aws_cloudwatch_types.MetricDataQuery{
-			Id: id,
-			MetricStat: &aws_cloudwatch_types.MetricStat{
-				Metric: &aws_cloudwatch_types.Metric{
-					Namespace:  aws.String("AWS/Usage"),
-					MetricName: aws.String("ResourceCount"),
-					Dimensions: []aws_cloudwatch_types.Dimension{
-						{
-							Name:  aws.String("Service"),
-							Value: aws.String(service),
-						},
-						{
-							Name:  aws.String("Type"),
-							Value: aws.String("Resource"),
-						},
-						{
-							Name:  aws.String("Resource"),
-							Value: aws.String(metricName),
-						},
-						{
-							Name:  aws.String("Class"),
-							Value: aws.String("None"),
-						},
-					},
-				},
-				Stat:   aws.String("Average"), // Specify the statistic to retrieve
-				Period: aws.Int32(Minute),     // Specify the period of the metric. Granularity - 1 minute
+// usageQuery is a GetMetricData query of an AWS/Usage resource count series
+type usageQuery struct {
+	resource string
+	class    string
+	query    aws_cloudwatch_types.MetricDataQuery
+}
+
+// newUsageMetric returns the AWS/Usage resource count metric of a RDS resource
+func newUsageMetric(service string, resource string) aws_cloudwatch_types.Metric {
+	return aws_cloudwatch_types.Metric{
+		Namespace:  aws.String(servicequotas.UsageNamespace),
+		MetricName: aws.String(servicequotas.UsageMetricName),
+		Dimensions: []aws_cloudwatch_types.Dimension{
+			{
+				Name:  aws.String("Service"),
+				Value: aws.String(service),
 			},
-		}
+			{
+				Name:  aws.String("Type"),
+				Value: aws.String("Resource"),
+			},
+			{
+				Name:  aws.String("Resource"),
+				Value: aws.String(resource),
+			},
+			{
+				Name:  aws.String("Class"),
+				Value: aws.String(servicequotas.UsageNoClass),
+			},
+		},
+	}
+}
 
-		requests[*id] = CloudWatchMetricRequest{
-			Dbidentifier: "",
-			MetricName:   metricName,
-			Query:        *query,
+// getDimension returns the value of a metric dimension
+func getDimension(metric aws_cloudwatch_types.Metric, name string) string {
+	for _, dimension := range metric.Dimensions {
+		if aws.ToString(dimension.Name) == name {
+			return aws.ToString(dimension.Value)
 		}
 	}
 
-	return requests
+	return ""
 }
 
-func generateCloudWatchQueriesForUsage() *aws_cloudwatch.GetMetricDataInput {
-	metricsName := []string{
-		"AllocatedStorage",
-		"DBInstances",
-		"ManualSnapshots",
-	}
+func generateCloudWatchDataQueriesForUsage(metrics []aws_cloudwatch_types.Metric) map[string]usageQuery {
+	queries := make(map[string]usageQuery)
+
+	for i := range metrics {
+		resource := getDimension(metrics[i], "Resource")
+		if resource == "" {
+			continue
+		}
 
-	cloudwatchDataQueries := []aws_cloudwatch_types.MetricDataQuery{}
-	queries := generateCloudWatchDataQueriesForUsage("RDS", metricsName)
+		class := getDimension(metrics[i], "Class")
+		if class == "" {
+			class = servicequotas.UsageNoClass
+		}
 
-	for _, usageQuery := range queries {
-		query := aws_cloudwatch_types.MetricDataQuery{
-			Id: usageQuery.Query.Id,
-			MetricStat: &aws_cloudwatch_types.MetricStat{
-				Metric: usageQuery.Query.MetricStat.Metric,
-				Stat:   aws.String("Average"),
-				Period: aws.Int32(CloudwatchUsagePeriod * Minute),
+		id := fmt.Sprintf("usage_%d", i)
+		queries[id] = usageQuery{
+			resource: resource,
+			class:    class,
+			query: aws_cloudwatch_types.MetricDataQuery{
+				Id:    aws.String(id),
+				Label: aws.String(resource),
+				MetricStat: &aws_cloudwatch_types.MetricStat{
+					Metric: &metrics[i],
+					Stat:   aws.String("Average"),
+					Period: aws.Int32(CloudwatchUsagePeriod * Minute),
+				},
 			},
 		}
-		cloudwatchDataQueries = append(cloudwatchDataQueries, query)
 	}
 
-	return &aws_cloudwatch.GetMetricDataInput{
-		StartTime:         aws.Time(time.Now().Add(-5 * time.Hour)),
-		EndTime:           aws.Time(time.Now()),
-		ScanBy:            "TimestampDescending",
-		MetricDataQueries: cloudwatchDataQueries,
-	}
+	return queries
 }
 
 func NewUsageFetcher(client CloudWatchClient, logger slog.Logger) *usageFetcher {
@@ -129,33 +163,129 @@ func (u *usageFetcher) GetStatistics() Statistics {
 	return u.statistics
 }
 
-// GetUsageMetrics returns RDS service usages metrics
-func (u *usageFetcher) GetUsageMetrics() (UsageMetrics, error) {
-	metrics := UsageMetrics{}
+// discoverUsageMetrics lists AWS/Usage resource count series of the RDS service
+// Known resources are returned if no series is published yet (eg. new AWS account)
+func (u *usageFetcher) discoverUsageMetrics() ([]aws_cloudwatch_types.Metric, error) {
+	var metrics []aws_cloudwatch_types.Metric
+
+	input := &aws_cloudwatch.ListMetricsInput{
+		Namespace:  aws.String(servicequotas.UsageNamespace),
+		MetricName: aws.String(servicequotas.UsageMetricName),
+		Dimensions: []aws_cloudwatch_types.DimensionFilter{
+			{Name: aws.String("Service"), Value: aws.String(usageService)},
+			{Name: aws.String("Type"), Value: aws.String("Resource")},
+		},
+	}
 
-	query := generateCloudWatchQueriesForUsage()
+	paginator := aws_cloudwatch.NewListMetricsPaginator(u.client, input)
+	for paginator.HasMorePages() {
+		u.statistics.CloudWatchAPICall++
+
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("can't list usage metrics: %w", err)
+		}
 
-	resp, err := u.client.GetMetricData(context.TODO(), query)
+		metrics = append(metrics, output.Metrics...)
+	}
+
+	if len(metrics) == 0 {
+		for _, resource := range defaultUsageResources() {
+			metrics = append(metrics, newUsageMetric(usageService, resource))
+		}
+	}
+
+	return metrics, nil
+}
+
+// updateUsages fetches a batch of usage queries and adds their latest values to metrics
+func (u *usageFetcher) updateUsages(metrics *UsageMetrics, queries map[string]usageQuery, ids []string, startTime *time.Time, endTime *time.Time) error {
+	input := &aws_cloudwatch.GetMetricDataInput{
+		StartTime: startTime,
+		EndTime:   endTime,
+		ScanBy:    "TimestampDescending",
+	}
+
+	for _, id := range ids {
+		input.MetricDataQueries = append(input.MetricDataQueries, queries[id].query)
+	}
+
+	resp, err := u.client.GetMetricData(context.TODO(), input)
 	u.statistics.CloudWatchAPICall++
 
 	if err != nil {
-		return metrics, fmt.Errorf("error calling GetMetricData: %w", err)
+		return fmt.Errorf("error calling GetMetricData: %w", err)
 	}
 
 	for _, m := range resp.MetricDataResults {
-		if m.Values == nil {
-			u.logger.Warn("cloudwatch value is empty", "metric", *m.Label)
+		query, found := queries[aws.ToString(m.Id)]
+		if !found {
+			continue
+		}
 
+		if len(m.Values) == 0 {
+			u.logger.Warn("cloudwatch value is empty", "metric", query.resource, "class", query.class)
+
+			continue
+		}
+
+		metrics.Resources = append(metrics.Resources, ResourceUsage{
+			Resource: query.resource,
+			Class:    query.class,
+			Value:    m.Values[0],
+		})
+
+		if query.class != servicequotas.UsageNoClass {
 			continue
 		}
 
-		if len(m.Values) > 0 {
-			err = metrics.Update(*m.Label, m.Values[0])
-			if err != nil {
-				return metrics, fmt.Errorf("can't update internal values: %w", err)
-			}
+		err = metrics.Update(query.resource, m.Values[0])
+		if err != nil && !errors.Is(err, errUnknownMetric) {
+			return fmt.Errorf("can't update internal values: %w", err)
 		}
 	}
 
+	return nil
+}
+
+// GetUsageMetrics returns RDS service usages metrics
+func (u *usageFetcher) GetUsageMetrics() (UsageMetrics, error) {
+	metrics := UsageMetrics{}
+
+	usageMetrics, err := u.discoverUsageMetrics()
+	if err != nil {
+		return metrics, err
+	}
+
+	queries := generateCloudWatchDataQueriesForUsage(usageMetrics)
+
+	ids := make([]string, 0, len(queries))
+	for id := range queries {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+
+	endTime := aws.Time(time.Now())
+	startTime := aws.Time(endTime.Add(-5 * time.Hour))
+
+	for len(ids) > 0 {
+		chunk := ids[:min(len(ids), MaxQueriesPerCloudwatchRequest)]
+		ids = ids[len(chunk):]
+
+		err = u.updateUsages(&metrics, queries, chunk, startTime, endTime)
+		if err != nil {
+			return metrics, err
+		}
+	}
+
+	sort.Slice(metrics.Resources, func(i, j int) bool {
+		if metrics.Resources[i].Resource != metrics.Resources[j].Resource {
+			return metrics.Resources[i].Resource < metrics.Resources[j].Resource
+		}
+
+		return metrics.Resources[i].Class < metrics.Resources[j].Class
+	})
+
 	return metrics, nil
 }
@@ -1,6 +1,7 @@
 package cloudwatch_test
 
 import (
+	"fmt"
 	"log/slog"
 	"testing"
 
@@ -13,6 +14,19 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func newUsageSeries(resource string) aws_cloudwatch_types.Metric {
+	return aws_cloudwatch_types.Metric{
+		Namespace:  aws.String("AWS/Usage"),
+		MetricName: aws.String("ResourceCount"),
+		Dimensions: []aws_cloudwatch_types.Dimension{
+			{Name: aws.String("Service"), Value: aws.String("RDS")},
+			{Name: aws.String("Type"), Value: aws.String("Resource")},
+			{Name: aws.String("Resource"), Value: aws.String(resource)},
+			{Name: aws.String("Class"), Value: aws.String("None")},
+		},
+	}
+}
+
 func TestGetUsageMetrics(t *testing.T) {
 	expected := cloudwatch.UsageMetrics{
 		AllocatedStorage:    100,
@@ -20,28 +34,28 @@ func TestGetUsageMetrics(t *testing.T) {
 		ManualSnapshots:     10,
 		ReservedDBInstances: 3,
 	}
+	dbClusters := float64(7)
 
-	client := cloudwatch_mock.CloudwatchClient{
-		Metrics: []aws_cloudwatch_types.MetricDataResult{
-			{
-				Label:  aws.String("AllocatedStorage"),
-				Values: []float64{expected.AllocatedStorage},
-			},
-			{
-				Label:  aws.String("DBInstances"),
-				Values: []float64{expected.DBInstances},
-			},
-			{
-				Label:  aws.String("ManualSnapshots"),
-				Values: []float64{expected.ManualSnapshots},
-			},
-			{
-				Label:  aws.String("ReservedDBInstances"),
-				Values: []float64{expected.ReservedDBInstances},
-			},
-		},
+	// Series are queried in discovery order, usage_<index> is the query ID of each series
+	series := []aws_cloudwatch_types.Metric{
+		newUsageSeries("AllocatedStorage"),
+		newUsageSeries("DBInstances"),
+		newUsageSeries("ManualSnapshots"),
+		newUsageSeries("ReservedDBInstances"),
+		newUsageSeries("DBClusters"),
+	}
+	values := []float64{expected.AllocatedStorage, expected.DBInstances, expected.ManualSnapshots, expected.ReservedDBInstances, dbClusters}
+
+	results := make([]aws_cloudwatch_types.MetricDataResult, 0, len(values))
+	for i, value := range values {
+		results = append(results, aws_cloudwatch_types.MetricDataResult{
+			Id:     aws.String(fmt.Sprintf("usage_%d", i)),
+			Values: []float64{value},
+		})
 	}
 
+	client := cloudwatch_mock.CloudwatchClient{Series: series, Metrics: results}
+
 	fetcher := cloudwatch.NewUsageFetcher(client, slog.Logger{})
 	result, err := fetcher.GetUsageMetrics()
 
@@ -51,5 +65,31 @@ func TestGetUsageMetrics(t *testing.T) {
 	assert.Equal(t, expected.ManualSnapshots, result.ManualSnapshots, "Manual snapshots mismatch")
 	assert.Equal(t, expected.ReservedDBInstances, result.ReservedDBInstances, "Reserved DB instances mismatch")
 
-	assert.Equal(t, float64(1), fetcher.GetStatistics().CloudWatchAPICall, "One call to Cloudwatch API")
+	require.Len(t, result.Resources, len(series), "All discovered resources must be returned")
+	assert.Equal(t, cloudwatch.ResourceUsage{Resource: "AllocatedStorage", Class: "None", Value: expected.AllocatedStorage}, result.Resources[0], "Resource usages must be sorted and reported as is")
+
+	usage, found := result.Get("DBClusters", "None")
+	assert.True(t, found, "Resources without dedicated field must be returned")
+	assert.Equal(t, dbClusters, usage, "DB clusters count mismatch")
+
+	assert.Equal(t, float64(2), fetcher.GetStatistics().CloudWatchAPICall, "One call to list usage metrics and one call to get their values")
+}
+
+func TestGetUsageMetricsWithoutSeries(t *testing.T) {
+	// Without discovered series, known resources are queried
+	client := cloudwatch_mock.CloudwatchClient{
+		Metrics: []aws_cloudwatch_types.MetricDataResult{
+			{
+				Id:     aws.String("usage_1"),
+				Values: []float64{42},
+			},
+		},
+	}
+
+	fetcher := cloudwatch.NewUsageFetcher(client, slog.Logger{})
+	result, err := fetcher.GetUsageMetrics()
+
+	require.NoError(t, err, "GetUsageMetrics must succeed")
+	assert.Equal(t, float64(42), result.DBInstances, "DB instances count mismatch")
+	assert.Len(t, result.Resources, 1, "Only resources with datapoints must be returned")
 }
@@ -0,0 +1,143 @@
+// Package doctor checks AWS credentials, permissions and connectivity of collectors and reports actionable failures
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/awserror"
+	"golang.org/x/exp/slices"
+)
+
+// Category classifies the result of a check, AWS API errors are classified like collector fetch errors
+type Category = awserror.Category
+
+const (
+	CategoryOK                 = awserror.CategoryOK
+	CategoryAccessDenied       = awserror.CategoryAccessDenied
+	CategoryThrottling         = awserror.CategoryThrottling
+	CategoryUnreachable        = awserror.CategoryUnreachable
+	CategoryRegionDisabled     = awserror.CategoryRegionDisabled
+	CategoryInvalidCredentials = awserror.CategoryInvalidCredentials
+	CategoryError              = awserror.CategoryError
+)
+
+// CategorySkipped is the category of checks that can't be run
+const CategorySkipped Category = "skipped"
+
+// checkTimeout limits the duration of a check, AWS SDK retries unreachable endpoints
+const checkTimeout = 10 * time.Second
+
+// ErrSkipped is returned by checks that can't be run, for example without any instance to call the API on
+var ErrSkipped = errors.New("check skipped")
+
+// Check calls an AWS API action with minimal inputs
+type Check struct {
+	Action string // AWS IAM action (eg. rds:DescribeDBInstances)
+	Call   func(ctx context.Context) error
+
+	// AllowedErrors are AWS API error codes proving the caller is authorized, like errors on non-existent resources or dry runs
+	AllowedErrors []string
+}
+
+// Result is the result of a check
+type Result struct {
+	Action   string
+	Category Category
+	Err      error
+}
+
+// Failed returns true if the check failed
+func (r Result) Failed() bool {
+	return r.Category != CategoryOK && r.Category != CategorySkipped
+}
+
+// Advice returns what to do to fix the failure, identity is the ARN of the AWS identity calling the APIs
+func (r Result) Advice(identity string) string {
+	if identity == "" {
+		identity = "the exporter AWS identity"
+	}
+
+	switch r.Category {
+	case CategoryOK:
+		return ""
+	case CategorySkipped:
+		return r.Err.Error()
+	case CategoryAccessDenied:
+		return fmt.Sprintf("grant %s to %s, 'prometheus-rds-exporter iam-policy' generates the required policy", r.Action, identity)
+	case CategoryThrottling:
+		return "AWS API rate limit exceeded, retry later or reduce the scrape frequency and the number of exporters sharing the account"
+	case CategoryUnreachable:
+		return "can't reach the AWS API endpoint, check the region name, DNS resolution, HTTP proxy, VPC endpoints and security groups"
+	case CategoryRegionDisabled:
+		return "the region is not enabled in the AWS account, enable it in the AWS account settings or remove it from aws-regions"
+	case CategoryInvalidCredentials:
+		return "AWS credentials are rejected, they are invalid or expired"
+	case CategoryError:
+		return r.Err.Error()
+	}
+
+	return ""
+}
+
+// Classify returns the category of an error returned by a check
+func Classify(err error, allowedErrors ...string) Category {
+	if errors.Is(err, ErrSkipped) {
+		return CategorySkipped
+	}
+
+	return awserror.Classify(err, allowedErrors...)
+}
+
+// Run runs checks sequentially, so they don't trigger AWS API throttling
+func Run(ctx context.Context, checks []Check) []Result {
+	results := make([]Result, 0, len(checks))
+
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := check.Call(checkCtx)
+		cancel()
+
+		results = append(results, Result{
+			Action:   check.Action,
+			Category: Classify(err, check.AllowedErrors...),
+			Err:      err,
+		})
+	}
+
+	return results
+}
+
+// RegionReport contains results of the checks of a region
+type RegionReport struct {
+	Region    string
+	AccountID string
+	Identity  string // ARN of the AWS identity, empty if credentials can't be resolved
+	Results   []Result
+}
+
+// Failed returns true if a check of the region failed
+func (r RegionReport) Failed() bool {
+	return slices.ContainsFunc(r.Results, Result.Failed)
+}
+
+// Write writes a human readable report of the region
+func (r RegionReport) Write(w io.Writer) {
+	fmt.Fprintf(w, "Region %s\n", r.Region)
+
+	if r.Identity != "" {
+		fmt.Fprintf(w, "  Identity: %s (account %s)\n", r.Identity, r.AccountID)
+	}
+
+	for _, result := range r.Results {
+		fmt.Fprintf(w, "  [%s] %s\n", strings.ToUpper(string(result.Category)), result.Action)
+
+		if advice := result.Advice(r.Identity); advice != "" {
+			fmt.Fprintf(w, "      %s\n", advice)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package doctor_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/doctor"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		allowed  []string
+		expected doctor.Category
+	}{
+		{"success", nil, nil, doctor.CategoryOK},
+		{"access denied", &smithy.GenericAPIError{Code: "AccessDenied"}, nil, doctor.CategoryAccessDenied},
+		{"allowed error", &smithy.GenericAPIError{Code: "DryRunOperation"}, []string{"DryRunOperation"}, doctor.CategoryOK},
+		{"skipped", fmt.Errorf("%w: no instance", doctor.ErrSkipped), nil, doctor.CategorySkipped},
+		{"other error", errors.New("boom"), nil, doctor.CategoryError},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, doctor.Classify(tc.err, tc.allowed...), tc.name)
+	}
+}
+
+func TestRegionReport(t *testing.T) {
+	checks := []doctor.Check{
+		{Action: "rds:DescribeDBInstances", Call: func(context.Context) error { return nil }},
+		{Action: "rds:DescribeDBLogFiles", Call: func(context.Context) error {
+			return &smithy.GenericAPIError{Code: "AccessDenied"}
+		}},
+		{Action: "pi:GetResourceMetrics", Call: func(context.Context) error { return doctor.ErrSkipped }},
+	}
+
+	report := doctor.RegionReport{
+		Region:    "eu-west-3",
+		AccountID: "123456789012",
+		Identity:  "arn:aws:sts::123456789012:assumed-role/exporter/session",
+		Results:   doctor.Run(context.Background(), checks),
+	}
+
+	assert.True(t, report.Failed(), "Report with access denied should fail")
+
+	var output bytes.Buffer
+	report.Write(&output)
+
+	assert.Contains(t, output.String(), "[OK] rds:DescribeDBInstances", "Successful check should be reported")
+	assert.Contains(t, output.String(), "[ACCESS_DENIED] rds:DescribeDBLogFiles", "Failed check should be reported")
+	assert.Contains(t, output.String(), "grant rds:DescribeDBLogFiles to arn:aws:sts::123456789012:assumed-role/exporter/session", "Advice should name the action and identity")
+	assert.Contains(t, output.String(), "[SKIPPED] pi:GetResourceMetrics", "Skipped check should be reported")
+
+	report.Results = report.Results[:1]
+	assert.False(t, report.Failed(), "Report with successful checks should not fail")
+}
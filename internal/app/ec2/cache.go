@@ -0,0 +1,123 @@
+package ec2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	Instance  EC2InstanceMetrics `json:"instance"`
+	FetchedAt time.Time          `json:"fetchedAt"`
+}
+
+// Cache keeps AWS EC2 instance types information between scrapes and, if a path is set, between restarts
+type Cache struct {
+	mutex   sync.Mutex
+	path    string
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	dirty   bool
+}
+
+// NewCache returns a cache whose entries expire after ttl, entries are persisted in the file at path (empty path: in memory only)
+// The cache is empty if the file does not exist yet
+func NewCache(path string, ttl time.Duration) (*Cache, error) {
+	cache := &Cache{
+		path:    path,
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+
+	if path == "" {
+		return cache, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+
+	if err != nil {
+		return cache, fmt.Errorf("can't read instance types cache: %w", err)
+	}
+
+	err = json.Unmarshal(content, &cache.entries)
+	if err != nil {
+		cache.entries = make(map[string]cacheEntry)
+
+		return cache, fmt.Errorf("can't parse instance types cache %s: %w", path, err)
+	}
+
+	return cache, nil
+}
+
+// get returns the cached information of an instance type, stale entries are returned only if allowStale is true
+func (c *Cache) get(instanceType string, now time.Time, allowStale bool) (EC2InstanceMetrics, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, found := c.entries[instanceType]
+	if !found {
+		return EC2InstanceMetrics{}, false
+	}
+
+	if !allowStale && now.Sub(entry.FetchedAt) > c.ttl {
+		return EC2InstanceMetrics{}, false
+	}
+
+	return entry.Instance, true
+}
+
+func (c *Cache) set(instanceType string, instance EC2InstanceMetrics, now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[instanceType] = cacheEntry{Instance: instance, FetchedAt: now}
+	c.dirty = true
+}
+
+// Save writes the cache to its file if it has been modified
+func (c *Cache) Save() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.path == "" || !c.dirty {
+		return nil
+	}
+
+	content, err := json.Marshal(c.entries)
+	if err != nil {
+		return fmt.Errorf("can't serialize instance types cache: %w", err)
+	}
+
+	// Write to a temporary file first so that an interrupted write can't corrupt the cache
+	tmpFile, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return fmt.Errorf("can't create instance types cache: %w", err)
+	}
+
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(content)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("can't write instance types cache: %w", err)
+	}
+
+	err = os.Rename(tmpFile.Name(), c.path)
+	if err != nil {
+		return fmt.Errorf("can't write instance types cache: %w", err)
+	}
+
+	c.dirty = false
+
+	return nil
+}
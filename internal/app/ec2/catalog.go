@@ -0,0 +1,99 @@
+package ec2
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	converter "github.com/TeiNam/prometheus-rds-exporter/internal/app/unit"
+)
+
+// defaultCatalog contains capabilities of common RDS instance classes
+// Values come from AWS EC2 and RDS instance type documentation and are used when AWS EC2 API can't describe a class
+//
+//go:embed catalog.json
+var defaultCatalog []byte
+
+// InstanceClass describes an RDS instance class in the catalog, using AWS documentation units (Mbps are decimal megabits per second)
+type InstanceClass struct {
+	Vcpu                   int32   `json:"vcpu"`
+	MemoryMiB              int64   `json:"memoryMiB"`
+	BaselineBandwidthMbps  int32   `json:"baselineBandwidthMbps"`
+	BaselineIops           int32   `json:"baselineIops"`
+	MaximumIops            int32   `json:"maximumIops"`
+	BaselineThroughputMBps float64 `json:"baselineThroughputMBps"`
+	MaximumThroughputMBps  float64 `json:"maximumThroughputMBps"`
+	NetworkPerformance     string  `json:"networkPerformance"`
+	RDSOnly                bool    `json:"rdsOnly"` // Class has no AWS EC2 equivalent and must not be requested to AWS EC2 API
+}
+
+// Catalog contains RDS instance classes indexed by class name (eg. db.t3.large)
+type Catalog map[string]InstanceClass
+
+// metrics returns instance class capabilities
+func (i InstanceClass) metrics() EC2InstanceMetrics {
+	return EC2InstanceMetrics{
+		BaselineBandwidth:  megabitsToBits(i.BaselineBandwidthMbps),
+		BaselineIops:       i.BaselineIops,
+		BaselineThroughput: converter.MegaBytesToBytes(i.BaselineThroughputMBps),
+		MaximumIops:        i.MaximumIops,
+		MaximumThroughput:  converter.MegaBytesToBytes(i.MaximumThroughputMBps),
+		Memory:             converter.MegaBytesToBytes(i.MemoryMiB),
+		NetworkPerformance: i.NetworkPerformance,
+		Vcpu:               i.Vcpu,
+	}
+}
+
+// InstanceMetrics returns capabilities of an instance class of the catalog
+func (c Catalog) InstanceMetrics(instanceClass string) (EC2InstanceMetrics, bool) {
+	class, found := c[instanceClass]
+	if !found {
+		return EC2InstanceMetrics{}, false
+	}
+
+	return class.metrics(), true
+}
+
+// DefaultCatalog returns the instance class catalog embedded in the exporter
+func DefaultCatalog() (Catalog, error) {
+	catalog := make(Catalog)
+
+	err := json.Unmarshal(defaultCatalog, &catalog)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse embedded instance class catalog: %w", err)
+	}
+
+	return catalog, nil
+}
+
+// LoadCatalog returns the embedded instance class catalog overridden by classes defined in the file at path
+// Classes defined in the file replace embedded classes, an empty path returns the embedded catalog
+func LoadCatalog(path string) (Catalog, error) {
+	catalog, err := DefaultCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		return catalog, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read instance class catalog: %w", err)
+	}
+
+	overrides := make(Catalog)
+
+	err = json.Unmarshal(content, &overrides)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse instance class catalog %s: %w", path, err)
+	}
+
+	for class, instanceClass := range overrides {
+		catalog[class] = instanceClass
+	}
+
+	return catalog, nil
+}
@@ -3,26 +3,42 @@ package ec2
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
-	converter "github.com/TeiNam/prometheus-rds-exporter/internal/app/unit"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/iam"
 	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
 	aws_ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
 const (
-	maxInstanceTypesPerEC2APIRequest int = 100 // Limit the number of instance types per request due to AWS API limits
+	maxInstanceTypesPerEC2APIRequest int    = 100             // Limit the number of instance types per request due to AWS API limits
+	serverlessInstanceType           string = "db.serverless" // Aurora Serverless v2 instances have no EC2 equivalent
 )
 
 type EC2InstanceMetrics struct {
-	MaximumIops       int32
-	MaximumThroughput float64
-	Memory            int64
-	Vcpu              int32
+	BaselineBandwidth  float64
+	BaselineIops       int32
+	BaselineThroughput float64
+	MaximumIops        int32
+	MaximumThroughput  float64
+	Memory             int64
+	NetworkPerformance string
+	Vcpu               int32
 }
 
 type Metrics struct {
 	Instances map[string]EC2InstanceMetrics
+	Unknown   []string // Instance classes described neither by AWS EC2 API nor by the catalog
+}
+
+type Configuration struct {
+	Catalog Catalog // Instance classes used when AWS EC2 API can't describe a class
+	Cache   *Cache  // Cache of AWS EC2 API responses (nil: AWS EC2 API is called on every scrape)
 }
 
 type Statistics struct {
@@ -33,54 +49,197 @@ type EC2Client interface {
 	DescribeInstanceTypes(ctx context.Context, input *aws_ec2.DescribeInstanceTypesInput, fn ...func(*aws_ec2.Options)) (*aws_ec2.DescribeInstanceTypesOutput, error)
 }
 
-func NewFetcher(client EC2Client) *EC2Fetcher {
+// Permissions returns AWS IAM statements required by the fetcher
+func Permissions() []iam.Statement {
+	return []iam.Statement{
+		iam.Allow("AllowInstanceTypeDescriptions", []string{"ec2:DescribeInstanceTypes"}, iam.AllResources),
+	}
+}
+
+func NewFetcher(client EC2Client, configuration Configuration) *EC2Fetcher {
 	return &EC2Fetcher{
-		client: client,
+		client:        client,
+		configuration: configuration,
 	}
 }
 
 type EC2Fetcher struct {
-	client     EC2Client
-	statistics Statistics
+	client        EC2Client
+	statistics    Statistics
+	configuration Configuration
 }
 
 func (e *EC2Fetcher) GetStatistics() Statistics {
 	return e.statistics
 }
 
+// describeInstanceTypes returns information about AWS EC2 instance types, indexed by RDS instance class
+func (e *EC2Fetcher) describeInstanceTypes(instances []string) (map[string]EC2InstanceMetrics, error) {
+	metrics := make(map[string]EC2InstanceMetrics)
+
+	// Remove "db." prefix from instance types
+	instanceTypesToFetch := make([]aws_ec2_types.InstanceType, len(instances))
+	for i, instance := range instances {
+		instanceTypesToFetch[i] = (aws_ec2_types.InstanceType)(removeDBPrefix(instance))
+	}
+
+	input := &aws_ec2.DescribeInstanceTypesInput{InstanceTypes: instanceTypesToFetch}
+
+	resp, err := e.client.DescribeInstanceTypes(context.TODO(), input)
+	if err != nil {
+		return nil, fmt.Errorf("can't fetch describe instance types: %w", err)
+	}
+
+	e.statistics.EC2ApiCall++
+
+	for _, i := range resp.InstanceTypes {
+		metrics[addDBPrefix(string(i.InstanceType))] = newEC2InstanceMetrics(i)
+	}
+
+	return metrics, nil
+}
+
+// describeValidInstanceTypes returns information about AWS EC2 instance types, ignoring instance types rejected by AWS EC2 API
+// RDS only classes not flagged in the catalog and new classes are rejected by AWS EC2 API, the catalog is used instead
+// Rejected instance types are removed from the request, or instance types are requested one by one if AWS EC2 API doesn't name them
+func (e *EC2Fetcher) describeValidInstanceTypes(instances []string) (map[string]EC2InstanceMetrics, error) {
+	metrics, err := e.describeInstanceTypes(instances)
+	if err == nil || !isInvalidInstanceTypeError(err) {
+		return metrics, err
+	}
+
+	if len(instances) == 1 {
+		return map[string]EC2InstanceMetrics{}, nil
+	}
+
+	rejected := getRejectedInstanceTypes(err)
+	valid := slices.DeleteFunc(slices.Clone(instances), func(instance string) bool { return slices.Contains(rejected, instance) })
+
+	switch {
+	case len(valid) == 0:
+		return map[string]EC2InstanceMetrics{}, nil
+	case len(valid) < len(instances):
+		return e.describeValidInstanceTypes(valid)
+	}
+
+	metrics = make(map[string]EC2InstanceMetrics)
+
+	var errs []error
+
+	for _, instance := range instances {
+		instanceMetrics, err := e.describeValidInstanceTypes([]string{instance})
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		maps.Copy(metrics, instanceMetrics)
+	}
+
+	return metrics, errors.Join(errs...)
+}
+
+// instanceClass returns capabilities of an instance class, AWS EC2 API values override catalog values
+func (e *EC2Fetcher) instanceClass(instanceType string, live EC2InstanceMetrics) EC2InstanceMetrics {
+	class, found := e.configuration.Catalog[instanceType]
+	if !found {
+		return live
+	}
+
+	return merge(class.metrics(), live)
+}
+
 // GetDBInstanceTypeInformation returns information about specified AWS EC2 instance types
 // AWS RDS API use "db." prefix while AWS EC2 API don't so we must remove it to obtains instance type information
+// Aurora Serverless v2 class is ignored since its capacity is defined by the cluster scaling configuration
+// Classes that AWS EC2 API can't describe are read from the catalog, remaining classes are reported as unknown
 func (e *EC2Fetcher) GetDBInstanceTypeInformation(instanceTypes []string) (Metrics, error) {
+	var (
+		instanceTypesToFetch []string
+		errs                 []error
+	)
+
 	metrics := make(map[string]EC2InstanceMetrics)
+	cache := e.configuration.Cache
+	now := time.Now()
+
+	for _, instanceType := range instanceTypes {
+		if instanceType == serverlessInstanceType {
+			continue
+		}
 
-	for _, instances := range chunkBy(instanceTypes, maxInstanceTypesPerEC2APIRequest) {
-		// Remove "db." prefix from instance types
-		instanceTypesToFetch := make([]aws_ec2_types.InstanceType, len(instances))
-		for i, instance := range instances {
-			instanceTypesToFetch[i] = (aws_ec2_types.InstanceType)(removeDBPrefix(instance))
+		if cache != nil {
+			if live, found := cache.get(instanceType, now, false); found {
+				metrics[instanceType] = e.instanceClass(instanceType, live)
+
+				continue
+			}
+		}
+
+		if e.configuration.Catalog[instanceType].RDSOnly {
+			continue
 		}
 
-		input := &aws_ec2.DescribeInstanceTypesInput{InstanceTypes: instanceTypesToFetch}
+		instanceTypesToFetch = append(instanceTypesToFetch, instanceType)
+	}
+
+	for _, instances := range chunkBy(instanceTypesToFetch, maxInstanceTypesPerEC2APIRequest) {
+		// Empty request would return all EC2 instance types
+		if len(instances) == 0 {
+			continue
+		}
 
-		resp, err := e.client.DescribeInstanceTypes(context.TODO(), input)
+		liveMetrics, err := e.describeValidInstanceTypes(instances)
 		if err != nil {
-			return Metrics{}, fmt.Errorf("can't fetch describe instance types: %w", err)
+			errs = append(errs, err)
+		}
+
+		for instanceType, live := range liveMetrics {
+			metrics[instanceType] = e.instanceClass(instanceType, live)
+
+			if cache != nil {
+				cache.set(instanceType, live, now)
+			}
+		}
+	}
+
+	var unknown []string
+
+	for _, instanceType := range instanceTypes {
+		if _, found := metrics[instanceType]; found || instanceType == serverlessInstanceType {
+			continue
 		}
 
-		e.statistics.EC2ApiCall++
+		// Expired cache entries are preferred to catalog values if AWS EC2 API failed
+		if cache != nil {
+			if live, found := cache.get(instanceType, now, true); found {
+				metrics[instanceType] = e.instanceClass(instanceType, live)
 
-		for _, i := range resp.InstanceTypes {
-			instanceName := addDBPrefix(string(i.InstanceType))
-			metrics[instanceName] = EC2InstanceMetrics{
-				Vcpu:              *i.VCpuInfo.DefaultVCpus,
-				MaximumIops:       *i.EbsInfo.EbsOptimizedInfo.MaximumIops,
-				MaximumThroughput: converter.MegaBytesToBytes(*i.EbsInfo.EbsOptimizedInfo.MaximumThroughputInMBps),
-				Memory:            converter.MegaBytesToBytes(*i.MemoryInfo.SizeInMiB),
+				continue
 			}
 		}
+
+		if class, found := e.configuration.Catalog[instanceType]; found {
+			metrics[instanceType] = class.metrics()
+
+			continue
+		}
+
+		unknown = append(unknown, instanceType)
+	}
+
+	sort.Strings(unknown)
+
+	if cache != nil {
+		err := cache.Save()
+		if err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	return Metrics{
 		Instances: metrics,
-	}, nil
+		Unknown:   unknown,
+	}, errors.Join(errs...)
 }
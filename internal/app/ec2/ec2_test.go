@@ -1,7 +1,10 @@
 package ec2_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2"
 	mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2/mock"
@@ -14,7 +17,7 @@ func TestGetDBInstanceTypeInformation(t *testing.T) {
 	client := mock.EC2Client{}
 
 	instanceTypes := []string{"db.t3.large", "db.t3.small"}
-	fetcher := ec2.NewFetcher(client)
+	fetcher := ec2.NewFetcher(client, ec2.Configuration{})
 	result, err := fetcher.GetDBInstanceTypeInformation(instanceTypes)
 
 	require.NoError(t, err, "GetDBInstanceTypeInformation must succeed")
@@ -22,9 +25,130 @@ func TestGetDBInstanceTypeInformation(t *testing.T) {
 	assert.Equal(t, converter.MegaBytesToBytes(mock.InstanceT3Large.Memory), result.Instances["db.t3.large"].Memory, "Memory don't match")
 	assert.Equal(t, mock.InstanceT3Large.MaximumIops, result.Instances["db.t3.large"].MaximumIops, "MaximumThroughput don't match")
 	assert.Equal(t, converter.MegaBytesToBytes(mock.InstanceT3Large.MaximumThroughput), result.Instances["db.t3.large"].MaximumThroughput, "MaximumThroughput don't match")
+	assert.Equal(t, mock.InstanceT3Large.BaselineIops, result.Instances["db.t3.large"].BaselineIops, "BaselineIops don't match")
+	assert.Equal(t, converter.MegaBytesToBytes(mock.InstanceT3Large.BaselineThroughput), result.Instances["db.t3.large"].BaselineThroughput, "BaselineThroughput don't match")
+	assert.Equal(t, float64(mock.InstanceT3LargeBaselineBandwidth)*1000*1000, result.Instances["db.t3.large"].BaselineBandwidth, "BaselineBandwidth don't match")
+	assert.Equal(t, mock.InstanceT3Large.NetworkPerformance, result.Instances["db.t3.large"].NetworkPerformance, "NetworkPerformance don't match")
 
 	assert.Equal(t, mock.InstanceT3Small.Vcpu, result.Instances["db.t3.small"].Vcpu, "vCPU don't match")
 	assert.Equal(t, converter.MegaBytesToBytes(mock.InstanceT3Small.Memory), result.Instances["db.t3.small"].Memory, "Memory don't match")
 
 	assert.Equal(t, float64(1), fetcher.GetStatistics().EC2ApiCall, "EC2 API call don't match")
 }
+
+func TestServerlessInstanceTypeIsIgnored(t *testing.T) {
+	client := mock.EC2Client{}
+
+	fetcher := ec2.NewFetcher(client, ec2.Configuration{})
+	result, err := fetcher.GetDBInstanceTypeInformation([]string{"db.serverless"})
+
+	require.NoError(t, err, "GetDBInstanceTypeInformation must succeed")
+	assert.Empty(t, result.Instances, "Serverless class must not be returned")
+	assert.Equal(t, float64(0), fetcher.GetStatistics().EC2ApiCall, "EC2 API must not be called for serverless class")
+}
+
+func TestCatalogFallback(t *testing.T) {
+	client := mock.EC2Client{}
+
+	catalog, err := ec2.DefaultCatalog()
+	require.NoError(t, err, "Embedded catalog must be valid")
+
+	fetcher := ec2.NewFetcher(client, ec2.Configuration{Catalog: catalog})
+	result, err := fetcher.GetDBInstanceTypeInformation([]string{"db.t3.large", "db.t3.small", "db.x2g.large", "db.unknown.large"})
+
+	require.NoError(t, err, "GetDBInstanceTypeInformation must succeed")
+	assert.Equal(t, float64(1), fetcher.GetStatistics().EC2ApiCall, "RDS only classes must not be requested to EC2 API")
+
+	assert.Equal(t, mock.InstanceT3Large.Vcpu, result.Instances["db.t3.large"].Vcpu, "EC2 API values must be used")
+	assert.Equal(t, float64(mock.InstanceT3LargeBaselineBandwidth)*1000*1000, result.Instances["db.t3.large"].BaselineBandwidth, "EC2 API values must override catalog values")
+	assert.Equal(t, catalog["db.t3.small"].BaselineIops, result.Instances["db.t3.small"].BaselineIops, "Catalog values must complete EC2 API values")
+	assert.Equal(t, converter.GigaBytesToBytes(int64(32)), result.Instances["db.x2g.large"].Memory, "RDS only class must come from catalog")
+	assert.Equal(t, []string{"db.unknown.large"}, result.Unknown, "Unknown classes must be reported")
+}
+
+func TestInvalidInstanceTypeUsesCatalog(t *testing.T) {
+	client := mock.EC2Client{}
+
+	catalog := ec2.Catalog{mock.InvalidInstanceType: ec2.InstanceClass{Vcpu: 4}}
+
+	fetcher := ec2.NewFetcher(client, ec2.Configuration{Catalog: catalog})
+	result, err := fetcher.GetDBInstanceTypeInformation([]string{mock.InvalidInstanceType})
+
+	require.NoError(t, err, "Rejected instance types must not be reported as errors")
+	assert.Equal(t, int32(4), result.Instances[mock.InvalidInstanceType].Vcpu, "Rejected instance type must come from catalog")
+	assert.Empty(t, result.Unknown, "Catalog classes must not be reported as unknown")
+}
+
+func TestChunkWithInvalidInstanceTypes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		invalid       string
+		expectedCalls float64
+	}{
+		{name: "rejected instance types are removed from the request", invalid: mock.InvalidInstanceType, expectedCalls: 1},
+		{name: "instance types are requested one by one if rejected instance types are unknown", invalid: mock.UnnamedInvalidInstanceType, expectedCalls: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			catalog := ec2.Catalog{tc.invalid: ec2.InstanceClass{Vcpu: 4}}
+
+			fetcher := ec2.NewFetcher(mock.EC2Client{}, ec2.Configuration{Catalog: catalog})
+			result, err := fetcher.GetDBInstanceTypeInformation([]string{"db.t3.large", tc.invalid, "db.t3.small"})
+
+			require.NoError(t, err, "Rejected instance types must not be reported as errors")
+			assert.Equal(t, tc.expectedCalls, fetcher.GetStatistics().EC2ApiCall, "Successful AWS EC2 API calls mismatch")
+			assert.Equal(t, mock.InstanceT3Large.Vcpu, result.Instances["db.t3.large"].Vcpu, "Valid instance types of the chunk must come from AWS EC2 API")
+			assert.Equal(t, mock.InstanceT3Small.Vcpu, result.Instances["db.t3.small"].Vcpu, "Valid instance types of the chunk must come from AWS EC2 API")
+			assert.Equal(t, int32(4), result.Instances[tc.invalid].Vcpu, "Rejected instance type must come from catalog")
+			assert.Empty(t, result.Unknown, "No instance type must be reported as unknown")
+		})
+	}
+}
+
+func TestLoadCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	err := os.WriteFile(path, []byte(`{"db.t3.large": {"vcpu": 3}, "db.custom.large": {"vcpu": 8, "rdsOnly": true}}`), 0o600)
+	require.NoError(t, err)
+
+	catalog, err := ec2.LoadCatalog(path)
+	require.NoError(t, err, "LoadCatalog must succeed")
+	assert.Equal(t, int32(3), catalog["db.t3.large"].Vcpu, "File classes must override embedded classes")
+	assert.True(t, catalog["db.custom.large"].RDSOnly, "File classes must be added")
+	assert.Contains(t, catalog, "db.r6g.large", "Embedded classes must be kept")
+
+	_, err = ec2.LoadCatalog(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err, "Missing catalog file must fail")
+}
+
+func TestInstanceTypesCache(t *testing.T) {
+	client := mock.EC2Client{}
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	cache, err := ec2.NewCache(path, time.Hour)
+	require.NoError(t, err, "Missing cache file must not fail")
+
+	fetcher := ec2.NewFetcher(client, ec2.Configuration{Cache: cache})
+	_, err = fetcher.GetDBInstanceTypeInformation([]string{"db.t3.large"})
+	require.NoError(t, err, "GetDBInstanceTypeInformation must succeed")
+	assert.Equal(t, float64(1), fetcher.GetStatistics().EC2ApiCall, "EC2 API must be called on empty cache")
+
+	// Simulate a restart
+	cache, err = ec2.NewCache(path, time.Hour)
+	require.NoError(t, err, "Cache file must be readable")
+
+	fetcher = ec2.NewFetcher(client, ec2.Configuration{Cache: cache})
+	result, err := fetcher.GetDBInstanceTypeInformation([]string{"db.t3.large"})
+	require.NoError(t, err, "GetDBInstanceTypeInformation must succeed")
+	assert.Equal(t, float64(0), fetcher.GetStatistics().EC2ApiCall, "EC2 API must not be called for cached instance types")
+	assert.Equal(t, mock.InstanceT3Large.Vcpu, result.Instances["db.t3.large"].Vcpu, "Cached vCPU don't match")
+
+	// Expired entries are refreshed
+	cache, err = ec2.NewCache(path, 0)
+	require.NoError(t, err, "Cache file must be readable")
+
+	fetcher = ec2.NewFetcher(client, ec2.Configuration{Cache: cache})
+	_, err = fetcher.GetDBInstanceTypeInformation([]string{"db.t3.large"})
+	require.NoError(t, err, "GetDBInstanceTypeInformation must succeed")
+	assert.Equal(t, float64(1), fetcher.GetStatistics().EC2ApiCall, "EC2 API must be called for expired instance types")
+}
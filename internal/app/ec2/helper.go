@@ -1,6 +1,24 @@
 package ec2
 
-import "strings"
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	converter "github.com/TeiNam/prometheus-rds-exporter/internal/app/unit"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	invalidInstanceTypeErrorCode = "InvalidInstanceType" // Returned by AWS EC2 API for instance types it doesn't know
+	bitsPerMegabit               = 1000 * 1000
+)
+
+// rejectedInstanceTypesPattern extracts the list of instance types from AWS EC2 API InvalidInstanceType error messages
+// eg. "The following supplied instance types do not exist: [x2iedn.large, r6gd.large]"
+var rejectedInstanceTypesPattern = regexp.MustCompile(`\[([^\]]*)\]`)
 
 func chunkBy[T any](items []T, chunkSize int) (chunks [][]T) {
 	for chunkSize < len(items) {
@@ -17,5 +35,106 @@ func addDBPrefix(instance string) string {
 
 // removeDBPrefix removes "db." prefix for RDS instance type
 func removeDBPrefix(instance string) string {
-	return strings.Trim(instance, "db.")
+	return strings.TrimPrefix(instance, "db.")
+}
+
+// megabitsToBits converts AWS EC2 bandwidth in megabits per second (decimal unit) to bits per second
+func megabitsToBits(bandwidth int32) float64 {
+	return float64(bandwidth) * bitsPerMegabit
+}
+
+// isInvalidInstanceTypeError returns true if AWS EC2 API rejected an instance type
+func isInvalidInstanceTypeError(err error) bool {
+	var apiErr smithy.APIError
+
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == invalidInstanceTypeErrorCode
+}
+
+// getRejectedInstanceTypes returns RDS instance classes named in an AWS EC2 API InvalidInstanceType error
+func getRejectedInstanceTypes(err error) []string {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return nil
+	}
+
+	match := rejectedInstanceTypesPattern.FindStringSubmatch(apiErr.ErrorMessage())
+	if match == nil {
+		return nil
+	}
+
+	var instances []string
+
+	for _, instanceType := range strings.Split(match[1], ",") {
+		instanceType = strings.TrimSpace(instanceType)
+		if instanceType != "" {
+			instances = append(instances, addDBPrefix(instanceType))
+		}
+	}
+
+	return instances
+}
+
+// newEC2InstanceMetrics converts AWS EC2 instance type information, missing information are left empty
+func newEC2InstanceMetrics(instanceType aws_ec2_types.InstanceTypeInfo) EC2InstanceMetrics {
+	metrics := EC2InstanceMetrics{}
+
+	if instanceType.VCpuInfo != nil {
+		metrics.Vcpu = aws.ToInt32(instanceType.VCpuInfo.DefaultVCpus)
+	}
+
+	if instanceType.MemoryInfo != nil {
+		metrics.Memory = converter.MegaBytesToBytes(aws.ToInt64(instanceType.MemoryInfo.SizeInMiB))
+	}
+
+	if instanceType.NetworkInfo != nil {
+		metrics.NetworkPerformance = aws.ToString(instanceType.NetworkInfo.NetworkPerformance)
+	}
+
+	if instanceType.EbsInfo != nil && instanceType.EbsInfo.EbsOptimizedInfo != nil {
+		ebs := instanceType.EbsInfo.EbsOptimizedInfo
+		metrics.BaselineBandwidth = megabitsToBits(aws.ToInt32(ebs.BaselineBandwidthInMbps))
+		metrics.BaselineIops = aws.ToInt32(ebs.BaselineIops)
+		metrics.BaselineThroughput = converter.MegaBytesToBytes(aws.ToFloat64(ebs.BaselineThroughputInMBps))
+		metrics.MaximumIops = aws.ToInt32(ebs.MaximumIops)
+		metrics.MaximumThroughput = converter.MegaBytesToBytes(aws.ToFloat64(ebs.MaximumThroughputInMBps))
+	}
+
+	return metrics
+}
+
+// merge returns base values overridden by non empty values of override
+func merge(base EC2InstanceMetrics, override EC2InstanceMetrics) EC2InstanceMetrics {
+	if override.BaselineBandwidth != 0 {
+		base.BaselineBandwidth = override.BaselineBandwidth
+	}
+
+	if override.BaselineIops != 0 {
+		base.BaselineIops = override.BaselineIops
+	}
+
+	if override.BaselineThroughput != 0 {
+		base.BaselineThroughput = override.BaselineThroughput
+	}
+
+	if override.MaximumIops != 0 {
+		base.MaximumIops = override.MaximumIops
+	}
+
+	if override.MaximumThroughput != 0 {
+		base.MaximumThroughput = override.MaximumThroughput
+	}
+
+	if override.Memory != 0 {
+		base.Memory = override.Memory
+	}
+
+	if override.NetworkPerformance != "" {
+		base.NetworkPerformance = override.NetworkPerformance
+	}
+
+	if override.Vcpu != 0 {
+		base.Vcpu = override.Vcpu
+	}
+
+	return base
 }
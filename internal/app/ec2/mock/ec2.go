@@ -3,20 +3,30 @@ package mocks
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
 	aws_ec2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go"
 )
 
 //nolint:golint,gomnd
 var InstanceT3Large = ec2.EC2InstanceMetrics{
-	MaximumIops:       15700,
-	MaximumThroughput: 347.5,
-	Memory:            8,
-	Vcpu:              2,
+	BaselineIops:       4000,
+	BaselineThroughput: 86.875,
+	MaximumIops:        15700,
+	MaximumThroughput:  347.5,
+	Memory:             8,
+	NetworkPerformance: "Up to 5 Gigabit",
+	Vcpu:               2,
 }
 
+// InstanceT3LargeBaselineBandwidth is the baseline bandwidth of t3.large in Mbps
+const InstanceT3LargeBaselineBandwidth int32 = 695
+
 //nolint:golint,gomnd
 var InstanceT3Small = ec2.EC2InstanceMetrics{
 	MaximumIops:       11800,
@@ -25,12 +35,35 @@ var InstanceT3Small = ec2.EC2InstanceMetrics{
 	Vcpu:              2,
 }
 
+// Instance types rejected by the mock like AWS EC2 API rejects RDS only classes
+const (
+	InvalidInstanceType        = "db.invalid.large"
+	UnnamedInvalidInstanceType = "db.unnamed.large" // Rejected without being named in the error message
+)
+
 type EC2Client struct{}
 
 func (m EC2Client) DescribeInstanceTypes(ctx context.Context, input *aws_ec2.DescribeInstanceTypesInput, optFns ...func(*aws_ec2.Options)) (*aws_ec2.DescribeInstanceTypesOutput, error) {
-	var instances []aws_ec2_types.InstanceTypeInfo
+	var (
+		instances []aws_ec2_types.InstanceTypeInfo
+		invalid   []string
+	)
 
 	for _, instanceType := range input.InstanceTypes {
+		switch "db." + string(instanceType) {
+		case InvalidInstanceType:
+			invalid = append(invalid, string(instanceType))
+		case UnnamedInvalidInstanceType:
+			return nil, &smithy.GenericAPIError{Code: "InvalidInstanceType", Message: "Invalid instance type"}
+		}
+	}
+
+	if len(invalid) > 0 {
+		return nil, &smithy.GenericAPIError{Code: "InvalidInstanceType", Message: fmt.Sprintf("The following supplied instance types do not exist: [%s]", strings.Join(invalid, ", "))}
+	}
+
+	for _, instanceType := range input.InstanceTypes {
+
 		//nolint // Hide "missing cases in switch" alert because instanceType has many values. Mock with return empty result for unknown instances
 		switch instanceType {
 		case "t3.large":
@@ -38,9 +71,13 @@ func (m EC2Client) DescribeInstanceTypes(ctx context.Context, input *aws_ec2.Des
 				InstanceType: instanceType,
 				VCpuInfo:     &aws_ec2_types.VCpuInfo{DefaultVCpus: &InstanceT3Large.Vcpu},
 				MemoryInfo:   &aws_ec2_types.MemoryInfo{SizeInMiB: &InstanceT3Large.Memory},
+				NetworkInfo:  &aws_ec2_types.NetworkInfo{NetworkPerformance: &InstanceT3Large.NetworkPerformance},
 				EbsInfo: &aws_ec2_types.EbsInfo{EbsOptimizedInfo: &aws_ec2_types.EbsOptimizedInfo{
-					MaximumIops:             &InstanceT3Large.MaximumIops,
-					MaximumThroughputInMBps: &InstanceT3Large.MaximumThroughput,
+					BaselineBandwidthInMbps:  aws.Int32(InstanceT3LargeBaselineBandwidth),
+					BaselineIops:             &InstanceT3Large.BaselineIops,
+					BaselineThroughputInMBps: &InstanceT3Large.BaselineThroughput,
+					MaximumIops:              &InstanceT3Large.MaximumIops,
+					MaximumThroughputInMBps:  &InstanceT3Large.MaximumThroughput,
 				}},
 			})
 		case "t3.small":
@@ -0,0 +1,36 @@
+package exporter
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// Endpoint returns the address and port of the instance endpoint, empty while the instance is being created
+func (i InventoryInstance) Endpoint() string {
+	if i.RDS.EndpointAddress == "" {
+		return ""
+	}
+
+	return net.JoinHostPort(i.RDS.EndpointAddress, strconv.Itoa(int(i.RDS.EndpointPort)))
+}
+
+// TargetLabels returns the Prometheus service discovery labels of the instance, labeled like exporter metrics
+// tags are instance tags added as labels
+func (i InventoryInstance) TargetLabels(tags []string) map[string]string {
+	labels := map[string]string{
+		"aws_account_id": i.AccountID,
+		"aws_region":     i.Region,
+		"dbidentifier":   i.DBIdentifier,
+		"engine":         i.RDS.Engine,
+		"role":           i.RDS.Role,
+	}
+
+	for _, tag := range tags {
+		if value, found := i.RDS.Tags[tag]; found {
+			labels[fmt.Sprintf("tag_%s", ClearPrometheusLabel(tag))] = value
+		}
+	}
+
+	return labels
+}
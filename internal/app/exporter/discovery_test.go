@@ -0,0 +1,40 @@
+package exporter_test
+
+import (
+	"testing"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/exporter"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	rds_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/rds/mock"
+)
+
+func TestTargetLabels(t *testing.T) {
+	mysql := rds_mock.NewRdsInstance()
+	mysql.Engine = aws.String("mysql")
+	mysql.Endpoint.Port = aws.Int32(3306)
+
+	creating := rds_mock.NewRdsInstance()
+	creating.DBInstanceIdentifier = aws.String("creating")
+	creating.Endpoint = nil
+
+	instances := make(map[string]exporter.InventoryInstance)
+	for _, instance := range newInventoryCollector(t, "eu-west-3", *mysql, *creating).Inventory() {
+		instances[instance.DBIdentifier] = instance
+	}
+
+	require.Len(t, instances, 2, "All instances should be listed")
+
+	assert.Empty(t, instances["creating"].Endpoint(), "Instance being created should have no endpoint")
+	assert.Equal(t, *mysql.Endpoint.Address+":3306", instances[*mysql.DBInstanceIdentifier].Endpoint(), "Target should be the instance endpoint")
+	assert.Equal(t, map[string]string{
+		"aws_account_id": "123456789012",
+		"aws_region":     "eu-west-3",
+		"dbidentifier":   *mysql.DBInstanceIdentifier,
+		"engine":         "mysql",
+		"role":           "primary",
+		"tag_Team":       "sre",
+	}, instances[*mysql.DBInstanceIdentifier].TargetLabels([]string{"Team", "Unknown"}), "Labels mismatch")
+}
@@ -2,17 +2,22 @@
 package exporter
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/TeiNam/prometheus-rds-exporter/internal/app/cloudwatch"
 	"github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/pi"
 	"github.com/TeiNam/prometheus-rds-exporter/internal/app/rds"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/rightsizing"
 	"github.com/TeiNam/prometheus-rds-exporter/internal/app/servicequotas"
 	"github.com/TeiNam/prometheus-rds-exporter/internal/infra/build"
 	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/maps"
 )
 
 const (
@@ -20,23 +25,34 @@ const (
 	exporterDownStatusCode float64 = 0
 )
 
+// errNoMetricStreamData is the metric stream source error when no metric of the instances was received
+var errNoMetricStreamData = errors.New("no metric stream data received for instances")
+
 type Configuration struct {
-	CollectInstanceMetrics bool
-	CollectInstanceTags    bool
-	CollectInstanceTypes   bool
-	CollectLogsSize        bool
-	CollectMaintenances    bool
-	CollectQuotas          bool
-	CollectUsages          bool
+	AuroraStoragePricing       rds.AuroraStoragePricing
+	CloudWatchLookback         time.Duration
+	CloudWatchMaxStaleness     time.Duration
+	CloudWatchUseTimestamps    bool
+	CollectInstanceMetrics     bool
+	CollectInstanceTags        bool
+	CollectInstanceTypes       bool
+	CollectLogsSize            bool
+	CollectMaintenances        bool
+	CollectPerformanceInsights bool
+	CollectQuotas              bool
+	CollectUsages              bool
+	PerformanceInsightsTopSQL  int32
+	QuotasAllowList            []string
 }
 
 type Counters struct {
-	CloudwatchAPICalls    float64
-	EC2APIcalls           float64
-	Errors                float64
-	RDSAPIcalls           float64
-	ServiceQuotasAPICalls float64
-	UsageAPIcalls         float64
+	CloudwatchAPICalls          float64
+	EC2APIcalls                 float64
+	Errors                      float64
+	PerformanceInsightsAPICalls float64
+	RDSAPIcalls                 float64
+	ServiceQuotasAPICalls       float64
+	UsageAPIcalls               float64
 }
 
 type metrics struct {
@@ -44,11 +60,15 @@ type metrics struct {
 	RDS                 rds.Metrics
 	EC2                 ec2.Metrics
 	CloudwatchInstances cloudwatch.CloudWatchMetrics
+	CloudwatchClusters  cloudwatch.CloudWatchClusterMetrics
 	CloudWatchUsage     cloudwatch.UsageMetrics
+	PerformanceInsights pi.Metrics
 }
 
 type RdsCollector struct {
 	wg            sync.WaitGroup
+	fetchMutex    sync.Mutex   // Serializes fetches of concurrent scrapes
+	mutex         sync.RWMutex // Protects counters and metrics, read by the status page while a fetch is running
 	logger        slog.Logger
 	counters      Counters
 	metrics       metrics
@@ -60,59 +80,105 @@ type RdsCollector struct {
 	EC2Client           EC2Client
 	servicequotasClient servicequotasClient
 	cloudWatchClient    cloudWatchClient
-
-	errors                      *prometheus.Desc
-	DBLoad                      *prometheus.Desc
-	dBLoadCPU                   *prometheus.Desc
-	dBLoadNonCPU                *prometheus.Desc
-	allocatedStorage            *prometheus.Desc
-	information                 *prometheus.Desc
-	instanceMaximumIops         *prometheus.Desc
-	instanceMaximumThroughput   *prometheus.Desc
-	instanceMemory              *prometheus.Desc
-	instanceVCPU                *prometheus.Desc
-	instanceTags                *prometheus.Desc
-	logFilesSize                *prometheus.Desc
-	maxAllocatedStorage         *prometheus.Desc
-	maxIops                     *prometheus.Desc
-	status                      *prometheus.Desc
-	storageThroughput           *prometheus.Desc
-	up                          *prometheus.Desc
-	cpuUtilisation              *prometheus.Desc
-	freeStorageSpace            *prometheus.Desc
-	databaseConnections         *prometheus.Desc
-	freeableMemory              *prometheus.Desc
-	swapUsage                   *prometheus.Desc
-	writeIOPS                   *prometheus.Desc
-	readIOPS                    *prometheus.Desc
-	replicaLag                  *prometheus.Desc
-	replicationSlotDiskUsage    *prometheus.Desc
-	maximumUsedTransactionIDs   *prometheus.Desc
-	apiCall                     *prometheus.Desc
-	readThroughput              *prometheus.Desc
-	writeThroughput             *prometheus.Desc
-	backupRetentionPeriod       *prometheus.Desc
-	quotaDBInstances            *prometheus.Desc
-	quotaTotalStorage           *prometheus.Desc
-	quotaMaxDBInstanceSnapshots *prometheus.Desc
-	usageAllocatedStorage       *prometheus.Desc
-	usageDBInstances            *prometheus.Desc
-	usageManualSnapshots        *prometheus.Desc
-	exporterBuildInformation    *prometheus.Desc
-	transactionLogsDiskUsage    *prometheus.Desc
-	certificateValidTill        *prometheus.Desc
-	age                         *prometheus.Desc
-	BufferCacheHitRatio         *prometheus.Desc
-	Deadlocks                   *prometheus.Desc
-	Queries                     *prometheus.Desc
-	EngineUptime                *prometheus.Desc
-	SumBinaryLogSize            *prometheus.Desc
-	NumBinaryLogFiles           *prometheus.Desc
-	AuroraBinlogReplicaLag      *prometheus.Desc
-	BinLogDiskUsage             *prometheus.Desc
+	piClient            piClient
+	metricStream        metricStream
+
+	instanceClassCatalog ec2.Catalog
+	instanceTypesCache   *ec2.Cache
+	rightsizing          *rightsizing.Advisor
+
+	sourcesMutex sync.Mutex
+	sources      map[string]SourceStatus
+
+	errors                       *prometheus.Desc
+	DBLoad                       *prometheus.Desc
+	dBLoadCPU                    *prometheus.Desc
+	dBLoadNonCPU                 *prometheus.Desc
+	allocatedStorage             *prometheus.Desc
+	information                  *prometheus.Desc
+	instanceMaximumIops          *prometheus.Desc
+	instanceMaximumThroughput    *prometheus.Desc
+	instanceMemory               *prometheus.Desc
+	instanceVCPU                 *prometheus.Desc
+	instanceClassUnknown         *prometheus.Desc
+	instanceTags                 *prometheus.Desc
+	logFilesSize                 *prometheus.Desc
+	maxAllocatedStorage          *prometheus.Desc
+	maxIops                      *prometheus.Desc
+	status                       *prometheus.Desc
+	storageThroughput            *prometheus.Desc
+	up                           *prometheus.Desc
+	cpuUtilisation               *prometheus.Desc
+	freeStorageSpace             *prometheus.Desc
+	databaseConnections          *prometheus.Desc
+	freeableMemory               *prometheus.Desc
+	swapUsage                    *prometheus.Desc
+	writeIOPS                    *prometheus.Desc
+	readIOPS                     *prometheus.Desc
+	replicaLag                   *prometheus.Desc
+	replicationSlotDiskUsage     *prometheus.Desc
+	maximumUsedTransactionIDs    *prometheus.Desc
+	apiCall                      *prometheus.Desc
+	readThroughput               *prometheus.Desc
+	writeThroughput              *prometheus.Desc
+	backupRetentionPeriod        *prometheus.Desc
+	quotaDBInstances             *prometheus.Desc
+	quotaTotalStorage            *prometheus.Desc
+	quotaMaxDBInstanceSnapshots  *prometheus.Desc
+	usageAllocatedStorage        *prometheus.Desc
+	usageDBInstances             *prometheus.Desc
+	usageManualSnapshots         *prometheus.Desc
+	usageResourceCount           *prometheus.Desc
+	exporterBuildInformation     *prometheus.Desc
+	collectorDuration            *prometheus.Desc
+	collectorSuccess             *prometheus.Desc
+	collectorLastError           *prometheus.Desc
+	transactionLogsDiskUsage     *prometheus.Desc
+	certificateValidTill         *prometheus.Desc
+	age                          *prometheus.Desc
+	BufferCacheHitRatio          *prometheus.Desc
+	Deadlocks                    *prometheus.Desc
+	Queries                      *prometheus.Desc
+	EngineUptime                 *prometheus.Desc
+	SumBinaryLogSize             *prometheus.Desc
+	NumBinaryLogFiles            *prometheus.Desc
+	AuroraBinlogReplicaLag       *prometheus.Desc
+	BinLogDiskUsage              *prometheus.Desc
+	dBLoadWaitEvent              *prometheus.Desc
+	dBLoadSQL                    *prometheus.Desc
+	cloudwatchDatapointAge       *prometheus.Desc
+	serverlessMinCapacity        *prometheus.Desc
+	serverlessMaxCapacity        *prometheus.Desc
+	serverlessMinMemory          *prometheus.Desc
+	serverlessMaxMemory          *prometheus.Desc
+	serverlessMinVCPU            *prometheus.Desc
+	serverlessMaxVCPU            *prometheus.Desc
+	serverlessDatabaseCapacity   *prometheus.Desc
+	acuUtilization               *prometheus.Desc
+	ebsIOBalance                 *prometheus.Desc
+	ebsByteBalance               *prometheus.Desc
+	burstBalance                 *prometheus.Desc
+	cpuCreditBalance             *prometheus.Desc
+	instanceBaselineIops         *prometheus.Desc
+	instanceBaselineThroughput   *prometheus.Desc
+	instanceBaselineBandwidth    *prometheus.Desc
+	instanceNetwork              *prometheus.Desc
+	storageIopsUtilization       *prometheus.Desc
+	storageThroughputUtilization *prometheus.Desc
+	ebsIopsUtilization           *prometheus.Desc
+	ebsThroughputUtilization     *prometheus.Desc
+	memoryUtilization            *prometheus.Desc
+	storageUtilization           *prometheus.Desc
+	clusterVolumeBytesUsed       *prometheus.Desc
+	clusterVolumeReadIOs         *prometheus.Desc
+	clusterVolumeWriteIOs        *prometheus.Desc
+	clusterStorageMonthlyCost    *prometheus.Desc
+	rightsizingRecommendation    *prometheus.Desc
+	quota                        *prometheus.Desc
+	quotaUtilization             *prometheus.Desc
 }
 
-func NewCollector(logger slog.Logger, collectorConfiguration Configuration, awsAccountID string, awsRegion string, rdsClient rdsClient, ec2Client EC2Client, cloudWatchClient cloudWatchClient, servicequotasClient servicequotasClient) *RdsCollector {
+func NewCollector(logger slog.Logger, collectorConfiguration Configuration, awsAccountID string, awsRegion string, rdsClient rdsClient, ec2Client EC2Client, cloudWatchClient cloudWatchClient, servicequotasClient servicequotasClient, piClient piClient) *RdsCollector {
 	return &RdsCollector{
 		logger:              logger,
 		awsAccountID:        awsAccountID,
@@ -121,8 +187,10 @@ func NewCollector(logger slog.Logger, collectorConfiguration Configuration, awsA
 		servicequotasClient: servicequotasClient,
 		EC2Client:           ec2Client,
 		cloudWatchClient:    cloudWatchClient,
+		piClient:            piClient,
 
 		configuration: collectorConfiguration,
+		sources:       make(map[string]SourceStatus),
 
 		exporterBuildInformation: prometheus.NewDesc("rds_exporter_build_info",
 			"A metric with constant '1' value labeled by version from which exporter was built",
@@ -132,6 +200,18 @@ func NewCollector(logger slog.Logger, collectorConfiguration Configuration, awsA
 			"Total number of errors encountered by the exporter",
 			[]string{"aws_region"}, nil,
 		),
+		collectorDuration: prometheus.NewDesc("rds_exporter_collector_duration_seconds",
+			"Duration of the last fetch of the collector data source",
+			[]string{"aws_account_id", "aws_region", "collector"}, nil,
+		),
+		collectorSuccess: prometheus.NewDesc("rds_exporter_collector_success",
+			"1 if the last fetch of the collector data source succeeded, 0 otherwise",
+			[]string{"aws_account_id", "aws_region", "collector"}, nil,
+		),
+		collectorLastError: prometheus.NewDesc("rds_exporter_collector_last_error_info",
+			"Reason of the last fetch error of the collector data source, only exposed while the collector fails",
+			[]string{"aws_account_id", "aws_region", "collector", "reason"}, nil,
+		),
 		allocatedStorage: prometheus.NewDesc("rds_allocated_storage_bytes",
 			"Allocated storage",
 			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
@@ -172,6 +252,10 @@ func NewCollector(logger slog.Logger, collectorConfiguration Configuration, awsA
 			"Total of log files on the instance",
 			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
 		),
+		instanceClassUnknown: prometheus.NewDesc("rds_instance_class_unknown",
+			"Instance class described neither by AWS EC2 API nor by the instance class catalog",
+			[]string{"aws_account_id", "aws_region", "instance_class"}, nil,
+		),
 		instanceVCPU: prometheus.NewDesc("rds_instance_vcpu_average",
 			"Total vCPU for this instance class",
 			[]string{"aws_account_id", "aws_region", "instance_class"}, nil,
@@ -288,6 +372,10 @@ func NewCollector(logger slog.Logger, collectorConfiguration Configuration, awsA
 			"Manual snapshots count",
 			[]string{"aws_account_id", "aws_region"}, nil,
 		),
+		usageResourceCount: prometheus.NewDesc("rds_usage_resource_count",
+			"AWS RDS resource usage reported in AWS/Usage Cloudwatch namespace",
+			[]string{"aws_account_id", "aws_region", "resource", "class"}, nil,
+		),
 		BufferCacheHitRatio: prometheus.NewDesc("rds_buffer_cache_hit_ratio",
 			"The percentage of requests that are served by the buffer cache",
 			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
@@ -320,6 +408,134 @@ func NewCollector(logger slog.Logger, collectorConfiguration Configuration, awsA
 			"binary log disk usage",
 			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
 		),
+		dBLoadWaitEvent: prometheus.NewDesc("rds_dbload_wait_event_average",
+			"Number of active sessions grouped by wait event (Performance Insights)",
+			[]string{"aws_account_id", "aws_region", "dbidentifier", "wait_event", "wait_event_type"}, nil,
+		),
+		dBLoadSQL: prometheus.NewDesc("rds_dbload_sql_average",
+			"Number of active sessions generated by the top SQL digests (Performance Insights)",
+			[]string{"aws_account_id", "aws_region", "dbidentifier", "sql_id", "statement"}, nil,
+		),
+		cloudwatchDatapointAge: prometheus.NewDesc("rds_cloudwatch_datapoint_age_seconds",
+			"Age of the most recent Cloudwatch datapoint received for the instance",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		serverlessMinCapacity: prometheus.NewDesc("rds_serverless_min_capacity_acu",
+			"Minimum number of Aurora capacity units (ACU) of the Aurora Serverless v2 cluster",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		serverlessMaxCapacity: prometheus.NewDesc("rds_serverless_max_capacity_acu",
+			"Maximum number of Aurora capacity units (ACU) of the Aurora Serverless v2 cluster",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		serverlessMinMemory: prometheus.NewDesc("rds_serverless_min_memory_bytes",
+			"Approximate memory of the Aurora Serverless v2 instance at minimum capacity (2 GiB per ACU)",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		serverlessMaxMemory: prometheus.NewDesc("rds_serverless_max_memory_bytes",
+			"Approximate memory of the Aurora Serverless v2 instance at maximum capacity (2 GiB per ACU)",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		serverlessMinVCPU: prometheus.NewDesc("rds_serverless_min_vcpu_average",
+			"Approximate vCPU of the Aurora Serverless v2 instance at minimum capacity (0.25 vCPU per ACU)",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		serverlessMaxVCPU: prometheus.NewDesc("rds_serverless_max_vcpu_average",
+			"Approximate vCPU of the Aurora Serverless v2 instance at maximum capacity (0.25 vCPU per ACU)",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		serverlessDatabaseCapacity: prometheus.NewDesc("rds_serverless_database_capacity_acu",
+			"Current capacity of the Aurora Serverless v2 instance in Aurora capacity units (ACU)",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		acuUtilization: prometheus.NewDesc("rds_acu_utilization_percent",
+			"Percentage of the maximum capacity of the Aurora Serverless v2 cluster used by the instance",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		ebsIOBalance: prometheus.NewDesc("rds_ebs_io_balance_percent",
+			"Percentage of I/O credits remaining in the burst bucket of the instance EBS bandwidth",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		ebsByteBalance: prometheus.NewDesc("rds_ebs_byte_balance_percent",
+			"Percentage of throughput credits remaining in the burst bucket of the instance EBS bandwidth",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		burstBalance: prometheus.NewDesc("rds_storage_burst_balance_percent",
+			"Percentage of I/O credits remaining in the burst bucket of gp2 storage",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		cpuCreditBalance: prometheus.NewDesc("rds_cpu_credit_balance_average",
+			"Number of earned CPU credits accrued by burstable performance instances",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		instanceBaselineIops: prometheus.NewDesc("rds_instance_baseline_iops_average",
+			"Baseline IOPS of underlying EC2 instance class, maximum IOPS can only be sustained for 30 minutes per 24 hours",
+			[]string{"aws_account_id", "aws_region", "instance_class"}, nil,
+		),
+		instanceBaselineThroughput: prometheus.NewDesc("rds_instance_baseline_throughput_bytes",
+			"Baseline throughput of underlying EC2 instance class",
+			[]string{"aws_account_id", "aws_region", "instance_class"}, nil,
+		),
+		instanceBaselineBandwidth: prometheus.NewDesc("rds_instance_baseline_bandwidth_bits",
+			"Baseline EBS bandwidth of underlying EC2 instance class in bits per second",
+			[]string{"aws_account_id", "aws_region", "instance_class"}, nil,
+		),
+		instanceNetwork: prometheus.NewDesc("rds_instance_network_info",
+			"Network performance of underlying EC2 instance class",
+			[]string{"aws_account_id", "aws_region", "instance_class", "network_performance"}, nil,
+		),
+		storageIopsUtilization: prometheus.NewDesc("rds_storage_iops_utilization_ratio",
+			"Ratio of disk read and write IOPS to the effective IOPS limit, the lowest of storage and instance class maximum IOPS",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		storageThroughputUtilization: prometheus.NewDesc("rds_storage_throughput_utilization_ratio",
+			"Ratio of disk read and write throughput to the effective throughput limit, the lowest of storage and instance class maximum throughput",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		ebsIopsUtilization: prometheus.NewDesc("rds_instance_ebs_iops_utilization_ratio",
+			"Ratio of disk read and write IOPS to the baseline IOPS of the instance class",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		ebsThroughputUtilization: prometheus.NewDesc("rds_instance_ebs_throughput_utilization_ratio",
+			"Ratio of disk read and write throughput to the baseline throughput of the instance class",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		memoryUtilization: prometheus.NewDesc("rds_memory_utilization_ratio",
+			"Ratio of the instance class memory that is not freeable",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		storageUtilization: prometheus.NewDesc("rds_storage_utilization_ratio",
+			"Ratio of the allocated storage used",
+			[]string{"aws_account_id", "aws_region", "dbidentifier"}, nil,
+		),
+		clusterVolumeBytesUsed: prometheus.NewDesc("rds_cluster_volume_used_bytes",
+			"Storage used by the Aurora cluster volume",
+			[]string{"aws_account_id", "aws_region", "dbclusteridentifier"}, nil,
+		),
+		clusterVolumeReadIOs: prometheus.NewDesc("rds_cluster_volume_read_io_average",
+			"Number of billed read I/O operations on the Aurora cluster volume per 5 minutes",
+			[]string{"aws_account_id", "aws_region", "dbclusteridentifier"}, nil,
+		),
+		clusterVolumeWriteIOs: prometheus.NewDesc("rds_cluster_volume_write_io_average",
+			"Number of billed write I/O operations on the Aurora cluster volume per 5 minutes",
+			[]string{"aws_account_id", "aws_region", "dbclusteridentifier"}, nil,
+		),
+		clusterStorageMonthlyCost: prometheus.NewDesc("rds_cluster_storage_monthly_cost_estimate_dollars",
+			"Estimated monthly storage and I/O cost of the Aurora cluster volume with each Aurora storage type, based on observed I/O volume",
+			[]string{"aws_account_id", "aws_region", "dbclusteridentifier", "storage_type"}, nil,
+		),
+		rightsizingRecommendation: prometheus.NewDesc("rds_rightsizing_recommendation",
+			"Instance class recommended from the utilization observed over the rightsizing window (1: recommended)",
+			[]string{"aws_account_id", "aws_region", "dbidentifier", "current_class", "recommended_class", "reason"}, nil,
+		),
+		quota: prometheus.NewDesc("rds_quota",
+			"AWS RDS service quota value",
+			[]string{"aws_account_id", "aws_region", "quota_code", "quota_name", "unit"}, nil,
+		),
+		quotaUtilization: prometheus.NewDesc("rds_quota_utilization_ratio",
+			"Ratio of the AWS RDS service quota used (only for quotas whose usage is collected or can be derived from collected metrics)",
+			[]string{"aws_account_id", "aws_region", "quota_code", "quota_name"}, nil,
+		),
 	}
 }
 
@@ -331,10 +547,16 @@ func (c *RdsCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.apiCall
 	ch <- c.backupRetentionPeriod
 	ch <- c.certificateValidTill
+	ch <- c.cloudwatchDatapointAge
 	ch <- c.cpuUtilisation
 	ch <- c.dBLoadCPU
 	ch <- c.dBLoadNonCPU
+	ch <- c.dBLoadSQL
+	ch <- c.dBLoadWaitEvent
 	ch <- c.databaseConnections
+	ch <- c.collectorDuration
+	ch <- c.collectorLastError
+	ch <- c.collectorSuccess
 	ch <- c.errors
 	ch <- c.exporterBuildInformation
 	ch <- c.freeStorageSpace
@@ -344,13 +566,16 @@ func (c *RdsCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.instanceMaximumThroughput
 	ch <- c.instanceMemory
 	ch <- c.instanceVCPU
+	ch <- c.instanceClassUnknown
 	ch <- c.logFilesSize
 	ch <- c.maxAllocatedStorage
 	ch <- c.maxIops
 	ch <- c.maximumUsedTransactionIDs
+	ch <- c.quota
 	ch <- c.quotaDBInstances
 	ch <- c.quotaMaxDBInstanceSnapshots
 	ch <- c.quotaTotalStorage
+	ch <- c.quotaUtilization
 	ch <- c.readIOPS
 	ch <- c.readThroughput
 	ch <- c.replicaLag
@@ -363,6 +588,7 @@ func (c *RdsCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.usageAllocatedStorage
 	ch <- c.usageDBInstances
 	ch <- c.usageManualSnapshots
+	ch <- c.usageResourceCount
 	ch <- c.writeIOPS
 	ch <- c.writeThroughput
 	ch <- c.BufferCacheHitRatio
@@ -373,22 +599,54 @@ func (c *RdsCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.NumBinaryLogFiles
 	ch <- c.AuroraBinlogReplicaLag
 	ch <- c.BinLogDiskUsage
+	ch <- c.serverlessMinCapacity
+	ch <- c.serverlessMaxCapacity
+	ch <- c.serverlessMinMemory
+	ch <- c.serverlessMaxMemory
+	ch <- c.serverlessMinVCPU
+	ch <- c.serverlessMaxVCPU
+	ch <- c.serverlessDatabaseCapacity
+	ch <- c.acuUtilization
+	ch <- c.ebsIOBalance
+	ch <- c.ebsByteBalance
+	ch <- c.burstBalance
+	ch <- c.cpuCreditBalance
+	ch <- c.instanceBaselineIops
+	ch <- c.instanceBaselineThroughput
+	ch <- c.instanceBaselineBandwidth
+	ch <- c.instanceNetwork
+	ch <- c.storageIopsUtilization
+	ch <- c.storageThroughputUtilization
+	ch <- c.ebsIopsUtilization
+	ch <- c.ebsThroughputUtilization
+	ch <- c.memoryUtilization
+	ch <- c.storageUtilization
+	ch <- c.clusterVolumeBytesUsed
+	ch <- c.clusterVolumeReadIOs
+	ch <- c.clusterVolumeWriteIOs
+	ch <- c.clusterStorageMonthlyCost
+	ch <- c.rightsizingRecommendation
 }
 
 // getMetrics collects and return all RDS metrics
 func (c *RdsCollector) fetchMetrics() error {
 	c.logger.Debug("received query")
 
+	c.fetchMutex.Lock()
+	defer c.fetchMutex.Unlock()
+
+	cycleStart := time.Now()
+
 	// Fetch serviceQuotas metrics
 	if c.configuration.CollectQuotas {
-		go c.getQuotasMetrics(c.servicequotasClient)
 		c.wg.Add(1)
+		go c.getQuotasMetrics(c.servicequotasClient)
 	}
 
 	// Fetch usages metrics
 	if c.configuration.CollectUsages {
-		go c.getUsagesMetrics(c.cloudWatchClient)
 		c.wg.Add(1)
+		go c.getUsagesMetrics(c.cloudWatchClient)
 	}
 
 	// Fetch RDS instances metrics
@@ -399,51 +657,161 @@ func (c *RdsCollector) fetchMetrics() error {
 		CollectMaintenances: c.configuration.CollectMaintenances,
 	})
 
+	start := time.Now()
 	rdsMetrics, err := rdsFetcher.GetInstancesMetrics()
+	if errors.Is(err, rds.ErrServerlessCapacities) {
+		// Serverless capacities are optional, keep exporting instance metrics
+		c.logger.Error("can't fetch serverless capacities", "error", err)
+
+		c.mutex.Lock()
+		c.counters.Errors++
+		c.mutex.Unlock()
+
+		err = nil
+	}
+
+	c.recordFetch(SourceRDS, start, err)
+
 	if err != nil {
+		// Wait for quotas and usages fetches before returning, next fetch reuses the wait group
+		c.wg.Wait()
+
 		return fmt.Errorf("can't fetch RDS metrics: %w", err)
 	}
 
+	c.mutex.Lock()
 	c.metrics.RDS = rdsMetrics
 	c.counters.RDSAPIcalls += rdsFetcher.GetStatistics().RdsAPICall
+	c.mutex.Unlock()
 	c.logger.Debug("RDS metrics fetched")
 
 	// Compute uniq instances identifiers and instance types
 	instanceIdentifiers, instanceTypes := getUniqTypeAndIdentifiers(rdsMetrics.Instances)
 
+	// Fetch Performance Insights metrics for instances having Performance Insights enabled
+	if c.configuration.CollectPerformanceInsights {
+		performanceInsightsInstances := getPerformanceInsightsInstances(rdsMetrics.Instances)
+		if len(performanceInsightsInstances) > 0 {
+			c.wg.Add(1)
+			go c.getPerformanceInsightsMetrics(c.piClient, performanceInsightsInstances)
+		} else {
+			// Drop metrics of instances that no longer have Performance Insights enabled
+			c.mutex.Lock()
+			c.metrics.PerformanceInsights = pi.Metrics{}
+			c.mutex.Unlock()
+		}
+	}
+
 	// Fetch EC2 Metrics for instance types
 	if c.configuration.CollectInstanceTypes && len(instanceTypes) > 0 {
-		go c.getEC2Metrics(c.EC2Client, instanceTypes)
 		c.wg.Add(1)
+		go c.getEC2Metrics(c.EC2Client, instanceTypes)
 	}
 
-	// Fetch Cloudwatch metrics for instances
+	// Fetch Cloudwatch metrics for instances and Aurora clusters
+	// Metric streams only deliver instance metrics, so Aurora cluster metrics are always fetched from Cloudwatch API
 	if c.configuration.CollectInstanceMetrics {
-		go c.getCloudwatchMetrics(c.cloudWatchClient, instanceIdentifiers)
-		c.wg.Add(1)
+		clusterIdentifiers := getAuroraClusterIdentifiers(rdsMetrics.Instances)
+
+		if c.metricStream != nil {
+			c.getMetricStreamMetrics(instanceIdentifiers)
+			instanceIdentifiers = nil
+		}
+
+		if len(instanceIdentifiers) > 0 || len(clusterIdentifiers) > 0 {
+			c.wg.Add(1)
+			go c.getCloudwatchMetrics(c.cloudWatchClient, instanceIdentifiers, clusterIdentifiers)
+		} else {
+			// Drop metrics of deleted instances and clusters, instance metrics received from metric streams are kept
+			c.mutex.Lock()
+			if c.metricStream == nil {
+				c.metrics.CloudwatchInstances = cloudwatch.CloudWatchMetrics{}
+			}
+			c.metrics.CloudwatchClusters = cloudwatch.CloudWatchClusterMetrics{}
+			c.mutex.Unlock()
+
+			c.forgetSource(SourceCloudwatch)
+		}
 	}
 
 	// Wait for all go routines to finish
 	c.wg.Wait()
 
+	c.pruneSources(cycleStart)
+
 	return nil
 }
 
-func (c *RdsCollector) getCloudwatchMetrics(client cloudwatch.CloudWatchClient, instanceIdentifiers []string) {
+func (c *RdsCollector) getCloudwatchMetrics(client cloudwatch.CloudWatchClient, instanceIdentifiers []cloudwatch.Instance, clusterIdentifiers []string) {
 	defer c.wg.Done()
 	c.logger.Debug("fetch cloudwatch metrics")
 
-	fetcher := cloudwatch.NewRDSFetcher(client, c.logger)
+	fetcher := cloudwatch.NewRDSFetcher(client, c.logger, cloudwatch.Configuration{
+		Lookback:     c.configuration.CloudWatchLookback,
+		MaxStaleness: c.configuration.CloudWatchMaxStaleness,
+	})
+
+	start := time.Now()
+
+	var (
+		fetchErr error
+		failures float64
+	)
+
+	if len(instanceIdentifiers) > 0 {
+		metrics, err := fetcher.GetRDSInstanceMetrics(instanceIdentifiers)
+		if err != nil {
+			failures++
+			fetchErr = err
+		}
+
+		c.mutex.Lock()
+		c.metrics.CloudwatchInstances = metrics
+		c.mutex.Unlock()
+
+		c.logger.Debug("cloudwatch metrics fetched", "metrics", metrics)
+	}
 
-	metrics, err := fetcher.GetRDSInstanceMetrics(instanceIdentifiers)
+	clusterMetrics, err := fetcher.GetRDSClusterMetrics(clusterIdentifiers)
 	if err != nil {
-		c.counters.Errors++
+		failures++
+		fetchErr = err
 	}
 
+	c.recordFetch(SourceCloudwatch, start, fetchErr)
+
+	c.mutex.Lock()
+	c.counters.Errors += failures
 	c.counters.CloudwatchAPICalls += fetcher.GetStatistics().CloudWatchAPICall
+	c.metrics.CloudwatchClusters = clusterMetrics
+	c.mutex.Unlock()
+
+	c.logger.Debug("cloudwatch cluster metrics fetched", "metrics", clusterMetrics)
+}
+
+// getMetricStreamMetrics reads instance metrics received from Cloudwatch metric streams
+func (c *RdsCollector) getMetricStreamMetrics(instances []cloudwatch.Instance) {
+	dbIdentifiers := make([]string, len(instances))
+	for i, instance := range instances {
+		dbIdentifiers[i] = instance.DBIdentifier
+	}
+
+	start := time.Now()
+	metrics := c.metricStream.GetRDSInstanceMetrics(c.awsAccountID, c.awsRegion, dbIdentifiers)
+
+	// Streams may be misconfigured or not delivered yet, instance metrics are missing until the first delivery
+	var err error
+	if len(dbIdentifiers) > 0 && len(metrics.Instances) == 0 {
+		err = errNoMetricStreamData
+	}
+
+	c.recordFetch(SourceMetricStream, start, err)
+
+	c.mutex.Lock()
 	c.metrics.CloudwatchInstances = metrics
+	c.mutex.Unlock()
 
-	c.logger.Debug("cloudwatch metrics fetched", "metrics", metrics)
+	c.logger.Debug("metric stream metrics read", "metrics", metrics)
 }
 
 func (c *RdsCollector) getUsagesMetrics(client cloudwatch.CloudWatchClient) {
@@ -452,10 +820,15 @@ func (c *RdsCollector) getUsagesMetrics(client cloudwatch.CloudWatchClient) {
 
 	fetcher := cloudwatch.NewUsageFetcher(client, c.logger)
 
+	start := time.Now()
 	metrics, err := fetcher.GetUsageMetrics()
+	c.recordFetch(SourceUsage, start, err)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
 	if err != nil {
 		c.counters.Errors++
-		c.logger.Error(fmt.Sprintf("can't fetch usage metrics: %s", err))
 	}
 
 	c.counters.UsageAPIcalls += fetcher.GetStatistics().CloudWatchAPICall
@@ -468,12 +841,20 @@ func (c *RdsCollector) getEC2Metrics(client ec2.EC2Client, instanceTypes []strin
 	defer c.wg.Done()
 	c.logger.Debug("fetch EC2 metrics")
 
-	fetcher := ec2.NewFetcher(client)
+	fetcher := ec2.NewFetcher(client, ec2.Configuration{
+		Catalog: c.instanceClassCatalog,
+		Cache:   c.instanceTypesCache,
+	})
 
+	start := time.Now()
 	metrics, err := fetcher.GetDBInstanceTypeInformation(instanceTypes)
+	c.recordFetch(SourceEC2, start, err)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
 	if err != nil {
 		c.counters.Errors++
-		c.logger.Error(fmt.Sprintf("can't fetch EC2 metrics: %s", err))
 	}
 
 	c.counters.EC2APIcalls += fetcher.GetStatistics().EC2ApiCall
@@ -486,18 +867,50 @@ func (c *RdsCollector) getQuotasMetrics(client servicequotas.ServiceQuotasClient
 	defer c.wg.Done()
 	c.logger.Debug("fetch quotas")
 
-	fetcher := servicequotas.NewFetcher(client)
+	fetcher := servicequotas.NewFetcher(client, c.logger, servicequotas.Configuration{
+		AllowList: c.configuration.QuotasAllowList,
+	})
 
+	start := time.Now()
 	metrics, err := fetcher.GetRDSQuotas()
+	c.recordFetch(SourceServiceQuotas, start, err)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
 	if err != nil {
 		c.counters.Errors++
-		c.logger.Error(fmt.Sprintf("can't fetch service quota metrics: %s", err))
 	}
 
 	c.counters.ServiceQuotasAPICalls += fetcher.GetStatistics().UsageAPICall
 	c.metrics.ServiceQuota = metrics
 }
 
+func (c *RdsCollector) getPerformanceInsightsMetrics(client pi.PIClient, instances map[string]string) {
+	defer c.wg.Done()
+	c.logger.Debug("fetch performance insights metrics")
+
+	fetcher := pi.NewFetcher(client, pi.Configuration{
+		TopSQL: c.configuration.PerformanceInsightsTopSQL,
+	})
+
+	start := time.Now()
+	metrics, err := fetcher.GetInstancesMetrics(instances)
+	c.recordFetch(SourcePerformanceInsights, start, err)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err != nil {
+		c.counters.Errors++
+	}
+
+	c.counters.PerformanceInsightsAPICalls += fetcher.GetStatistics().PIAPICall
+	c.metrics.PerformanceInsights = metrics
+
+	c.logger.Debug("performance insights metrics fetched", "metrics", metrics)
+}
+
 func (c *RdsCollector) getInstanceTagLabels(dbidentifier string, instance rds.RdsInstanceMetrics) (keys []string, values []string) {
 	labels := map[string]string{
 		"aws_account_id": c.awsAccountID,
@@ -522,10 +935,12 @@ func (c *RdsCollector) getInstanceTagLabels(dbidentifier string, instance rds.Rd
 
 func (c *RdsCollector) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(c.exporterBuildInformation, prometheus.GaugeValue, 1, build.Version, build.CommitSHA, build.Date, c.awsRegion)
-	ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, c.counters.Errors, c.awsRegion)
+	ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, c.GetStatistics().Errors, c.awsRegion)
 
 	// Get all metrics
 	err := c.fetchMetrics()
+	c.collectSourceMetrics(ch)
+
 	if err != nil {
 		c.logger.Error(fmt.Sprintf("can't scrape metrics: %s", err))
 		// Mark exporter as down
@@ -534,9 +949,11 @@ func (c *RdsCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, exporterUpStatusCode, c.awsRegion)
 
+	counters, metrics := c.GetStatistics(), c.GetMetrics()
+
 	// RDS metrics
-	ch <- prometheus.MustNewConstMetric(c.apiCall, prometheus.CounterValue, c.counters.RDSAPIcalls, c.awsAccountID, c.awsRegion, "rds")
-	for dbidentifier, instance := range c.metrics.RDS.Instances {
+	ch <- prometheus.MustNewConstMetric(c.apiCall, prometheus.CounterValue, counters.RDSAPIcalls, c.awsAccountID, c.awsRegion, "rds")
+	for dbidentifier, instance := range metrics.RDS.Instances {
 		ch <- prometheus.MustNewConstMetric(
 			c.allocatedStorage,
 			prometheus.GaugeValue,
@@ -566,9 +983,13 @@ func (c *RdsCollector) Collect(ch chan<- prometheus.Metric) {
 			instance.Arn,
 		)
 		ch <- prometheus.MustNewConstMetric(c.maxAllocatedStorage, prometheus.GaugeValue, float64(instance.MaxAllocatedStorage), c.awsAccountID, c.awsRegion, dbidentifier)
-		ch <- prometheus.MustNewConstMetric(c.maxIops, prometheus.GaugeValue, float64(instance.MaxIops), c.awsAccountID, c.awsRegion, dbidentifier)
 		ch <- prometheus.MustNewConstMetric(c.status, prometheus.GaugeValue, float64(instance.Status), c.awsAccountID, c.awsRegion, dbidentifier)
-		ch <- prometheus.MustNewConstMetric(c.storageThroughput, prometheus.GaugeValue, float64(instance.StorageThroughput), c.awsAccountID, c.awsRegion, dbidentifier)
+
+		// Aurora cluster volumes have no storage IOPS and throughput limits
+		if !rds.IsAuroraStorageType(instance.StorageType) {
+			ch <- prometheus.MustNewConstMetric(c.maxIops, prometheus.GaugeValue, float64(instance.MaxIops), c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- prometheus.MustNewConstMetric(c.storageThroughput, prometheus.GaugeValue, float64(instance.StorageThroughput), c.awsAccountID, c.awsRegion, dbidentifier)
+		}
 		ch <- prometheus.MustNewConstMetric(c.backupRetentionPeriod, prometheus.GaugeValue, float64(instance.BackupRetentionPeriod), c.awsAccountID, c.awsRegion, dbidentifier)
 
 		if c.configuration.CollectInstanceTags {
@@ -589,139 +1010,347 @@ func (c *RdsCollector) Collect(ch chan<- prometheus.Metric) {
 		if instance.LogFilesSize != nil {
 			ch <- prometheus.MustNewConstMetric(c.logFilesSize, prometheus.GaugeValue, float64(*instance.LogFilesSize), c.awsAccountID, c.awsRegion, dbidentifier)
 		}
+
+		if instance.ServerlessMinCapacity != nil {
+			ch <- prometheus.MustNewConstMetric(c.serverlessMinCapacity, prometheus.GaugeValue, *instance.ServerlessMinCapacity, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- prometheus.MustNewConstMetric(c.serverlessMinMemory, prometheus.GaugeValue, rds.ServerlessMemory(*instance.ServerlessMinCapacity), c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- prometheus.MustNewConstMetric(c.serverlessMinVCPU, prometheus.GaugeValue, rds.ServerlessVCPU(*instance.ServerlessMinCapacity), c.awsAccountID, c.awsRegion, dbidentifier)
+		}
+
+		if instance.ServerlessMaxCapacity != nil {
+			ch <- prometheus.MustNewConstMetric(c.serverlessMaxCapacity, prometheus.GaugeValue, *instance.ServerlessMaxCapacity, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- prometheus.MustNewConstMetric(c.serverlessMaxMemory, prometheus.GaugeValue, rds.ServerlessMemory(*instance.ServerlessMaxCapacity), c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- prometheus.MustNewConstMetric(c.serverlessMaxVCPU, prometheus.GaugeValue, rds.ServerlessVCPU(*instance.ServerlessMaxCapacity), c.awsAccountID, c.awsRegion, dbidentifier)
+		}
 	}
 
 	// Cloudwatch metrics
-	ch <- prometheus.MustNewConstMetric(c.apiCall, prometheus.CounterValue, c.counters.CloudwatchAPICalls, c.awsAccountID, c.awsRegion, "cloudwatch")
+	ch <- prometheus.MustNewConstMetric(c.apiCall, prometheus.CounterValue, counters.CloudwatchAPICalls, c.awsAccountID, c.awsRegion, "cloudwatch")
+
+	now := time.Now()
+
+	for dbidentifier, instance := range metrics.CloudwatchInstances.Instances {
+		if instance.LatestTimestamp != nil {
+			ch <- prometheus.MustNewConstMetric(c.cloudwatchDatapointAge, prometheus.GaugeValue, time.Since(*instance.LatestTimestamp).Seconds(), c.awsAccountID, c.awsRegion, dbidentifier)
+		}
 
-	for dbidentifier, instance := range c.metrics.CloudwatchInstances.Instances {
 		if instance.DatabaseConnections != nil {
-			ch <- prometheus.MustNewConstMetric(c.databaseConnections, prometheus.GaugeValue, *instance.DatabaseConnections, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.databaseConnections, instance, "DatabaseConnections", *instance.DatabaseConnections, dbidentifier)
 		}
 
 		if instance.FreeStorageSpace != nil {
-			ch <- prometheus.MustNewConstMetric(c.freeStorageSpace, prometheus.GaugeValue, *instance.FreeStorageSpace, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.freeStorageSpace, instance, "FreeStorageSpace", *instance.FreeStorageSpace, dbidentifier)
 		}
 
 		if instance.FreeableMemory != nil {
-			ch <- prometheus.MustNewConstMetric(c.freeableMemory, prometheus.GaugeValue, *instance.FreeableMemory, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.freeableMemory, instance, "FreeableMemory", *instance.FreeableMemory, dbidentifier)
 		}
 
 		if instance.MaximumUsedTransactionIDs != nil {
-			ch <- prometheus.MustNewConstMetric(c.maximumUsedTransactionIDs, prometheus.GaugeValue, *instance.MaximumUsedTransactionIDs, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.maximumUsedTransactionIDs, instance, "MaximumUsedTransactionIDs", *instance.MaximumUsedTransactionIDs, dbidentifier)
 		}
 
 		if instance.ReadThroughput != nil {
-			ch <- prometheus.MustNewConstMetric(c.readThroughput, prometheus.GaugeValue, *instance.ReadThroughput, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.readThroughput, instance, "ReadThroughput", *instance.ReadThroughput, dbidentifier)
 		}
 
 		if instance.ReplicaLag != nil {
-			ch <- prometheus.MustNewConstMetric(c.replicaLag, prometheus.GaugeValue, *instance.ReplicaLag, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.replicaLag, instance, "ReplicaLag", *instance.ReplicaLag, dbidentifier)
 		}
 
 		if instance.ReplicationSlotDiskUsage != nil {
-			ch <- prometheus.MustNewConstMetric(c.replicationSlotDiskUsage, prometheus.GaugeValue, *instance.ReplicationSlotDiskUsage, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.replicationSlotDiskUsage, instance, "ReplicationSlotDiskUsage", *instance.ReplicationSlotDiskUsage, dbidentifier)
 		}
 
 		if instance.SwapUsage != nil {
-			ch <- prometheus.MustNewConstMetric(c.swapUsage, prometheus.GaugeValue, *instance.SwapUsage, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.swapUsage, instance, "SwapUsage", *instance.SwapUsage, dbidentifier)
 		}
 
 		if instance.ReadIOPS != nil {
-			ch <- prometheus.MustNewConstMetric(c.readIOPS, prometheus.GaugeValue, *instance.ReadIOPS, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.readIOPS, instance, "ReadIOPS", *instance.ReadIOPS, dbidentifier)
 		}
 
 		if instance.WriteIOPS != nil {
-			ch <- prometheus.MustNewConstMetric(c.writeIOPS, prometheus.GaugeValue, *instance.WriteIOPS, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.writeIOPS, instance, "WriteIOPS", *instance.WriteIOPS, dbidentifier)
 		}
 
 		if instance.WriteThroughput != nil {
-			ch <- prometheus.MustNewConstMetric(c.writeThroughput, prometheus.GaugeValue, *instance.WriteThroughput, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.writeThroughput, instance, "WriteThroughput", *instance.WriteThroughput, dbidentifier)
 		}
 
 		if instance.TransactionLogsDiskUsage != nil {
-			ch <- prometheus.MustNewConstMetric(c.transactionLogsDiskUsage, prometheus.GaugeValue, *instance.TransactionLogsDiskUsage, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.transactionLogsDiskUsage, instance, "TransactionLogsDiskUsage", *instance.TransactionLogsDiskUsage, dbidentifier)
 		}
 
 		if instance.DBLoad != nil {
-			ch <- prometheus.MustNewConstMetric(c.DBLoad, prometheus.GaugeValue, *instance.DBLoad, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.DBLoad, instance, "DBLoad", *instance.DBLoad, dbidentifier)
 		}
 
 		if instance.CPUUtilization != nil {
-			ch <- prometheus.MustNewConstMetric(c.cpuUtilisation, prometheus.GaugeValue, *instance.CPUUtilization, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.cpuUtilisation, instance, "CPUUtilization", *instance.CPUUtilization, dbidentifier)
 		}
 
 		if instance.DBLoadCPU != nil {
-			ch <- prometheus.MustNewConstMetric(c.dBLoadCPU, prometheus.GaugeValue, *instance.DBLoadCPU, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.dBLoadCPU, instance, "DBLoadCPU", *instance.DBLoadCPU, dbidentifier)
 		}
 
 		if instance.DBLoadNonCPU != nil {
-			ch <- prometheus.MustNewConstMetric(c.dBLoadNonCPU, prometheus.GaugeValue, *instance.DBLoadNonCPU, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.dBLoadNonCPU, instance, "DBLoadNonCPU", *instance.DBLoadNonCPU, dbidentifier)
 		}
 
 		if instance.BufferCacheHitRatio != nil {
-			ch <- prometheus.MustNewConstMetric(c.BufferCacheHitRatio, prometheus.GaugeValue, *instance.BufferCacheHitRatio, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.BufferCacheHitRatio, instance, "BufferCacheHitRatio", *instance.BufferCacheHitRatio, dbidentifier)
 		}
 
 		if instance.Deadlocks != nil {
-			ch <- prometheus.MustNewConstMetric(c.Deadlocks, prometheus.GaugeValue, *instance.Deadlocks, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.Deadlocks, instance, "Deadlocks", *instance.Deadlocks, dbidentifier)
 		}
 
 		if instance.Queries != nil {
-			ch <- prometheus.MustNewConstMetric(c.Queries, prometheus.GaugeValue, *instance.Queries, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.Queries, instance, "Queries", *instance.Queries, dbidentifier)
 		}
 
 		if instance.EngineUptime != nil {
-			ch <- prometheus.MustNewConstMetric(c.EngineUptime, prometheus.GaugeValue, *instance.EngineUptime, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.EngineUptime, instance, "EngineUptime", *instance.EngineUptime, dbidentifier)
 		}
 
 		if instance.SumBinaryLogSize != nil {
-			ch <- prometheus.MustNewConstMetric(c.SumBinaryLogSize, prometheus.GaugeValue, *instance.SumBinaryLogSize, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.SumBinaryLogSize, instance, "SumBinaryLogSize", *instance.SumBinaryLogSize, dbidentifier)
 		}
 
 		if instance.NumBinaryLogFiles != nil {
-			ch <- prometheus.MustNewConstMetric(c.NumBinaryLogFiles, prometheus.GaugeValue, *instance.NumBinaryLogFiles, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.NumBinaryLogFiles, instance, "NumBinaryLogFiles", *instance.NumBinaryLogFiles, dbidentifier)
 		}
 
 		if instance.AuroraBinlogReplicaLag != nil {
-			ch <- prometheus.MustNewConstMetric(c.AuroraBinlogReplicaLag, prometheus.GaugeValue, *instance.AuroraBinlogReplicaLag, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.AuroraBinlogReplicaLag, instance, "AuroraBinlogReplicaLag", *instance.AuroraBinlogReplicaLag, dbidentifier)
 		}
 
 		if instance.BinLogDiskUsage != nil {
-			ch <- prometheus.MustNewConstMetric(c.BinLogDiskUsage, prometheus.GaugeValue, *instance.BinLogDiskUsage, c.awsAccountID, c.awsRegion, dbidentifier)
+			ch <- c.newCloudwatchMetric(c.BinLogDiskUsage, instance, "BinLogDiskUsage", *instance.BinLogDiskUsage, dbidentifier)
+		}
+
+		if instance.ServerlessDatabaseCapacity != nil {
+			ch <- c.newCloudwatchMetric(c.serverlessDatabaseCapacity, instance, "ServerlessDatabaseCapacity", *instance.ServerlessDatabaseCapacity, dbidentifier)
+		}
+
+		if instance.ACUUtilization != nil {
+			ch <- c.newCloudwatchMetric(c.acuUtilization, instance, "ACUUtilization", *instance.ACUUtilization, dbidentifier)
+		}
+
+		if instance.EBSIOBalance != nil {
+			ch <- c.newCloudwatchMetric(c.ebsIOBalance, instance, "EBSIOBalance%", *instance.EBSIOBalance, dbidentifier)
+		}
+
+		if instance.EBSByteBalance != nil {
+			ch <- c.newCloudwatchMetric(c.ebsByteBalance, instance, "EBSByteBalance%", *instance.EBSByteBalance, dbidentifier)
+		}
+
+		if instance.BurstBalance != nil {
+			ch <- c.newCloudwatchMetric(c.burstBalance, instance, "BurstBalance", *instance.BurstBalance, dbidentifier)
+		}
+
+		if instance.CPUCreditBalance != nil {
+			ch <- c.newCloudwatchMetric(c.cpuCreditBalance, instance, "CPUCreditBalance", *instance.CPUCreditBalance, dbidentifier)
+		}
+
+		// Saturation ratios require instance limits from AWS RDS and AWS EC2 APIs
+		rdsInstance, found := metrics.RDS.Instances[dbidentifier]
+		if !found {
+			continue
+		}
+
+		ratios := getUtilizationRatios(rdsInstance, *instance, metrics.EC2.Instances[rdsInstance.DBInstanceClass])
+
+		if ratios.StorageIops != nil {
+			ch <- prometheus.MustNewConstMetric(c.storageIopsUtilization, prometheus.GaugeValue, *ratios.StorageIops, c.awsAccountID, c.awsRegion, dbidentifier)
+		}
+
+		if ratios.StorageThroughput != nil {
+			ch <- prometheus.MustNewConstMetric(c.storageThroughputUtilization, prometheus.GaugeValue, *ratios.StorageThroughput, c.awsAccountID, c.awsRegion, dbidentifier)
+		}
+
+		if ratios.InstanceEBSIops != nil {
+			ch <- prometheus.MustNewConstMetric(c.ebsIopsUtilization, prometheus.GaugeValue, *ratios.InstanceEBSIops, c.awsAccountID, c.awsRegion, dbidentifier)
+		}
+
+		if ratios.InstanceEBSThroughput != nil {
+			ch <- prometheus.MustNewConstMetric(c.ebsThroughputUtilization, prometheus.GaugeValue, *ratios.InstanceEBSThroughput, c.awsAccountID, c.awsRegion, dbidentifier)
+		}
+
+		if ratios.Memory != nil {
+			ch <- prometheus.MustNewConstMetric(c.memoryUtilization, prometheus.GaugeValue, *ratios.Memory, c.awsAccountID, c.awsRegion, dbidentifier)
+		}
+
+		if ratios.Storage != nil {
+			ch <- prometheus.MustNewConstMetric(c.storageUtilization, prometheus.GaugeValue, *ratios.Storage, c.awsAccountID, c.awsRegion, dbidentifier)
+		}
+
+		if c.rightsizing != nil {
+			c.rightsizing.Observe(dbidentifier, rdsInstance.DBInstanceClass, newRightsizingSample(*instance, now))
+		}
+	}
+
+	// Rightsizing recommendations
+	if c.rightsizing != nil {
+		c.rightsizing.Prune(maps.Keys(metrics.RDS.Instances))
+
+		for dbidentifier, instance := range metrics.RDS.Instances {
+			capacity, found := metrics.EC2.Instances[instance.DBInstanceClass]
+			if !found {
+				continue
+			}
+
+			recommendations := c.rightsizing.Recommend(rightsizing.Instance{
+				DBIdentifier:  dbidentifier,
+				Engine:        instance.Engine,
+				InstanceClass: instance.DBInstanceClass,
+				Capacity:      capacity,
+			}, now)
+
+			for _, recommendation := range recommendations {
+				ch <- prometheus.MustNewConstMetric(c.rightsizingRecommendation, prometheus.GaugeValue, 1, c.awsAccountID, c.awsRegion, dbidentifier, recommendation.CurrentClass, recommendation.RecommendedClass, recommendation.Reason)
+			}
+		}
+	}
+
+	// Aurora cluster metrics
+	for dbClusterIdentifier, cluster := range metrics.CloudwatchClusters.Clusters {
+		if cluster.VolumeBytesUsed != nil {
+			ch <- prometheus.MustNewConstMetric(c.clusterVolumeBytesUsed, prometheus.GaugeValue, *cluster.VolumeBytesUsed, c.awsAccountID, c.awsRegion, dbClusterIdentifier)
+		}
+
+		if cluster.VolumeReadIOPs != nil {
+			ch <- prometheus.MustNewConstMetric(c.clusterVolumeReadIOs, prometheus.GaugeValue, *cluster.VolumeReadIOPs, c.awsAccountID, c.awsRegion, dbClusterIdentifier)
+		}
+
+		if cluster.VolumeWriteIOPs != nil {
+			ch <- prometheus.MustNewConstMetric(c.clusterVolumeWriteIOs, prometheus.GaugeValue, *cluster.VolumeWriteIOPs, c.awsAccountID, c.awsRegion, dbClusterIdentifier)
+		}
+
+		if cluster.VolumeBytesUsed != nil && cluster.VolumeReadIOPs != nil && cluster.VolumeWriteIOPs != nil {
+			standard, ioOptimized := rds.AuroraStorageMonthlyCosts(c.configuration.AuroraStoragePricing, *cluster.VolumeBytesUsed, *cluster.VolumeReadIOPs+*cluster.VolumeWriteIOPs)
+			ch <- prometheus.MustNewConstMetric(c.clusterStorageMonthlyCost, prometheus.GaugeValue, standard, c.awsAccountID, c.awsRegion, dbClusterIdentifier, rds.AuroraStandardStorageType)
+			ch <- prometheus.MustNewConstMetric(c.clusterStorageMonthlyCost, prometheus.GaugeValue, ioOptimized, c.awsAccountID, c.awsRegion, dbClusterIdentifier, rds.AuroraIOOptimizedStorageType)
 		}
 	}
 
 	// usage metrics
 	if c.configuration.CollectUsages {
-		ch <- prometheus.MustNewConstMetric(c.apiCall, prometheus.CounterValue, c.counters.UsageAPIcalls, c.awsAccountID, c.awsRegion, "usage")
-		ch <- prometheus.MustNewConstMetric(c.usageAllocatedStorage, prometheus.GaugeValue, c.metrics.CloudWatchUsage.AllocatedStorage, c.awsAccountID, c.awsRegion)
-		ch <- prometheus.MustNewConstMetric(c.usageDBInstances, prometheus.GaugeValue, c.metrics.CloudWatchUsage.DBInstances, c.awsAccountID, c.awsRegion)
-		ch <- prometheus.MustNewConstMetric(c.usageManualSnapshots, prometheus.GaugeValue, c.metrics.CloudWatchUsage.ManualSnapshots, c.awsAccountID, c.awsRegion)
+		ch <- prometheus.MustNewConstMetric(c.apiCall, prometheus.CounterValue, counters.UsageAPIcalls, c.awsAccountID, c.awsRegion, "usage")
+		ch <- prometheus.MustNewConstMetric(c.usageAllocatedStorage, prometheus.GaugeValue, metrics.CloudWatchUsage.AllocatedStorage, c.awsAccountID, c.awsRegion)
+		ch <- prometheus.MustNewConstMetric(c.usageDBInstances, prometheus.GaugeValue, metrics.CloudWatchUsage.DBInstances, c.awsAccountID, c.awsRegion)
+		ch <- prometheus.MustNewConstMetric(c.usageManualSnapshots, prometheus.GaugeValue, metrics.CloudWatchUsage.ManualSnapshots, c.awsAccountID, c.awsRegion)
+
+		for _, usage := range metrics.CloudWatchUsage.Resources {
+			ch <- prometheus.MustNewConstMetric(c.usageResourceCount, prometheus.GaugeValue, usage.Value, c.awsAccountID, c.awsRegion, usage.Resource, usage.Class)
+		}
 	}
 
 	// EC2 metrics
-	ch <- prometheus.MustNewConstMetric(c.apiCall, prometheus.CounterValue, c.counters.EC2APIcalls, c.awsAccountID, c.awsRegion, "ec2")
-	for instanceType, instance := range c.metrics.EC2.Instances {
+	ch <- prometheus.MustNewConstMetric(c.apiCall, prometheus.CounterValue, counters.EC2APIcalls, c.awsAccountID, c.awsRegion, "ec2")
+	for instanceType, instance := range metrics.EC2.Instances {
 		ch <- prometheus.MustNewConstMetric(c.instanceMaximumIops, prometheus.GaugeValue, float64(instance.MaximumIops), c.awsAccountID, c.awsRegion, instanceType)
 		ch <- prometheus.MustNewConstMetric(c.instanceMaximumThroughput, prometheus.GaugeValue, instance.MaximumThroughput, c.awsAccountID, c.awsRegion, instanceType)
+		ch <- prometheus.MustNewConstMetric(c.instanceBaselineIops, prometheus.GaugeValue, float64(instance.BaselineIops), c.awsAccountID, c.awsRegion, instanceType)
+		ch <- prometheus.MustNewConstMetric(c.instanceBaselineThroughput, prometheus.GaugeValue, instance.BaselineThroughput, c.awsAccountID, c.awsRegion, instanceType)
+		ch <- prometheus.MustNewConstMetric(c.instanceBaselineBandwidth, prometheus.GaugeValue, instance.BaselineBandwidth, c.awsAccountID, c.awsRegion, instanceType)
 		ch <- prometheus.MustNewConstMetric(c.instanceMemory, prometheus.GaugeValue, float64(instance.Memory), c.awsAccountID, c.awsRegion, instanceType)
 		ch <- prometheus.MustNewConstMetric(c.instanceVCPU, prometheus.GaugeValue, float64(instance.Vcpu), c.awsAccountID, c.awsRegion, instanceType)
+
+		if instance.NetworkPerformance != "" {
+			ch <- prometheus.MustNewConstMetric(c.instanceNetwork, prometheus.GaugeValue, 1, c.awsAccountID, c.awsRegion, instanceType, instance.NetworkPerformance)
+		}
+	}
+
+	for _, instanceType := range metrics.EC2.Unknown {
+		ch <- prometheus.MustNewConstMetric(c.instanceClassUnknown, prometheus.GaugeValue, 1, c.awsAccountID, c.awsRegion, instanceType)
+	}
+
+	// Performance Insights metrics
+	if c.configuration.CollectPerformanceInsights {
+		ch <- prometheus.MustNewConstMetric(c.apiCall, prometheus.CounterValue, counters.PerformanceInsightsAPICalls, c.awsAccountID, c.awsRegion, "pi")
+
+		for dbidentifier, instance := range metrics.PerformanceInsights.Instances {
+			for _, waitEvent := range instance.WaitEvents {
+				ch <- prometheus.MustNewConstMetric(c.dBLoadWaitEvent, prometheus.GaugeValue, waitEvent.DBLoad, c.awsAccountID, c.awsRegion, dbidentifier, waitEvent.Name, waitEvent.Type)
+			}
+
+			for _, sql := range instance.TopSQL {
+				ch <- prometheus.MustNewConstMetric(c.dBLoadSQL, prometheus.GaugeValue, sql.DBLoad, c.awsAccountID, c.awsRegion, dbidentifier, sql.ID, sql.Statement)
+			}
+		}
 	}
 
 	// serviceQuotas metrics
 	if c.configuration.CollectQuotas {
-		ch <- prometheus.MustNewConstMetric(c.apiCall, prometheus.CounterValue, c.counters.ServiceQuotasAPICalls, c.awsAccountID, c.awsRegion, "servicequotas")
-		ch <- prometheus.MustNewConstMetric(c.quotaDBInstances, prometheus.GaugeValue, c.metrics.ServiceQuota.DBinstances, c.awsAccountID, c.awsRegion)
-		ch <- prometheus.MustNewConstMetric(c.quotaTotalStorage, prometheus.GaugeValue, c.metrics.ServiceQuota.TotalStorage, c.awsAccountID, c.awsRegion)
-		ch <- prometheus.MustNewConstMetric(c.quotaMaxDBInstanceSnapshots, prometheus.GaugeValue, c.metrics.ServiceQuota.ManualDBInstanceSnapshots, c.awsAccountID, c.awsRegion)
+		ch <- prometheus.MustNewConstMetric(c.apiCall, prometheus.CounterValue, counters.ServiceQuotasAPICalls, c.awsAccountID, c.awsRegion, "servicequotas")
+		ch <- prometheus.MustNewConstMetric(c.quotaDBInstances, prometheus.GaugeValue, metrics.ServiceQuota.DBinstances, c.awsAccountID, c.awsRegion)
+		ch <- prometheus.MustNewConstMetric(c.quotaTotalStorage, prometheus.GaugeValue, metrics.ServiceQuota.TotalStorage, c.awsAccountID, c.awsRegion)
+		ch <- prometheus.MustNewConstMetric(c.quotaMaxDBInstanceSnapshots, prometheus.GaugeValue, metrics.ServiceQuota.ManualDBInstanceSnapshots, c.awsAccountID, c.awsRegion)
+
+		usages := getQuotaUsages(metrics.ServiceQuota.Quotas, metrics.RDS.Instances, metrics.CloudWatchUsage, c.configuration.CollectUsages)
+
+		for _, quota := range metrics.ServiceQuota.Quotas {
+			ch <- prometheus.MustNewConstMetric(c.quota, prometheus.GaugeValue, quota.Value, c.awsAccountID, c.awsRegion, quota.Code, quota.Name, quota.Unit)
+
+			if usage, found := usages[quota.Code]; found && quota.Value > 0 {
+				ch <- prometheus.MustNewConstMetric(c.quotaUtilization, prometheus.GaugeValue, usage/quota.Value, c.awsAccountID, c.awsRegion, quota.Code, quota.Name)
+			}
+		}
+	}
+}
+
+// newCloudwatchMetric returns a Cloudwatch instance metric, timestamped with its datapoint timestamp if enabled
+func (c *RdsCollector) newCloudwatchMetric(desc *prometheus.Desc, instance *cloudwatch.RdsMetrics, metricName string, value float64, dbidentifier string) prometheus.Metric {
+	metric := prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, c.awsAccountID, c.awsRegion, dbidentifier)
+
+	if c.configuration.CloudWatchUseTimestamps {
+		if timestamp, found := instance.Timestamps[metricName]; found {
+			return prometheus.NewMetricWithTimestamp(timestamp, metric)
+		}
 	}
+
+	return metric
+}
+
+// SetMetricStream configures the collector to read instance metrics from Cloudwatch metric streams instead of polling Cloudwatch API
+func (c *RdsCollector) SetMetricStream(stream metricStream) {
+	c.metricStream = stream
+}
+
+// SetInstanceClasses configures the instance class catalog used when AWS EC2 API can't describe a class and the cache of AWS EC2 API responses
+func (c *RdsCollector) SetInstanceClasses(catalog ec2.Catalog, cache *ec2.Cache) {
+	c.instanceClassCatalog = catalog
+	c.instanceTypesCache = cache
+}
+
+// SetRightsizing configures the advisor recommending instance classes from observed utilization
+func (c *RdsCollector) SetRightsizing(advisor *rightsizing.Advisor) {
+	c.rightsizing = advisor
+}
+
+// AccountID returns the AWS account ID of the collected instances
+func (c *RdsCollector) AccountID() string {
+	return c.awsAccountID
+}
+
+// Region returns the AWS region of the collected instances
+func (c *RdsCollector) Region() string {
+	return c.awsRegion
 }
 
 func (c *RdsCollector) GetStatistics() Counters {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
 	return c.counters
 }
 
+// GetMetrics returns the metrics of the last fetch, fetches replace metrics instead of modifying them so the result can be read while a fetch is running
 func (c *RdsCollector) GetMetrics() metrics {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
 	return c.metrics
 }
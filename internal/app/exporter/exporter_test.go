@@ -1,19 +1,34 @@
 package exporter_test
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2"
 	"github.com/TeiNam/prometheus-rds-exporter/internal/app/exporter"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/metricstream"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/rightsizing"
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
 	"github.com/TeiNam/prometheus-rds-exporter/internal/infra/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	aws_rds "github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/smithy-go"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	cloudwatch_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/cloudwatch/mock"
 	ec2_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2/mock"
+	pi_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/pi/mock"
 	rds_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/rds/mock"
 	servicequotas_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/servicequotas/mock"
 	converter "github.com/TeiNam/prometheus-rds-exporter/internal/app/unit"
+	aws_cloudwatch_types "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	aws_rds_types "github.com/aws/aws-sdk-go-v2/service/rds/types"
 )
 
@@ -29,18 +44,20 @@ func TestWithAllDisabledCollectors(t *testing.T) {
 	ec2Client := ec2_mock.EC2Client{}
 	cloudWatchClient := cloudwatch_mock.CloudwatchClient{}
 	servicequotasClient := servicequotas_mock.ServiceQuotasClient{}
+	piClient := pi_mock.PIClient{}
 
 	configuration := exporter.Configuration{
-		CollectInstanceMetrics: false,
-		CollectInstanceTypes:   false,
-		CollectInstanceTags:    false,
-		CollectLogsSize:        false,
-		CollectMaintenances:    false,
-		CollectQuotas:          false,
-		CollectUsages:          false,
+		CollectInstanceMetrics:     false,
+		CollectInstanceTypes:       false,
+		CollectInstanceTags:        false,
+		CollectLogsSize:            false,
+		CollectMaintenances:        false,
+		CollectPerformanceInsights: false,
+		CollectQuotas:              false,
+		CollectUsages:              false,
 	}
 
-	collector := exporter.NewCollector(*logger, configuration, awsAccountID, awsRegion, rdsClient, ec2Client, cloudWatchClient, servicequotasClient)
+	collector := exporter.NewCollector(*logger, configuration, awsAccountID, awsRegion, rdsClient, ec2Client, cloudWatchClient, servicequotasClient, piClient)
 
 	testutil.CollectAndCount(collector)
 
@@ -51,6 +68,7 @@ func TestWithAllDisabledCollectors(t *testing.T) {
 	assert.Equal(t, float64(0), counter.ServiceQuotasAPICalls, "should not have any call")
 	assert.Equal(t, float64(0), counter.UsageAPIcalls, "should not have any call")
 	assert.Equal(t, float64(0), counter.CloudwatchAPICalls, "should not have any call")
+	assert.Equal(t, float64(0), counter.PerformanceInsightsAPICalls, "should not have any call")
 }
 
 func TestCollector(t *testing.T) {
@@ -65,18 +83,21 @@ func TestCollector(t *testing.T) {
 	ec2Client := ec2_mock.EC2Client{}
 	cloudWatchClient := cloudwatch_mock.CloudwatchClient{}
 	servicequotasClient := servicequotas_mock.ServiceQuotasClient{}
+	piClient := pi_mock.PIClient{}
 
 	configuration := exporter.Configuration{
-		CollectInstanceMetrics: true,
-		CollectInstanceTypes:   true,
-		CollectInstanceTags:    false,
-		CollectLogsSize:        true,
-		CollectMaintenances:    true,
-		CollectQuotas:          true,
-		CollectUsages:          true,
+		CollectInstanceMetrics:     true,
+		CollectInstanceTypes:       true,
+		CollectInstanceTags:        false,
+		CollectLogsSize:            true,
+		CollectMaintenances:        true,
+		CollectPerformanceInsights: true,
+		CollectQuotas:              true,
+		CollectUsages:              true,
+		PerformanceInsightsTopSQL:  10,
 	}
 
-	collector := exporter.NewCollector(*logger, configuration, awsAccountID, awsRegion, rdsClient, ec2Client, cloudWatchClient, servicequotasClient)
+	collector := exporter.NewCollector(*logger, configuration, awsAccountID, awsRegion, rdsClient, ec2Client, cloudWatchClient, servicequotasClient, piClient)
 
 	testutil.CollectAndCount(collector)
 
@@ -85,9 +106,10 @@ func TestCollector(t *testing.T) {
 	assert.Equal(t, float64(0), counter.Errors, "should not have any error")
 	assert.Equal(t, float64(3), counter.RDSAPIcalls, "should have 1 call to RDS API")
 	assert.Equal(t, float64(1), counter.EC2APIcalls, "should have 1 call to EC2 API")
-	assert.Equal(t, float64(3), counter.ServiceQuotasAPICalls, "should have 1 call to ServiceQuota API")
-	assert.Equal(t, float64(1), counter.UsageAPIcalls, "should have 1 call to UsageAPIcalls API")
+	assert.Equal(t, float64(2), counter.ServiceQuotasAPICalls, "should have 2 calls to ServiceQuota API")
+	assert.Equal(t, float64(2), counter.UsageAPIcalls, "should have 2 calls to UsageAPIcalls API")
 	assert.Equal(t, float64(1), counter.CloudwatchAPICalls, "should have 1 call to CloudWatch API")
+	assert.Equal(t, float64(2), counter.PerformanceInsightsAPICalls, "should have 2 calls to Performance Insights API")
 
 	// Get internal metrics
 	metrics := collector.GetMetrics()
@@ -97,8 +119,539 @@ func TestCollector(t *testing.T) {
 	assert.Equal(t, "postgres", metrics.RDS.Instances[*instanceName].Engine, "Engine should match")
 	assert.Equal(t, "14.9", metrics.RDS.Instances[*instanceName].EngineVersion, "Version should match")
 
+	// Check Performance Insights details
+	assert.Equal(t, pi_mock.TopSQL, metrics.PerformanceInsights.Instances[*instanceName].TopSQL[0], "Top SQL should match")
+
 	// Check serviceQuota information
 	assert.Equal(t, servicequotas_mock.DBinstancesQuota, metrics.ServiceQuota.DBinstances, "DBinstance quota should match")
 	assert.Equal(t, servicequotas_mock.ManualDBInstanceSnapshots, metrics.ServiceQuota.ManualDBInstanceSnapshots, "Manual instance snapshot quota should match")
 	assert.Equal(t, converter.GigaBytesToBytes(servicequotas_mock.TotalStorage), metrics.ServiceQuota.TotalStorage, "TotalStorage quota should match")
 }
+
+func TestStatusPageDuringScrape(t *testing.T) {
+	rdsInstance := rds_mock.NewRdsInstance()
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	logger, _ := logger.New(true, "text")
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput}
+
+	configuration := exporter.Configuration{
+		CollectInstanceMetrics:     true,
+		CollectInstanceTypes:       true,
+		CollectPerformanceInsights: true,
+		CollectQuotas:              true,
+		CollectUsages:              true,
+	}
+
+	collector := exporter.NewCollector(*logger, configuration, "123456789012", "eu-west-3", rdsClient, ec2_mock.EC2Client{}, cloudwatch_mock.CloudwatchClient{}, servicequotas_mock.ServiceQuotasClient{}, pi_mock.PIClient{})
+
+	homepage, err := webserver.NewHomePage("test", "/metrics", func() webserver.Status {
+		counters := collector.GetStatistics()
+
+		var engines []webserver.Count
+		for _, instance := range collector.GetMetrics().RDS.Instances {
+			engines = append(engines, webserver.Count{Name: instance.Engine, Value: 1})
+		}
+
+		return webserver.Status{Regions: []webserver.RegionStatus{{
+			Region:   collector.Region(),
+			Errors:   counters.Errors,
+			APICalls: []webserver.Count{{Name: exporter.SourceRDS, Value: counters.RDSAPIcalls}},
+			Engines:  engines,
+		}}}
+	})
+	require.NoError(t, err)
+
+	server := httptest.NewServer(homepage)
+	defer server.Close()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 5; i++ {
+			testutil.CollectAndCount(collector)
+		}
+	}()
+
+	// Serve the status page while scrapes are running, data races are reported by go test -race
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+
+		response, err := http.Get(server.URL + "/")
+		require.NoError(t, err)
+		response.Body.Close()
+
+		assert.Equal(t, http.StatusOK, response.StatusCode, "status page should be served during scrapes")
+	}
+
+	assert.Equal(t, float64(0), collector.GetStatistics().Errors, "should not have any error")
+}
+
+func TestCollectorWithoutServerlessCapacities(t *testing.T) {
+	rdsInstance := rds_mock.NewRdsInstance()
+	rdsInstance.DBInstanceClass = aws.String("db.serverless")
+	rdsInstance.DBClusterIdentifier = aws.String("cluster1")
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	logger, _ := logger.New(true, "text")
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput, DescribeDBClustersOutputError: errors.New("throttled")}
+
+	collector := exporter.NewCollector(*logger, exporter.Configuration{}, "123456789012", "eu-west-3", rdsClient, ec2_mock.EC2Client{}, cloudwatch_mock.CloudwatchClient{}, servicequotas_mock.ServiceQuotasClient{}, pi_mock.PIClient{})
+
+	gauges := gatherGauges(t, collector, "collector")
+	assert.Equal(t, float64(1), gauges["up"][""], "Exporter should stay up without serverless capacities")
+	assert.Equal(t, float64(1), gauges["rds_exporter_collector_success"][exporter.SourceRDS], "RDS collector should succeed")
+	assert.Contains(t, collector.GetMetrics().RDS.Instances, *rdsInstance.DBInstanceIdentifier, "Instance metrics should be kept")
+	assert.Equal(t, float64(1), collector.GetStatistics().Errors, "Missing serverless capacities should be counted as error")
+}
+
+func TestPerformanceInsightsMetricsDropped(t *testing.T) {
+	rdsInstance := rds_mock.NewRdsInstance()
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	logger, _ := logger.New(true, "text")
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput}
+
+	configuration := exporter.Configuration{CollectPerformanceInsights: true, PerformanceInsightsTopSQL: 10}
+
+	collector := exporter.NewCollector(*logger, configuration, "123456789012", "eu-west-3", rdsClient, ec2_mock.EC2Client{}, cloudwatch_mock.CloudwatchClient{}, servicequotas_mock.ServiceQuotasClient{}, pi_mock.PIClient{})
+
+	testutil.CollectAndCount(collector)
+	require.Contains(t, collector.GetMetrics().PerformanceInsights.Instances, *rdsInstance.DBInstanceIdentifier, "Performance Insights metrics should be collected")
+
+	mockDescribeDBInstancesOutput.DBInstances[0].PerformanceInsightsEnabled = aws.Bool(false)
+
+	testutil.CollectAndCount(collector)
+	assert.Empty(t, collector.GetMetrics().PerformanceInsights.Instances, "Performance Insights metrics should be dropped without Performance Insights instances")
+}
+
+func TestCloudwatchMetricsDropped(t *testing.T) {
+	rdsInstance := rds_mock.NewRdsInstance()
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	logger, _ := logger.New(true, "text")
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput}
+	cloudWatchClient := cloudwatch_mock.CloudwatchClient{Metrics: []aws_cloudwatch_types.MetricDataResult{
+		{Id: aws.String("cpuutilization_0"), Values: []float64{10}},
+	}}
+	configuration := exporter.Configuration{CollectInstanceMetrics: true}
+
+	collector := exporter.NewCollector(*logger, configuration, "123456789012", "eu-west-3", rdsClient, ec2_mock.EC2Client{}, cloudWatchClient, servicequotas_mock.ServiceQuotasClient{}, pi_mock.PIClient{})
+	testutil.CollectAndCount(collector)
+
+	require.Contains(t, collector.GetMetrics().CloudwatchInstances.Instances, *rdsInstance.DBInstanceIdentifier, "Cloudwatch metrics should be fetched")
+	require.Contains(t, collector.SourceStatuses(), exporter.SourceCloudwatch, "Cloudwatch source should be reported")
+
+	// All instances are deleted
+	mockDescribeDBInstancesOutput.DBInstances = nil
+	testutil.CollectAndCount(collector)
+
+	assert.Empty(t, collector.GetMetrics().CloudwatchInstances.Instances, "Cloudwatch metrics of deleted instances should be dropped")
+	assert.NotContains(t, collector.SourceStatuses(), exporter.SourceCloudwatch, "Cloudwatch source should be forgotten")
+}
+
+func TestCollectorWithMetricStream(t *testing.T) {
+	awsAccountID := "123456789012"
+	awsRegion := "eu-west-3"
+
+	rdsInstance := rds_mock.NewRdsInstance()
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	logger, _ := logger.New(true, "text")
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput}
+	ec2Client := ec2_mock.EC2Client{}
+	cloudWatchClient := cloudwatch_mock.CloudwatchClient{}
+	servicequotasClient := servicequotas_mock.ServiceQuotasClient{}
+	piClient := pi_mock.PIClient{}
+
+	store := metricstream.NewStore(0)
+	store.Add(metricstream.Datapoint{
+		AccountID:  awsAccountID,
+		Region:     awsRegion,
+		Namespace:  "AWS/RDS",
+		MetricName: "CPUUtilization",
+		Dimensions: map[string]string{"DBInstanceIdentifier": *rdsInstance.DBInstanceIdentifier},
+		Timestamp:  time.Now(),
+		Sum:        42,
+		Count:      1,
+	})
+
+	configuration := exporter.Configuration{CollectInstanceMetrics: true}
+
+	collector := exporter.NewCollector(*logger, configuration, awsAccountID, awsRegion, rdsClient, ec2Client, cloudWatchClient, servicequotasClient, piClient)
+	collector.SetMetricStream(store)
+
+	testutil.CollectAndCount(collector)
+
+	counter := collector.GetStatistics()
+	assert.Equal(t, float64(0), counter.CloudwatchAPICalls, "should not call CloudWatch API")
+
+	metrics := collector.GetMetrics()
+	assert.Equal(t, aws.Float64(42), metrics.CloudwatchInstances.Instances[*rdsInstance.DBInstanceIdentifier].CPUUtilization, "CPU utilization should come from metric stream")
+
+	require.Contains(t, collector.SourceStatuses(), exporter.SourceMetricStream, "Metric stream should be reported as a source")
+	assert.NoError(t, collector.SourceStatuses()[exporter.SourceMetricStream].LastError, "Metric stream read should succeed")
+
+	// Instances without delivered metrics fail the metric stream source
+	collector = exporter.NewCollector(*logger, configuration, "210987654321", awsRegion, rdsClient, ec2Client, cloudWatchClient, servicequotasClient, piClient)
+	collector.SetMetricStream(store)
+
+	gauges := gatherGauges(t, collector, "collector", "reason")
+	assert.Equal(t, float64(0), gauges["rds_exporter_collector_success"][exporter.SourceMetricStream], "Metric stream source should fail without delivered metrics")
+	assert.Equal(t, float64(1), gauges["rds_exporter_collector_last_error_info"][exporter.SourceMetricStream+"/error"], "Error reason should be exposed")
+}
+
+func TestQuotaUtilization(t *testing.T) {
+	awsAccountID := "123456789012"
+	awsRegion := "eu-west-3"
+
+	primary := rds_mock.NewRdsInstance()
+	replica := rds_mock.NewRdsInstance()
+	replica.ReadReplicaSourceDBInstanceIdentifier = primary.DBInstanceIdentifier
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*primary, *replica}}
+
+	logger, _ := logger.New(true, "text")
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput}
+	ec2Client := ec2_mock.EC2Client{}
+	cloudWatchClient := cloudwatch_mock.CloudwatchClient{}
+	servicequotasClient := servicequotas_mock.ServiceQuotasClient{}
+	piClient := pi_mock.PIClient{}
+
+	configuration := exporter.Configuration{CollectQuotas: true}
+
+	collector := exporter.NewCollector(*logger, configuration, awsAccountID, awsRegion, rdsClient, ec2Client, cloudWatchClient, servicequotasClient, piClient)
+
+	gauges := gatherGauges(t, collector, "quota_code")
+	quotas, ratios := gauges["rds_quota"], gauges["rds_quota_utilization_ratio"]
+
+	assert.Len(t, quotas, 5, "All quotas should be exported")
+	assert.Equal(t, servicequotas_mock.ParameterGroups, quotas[servicequotas_mock.ParameterGroupsQuotaCode], "Parameter groups quota should match")
+	assert.InDelta(t, 2/servicequotas_mock.DBinstancesQuota, ratios["L-7B6409FD"], 0.0001, "DB instances utilization should match")
+	assert.InDelta(t, 1/servicequotas_mock.ReadReplicasPerPrimary, ratios[servicequotas_mock.ReadReplicasQuotaCode], 0.0001, "Read replicas utilization should match")
+	assert.NotContains(t, ratios, servicequotas_mock.ParameterGroupsQuotaCode, "Parameter groups usage can't be derived")
+	assert.NotContains(t, ratios, "L-272F1212", "Manual snapshots usage requires usage collection")
+}
+
+func TestQuotaUtilizationWithUsages(t *testing.T) {
+	awsAccountID := "123456789012"
+	awsRegion := "eu-west-3"
+
+	rdsInstance := rds_mock.NewRdsInstance()
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	parameterGroups := float64(5)
+
+	logger, _ := logger.New(true, "text")
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput}
+	ec2Client := ec2_mock.EC2Client{}
+	cloudWatchClient := cloudwatch_mock.CloudwatchClient{
+		Series: []aws_cloudwatch_types.Metric{
+			{
+				Namespace:  aws.String("AWS/Usage"),
+				MetricName: aws.String("ResourceCount"),
+				Dimensions: []aws_cloudwatch_types.Dimension{
+					{Name: aws.String("Service"), Value: aws.String("RDS")},
+					{Name: aws.String("Type"), Value: aws.String("Resource")},
+					{Name: aws.String("Resource"), Value: aws.String(servicequotas_mock.ParameterGroupsUsageResource)},
+					{Name: aws.String("Class"), Value: aws.String("None")},
+				},
+			},
+		},
+		Metrics: []aws_cloudwatch_types.MetricDataResult{
+			{Id: aws.String("usage_0"), Values: []float64{parameterGroups}},
+		},
+	}
+	servicequotasClient := servicequotas_mock.ServiceQuotasClient{}
+	piClient := pi_mock.PIClient{}
+
+	configuration := exporter.Configuration{CollectQuotas: true, CollectUsages: true}
+
+	collector := exporter.NewCollector(*logger, configuration, awsAccountID, awsRegion, rdsClient, ec2Client, cloudWatchClient, servicequotasClient, piClient)
+
+	ratios := gatherGauges(t, collector, "quota_code")["rds_quota_utilization_ratio"]
+
+	assert.InDelta(t, parameterGroups/servicequotas_mock.ParameterGroups, ratios[servicequotas_mock.ParameterGroupsQuotaCode], 0.0001, "Parameter groups utilization should come from AWS/Usage")
+	assert.InDelta(t, 1/servicequotas_mock.DBinstancesQuota, ratios["L-7B6409FD"], 0.0001, "DB instances utilization should be derived from instances without AWS/Usage series")
+}
+
+func TestUtilizationRatios(t *testing.T) {
+	awsAccountID := "123456789012"
+	awsRegion := "eu-west-3"
+
+	rdsInstance := rds_mock.NewRdsInstance()
+	rdsInstance.DBInstanceClass = aws.String("db.t3.large")
+	rdsInstance.StorageThroughput = aws.Int32(125)
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	logger, _ := logger.New(true, "text")
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput}
+	ec2Client := ec2_mock.EC2Client{}
+	cloudWatchClient := cloudwatch_mock.CloudwatchClient{Metrics: []aws_cloudwatch_types.MetricDataResult{
+		{Id: aws.String("readiops_0"), Values: []float64{1000}},
+		{Id: aws.String("writeiops_0"), Values: []float64{500}},
+		{Id: aws.String("readthroughput_0"), Values: []float64{converter.MegaBytesToBytes(float64(50))}},
+		{Id: aws.String("writethroughput_0"), Values: []float64{converter.MegaBytesToBytes(float64(12.5))}},
+		{Id: aws.String("freeablememory_0"), Values: []float64{converter.MegaBytesToBytes(float64(2))}},
+		{Id: aws.String("freestoragespace_0"), Values: []float64{converter.GigaBytesToBytes(float64(1))}},
+	}}
+	servicequotasClient := servicequotas_mock.ServiceQuotasClient{}
+	piClient := pi_mock.PIClient{}
+
+	configuration := exporter.Configuration{CollectInstanceMetrics: true, CollectInstanceTypes: true}
+
+	collector := exporter.NewCollector(*logger, configuration, awsAccountID, awsRegion, rdsClient, ec2Client, cloudWatchClient, servicequotasClient, piClient)
+
+	gauges := gatherGauges(t, collector)
+
+	assert.InDelta(t, 1500.0/3000, gauges["rds_storage_iops_utilization_ratio"][""], 0.0001, "Storage IOPS limit is lower than instance class maximum IOPS")
+	assert.InDelta(t, 62.5/125, gauges["rds_storage_throughput_utilization_ratio"][""], 0.0001, "Storage throughput limit is lower than instance class maximum throughput")
+	assert.InDelta(t, 1500/float64(ec2_mock.InstanceT3Large.BaselineIops), gauges["rds_instance_ebs_iops_utilization_ratio"][""], 0.0001, "EBS IOPS utilization mismatch")
+	assert.InDelta(t, 62.5/ec2_mock.InstanceT3Large.BaselineThroughput, gauges["rds_instance_ebs_throughput_utilization_ratio"][""], 0.0001, "EBS throughput utilization mismatch")
+	assert.InDelta(t, 0.75, gauges["rds_memory_utilization_ratio"][""], 0.0001, "Memory utilization mismatch")
+	assert.InDelta(t, 0.8, gauges["rds_storage_utilization_ratio"][""], 0.0001, "Storage utilization mismatch")
+}
+
+func TestUtilizationRatiosOfAuroraInstance(t *testing.T) {
+	awsAccountID := "123456789012"
+	awsRegion := "eu-west-3"
+
+	rdsInstance := rds_mock.NewRdsInstance()
+	rdsInstance.DBInstanceClass = aws.String("db.t3.large")
+	rdsInstance.StorageType = aws.String("aurora")
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	logger, _ := logger.New(true, "text")
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput}
+	ec2Client := ec2_mock.EC2Client{}
+	cloudWatchClient := cloudwatch_mock.CloudwatchClient{Metrics: []aws_cloudwatch_types.MetricDataResult{
+		{Id: aws.String("readiops_0"), Values: []float64{1000}},
+		{Id: aws.String("writeiops_0"), Values: []float64{570}},
+		{Id: aws.String("freestoragespace_0"), Values: []float64{converter.GigaBytesToBytes(float64(1))}},
+	}}
+	servicequotasClient := servicequotas_mock.ServiceQuotasClient{}
+	piClient := pi_mock.PIClient{}
+
+	configuration := exporter.Configuration{CollectInstanceMetrics: true, CollectInstanceTypes: true}
+
+	collector := exporter.NewCollector(*logger, configuration, awsAccountID, awsRegion, rdsClient, ec2Client, cloudWatchClient, servicequotasClient, piClient)
+
+	gauges := gatherGauges(t, collector)
+
+	assert.InDelta(t, 1570/float64(ec2_mock.InstanceT3Large.MaximumIops), gauges["rds_storage_iops_utilization_ratio"][""], 0.0001, "Only instance class limit applies to Aurora")
+	assert.NotContains(t, gauges, "rds_storage_utilization_ratio", "Aurora cluster volume has no allocated storage")
+}
+
+func TestRightsizingRecommendation(t *testing.T) {
+	awsAccountID := "123456789012"
+	awsRegion := "eu-west-3"
+
+	rdsInstance := rds_mock.NewRdsInstance()
+	rdsInstance.DBInstanceClass = aws.String("db.t3.large")
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	logger, _ := logger.New(true, "text")
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput}
+	ec2Client := ec2_mock.EC2Client{}
+	cloudWatchClient := cloudwatch_mock.CloudwatchClient{Metrics: []aws_cloudwatch_types.MetricDataResult{
+		{Id: aws.String("cpuutilization_0"), Values: []float64{95}},
+	}}
+	servicequotasClient := servicequotas_mock.ServiceQuotasClient{}
+	piClient := pi_mock.PIClient{}
+
+	catalog, err := ec2.DefaultCatalog()
+	require.NoError(t, err, "Embedded catalog must be valid")
+
+	configuration := exporter.Configuration{CollectInstanceMetrics: true, CollectInstanceTypes: true}
+
+	collector := exporter.NewCollector(*logger, configuration, awsAccountID, awsRegion, rdsClient, ec2Client, cloudWatchClient, servicequotasClient, piClient)
+	collector.SetRightsizing(rightsizing.NewAdvisor(rightsizing.Configuration{
+		Window:     time.Hour,
+		Resolution: rightsizing.DefaultResolution,
+		MinSamples: 1,
+		Threshold:  0.8,
+	}, catalog))
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	families, err := registry.Gather()
+	require.NoError(t, err, "Gather must succeed")
+
+	var recommendedClasses []string
+
+	for _, family := range families {
+		if family.GetName() != "rds_rightsizing_recommendation" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "recommended_class" {
+					recommendedClasses = append(recommendedClasses, label.GetValue())
+				}
+			}
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"db.t3.xlarge", "db.t4g.xlarge"}, recommendedClasses, "Saturated instance should be resized in the same family or its Graviton sibling")
+}
+
+func TestSourceStatuses(t *testing.T) {
+	rdsInstance := rds_mock.NewRdsInstance()
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	logger, _ := logger.New(true, "text")
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput}
+
+	configuration := exporter.Configuration{
+		CollectInstanceMetrics: true,
+		CollectInstanceTypes:   true,
+		CollectMaintenances:    true,
+		CollectQuotas:          true,
+	}
+
+	collector := exporter.NewCollector(*logger, configuration, "123456789012", "eu-west-3", rdsClient, ec2_mock.EC2Client{}, cloudwatch_mock.CloudwatchClient{}, servicequotas_mock.ServiceQuotasClient{}, pi_mock.PIClient{})
+
+	assert.Empty(t, collector.SourceStatuses(), "Sources should not be fetched before first scrape")
+
+	testutil.CollectAndCount(collector)
+
+	statuses := collector.SourceStatuses()
+	for _, source := range []string{exporter.SourceRDS, exporter.SourceCloudwatch, exporter.SourceEC2, exporter.SourceServiceQuotas} {
+		require.Contains(t, statuses, source, "Source should be fetched")
+		assert.NoError(t, statuses[source].LastError, "Source fetch should succeed")
+		assert.False(t, statuses[source].LastSuccess.IsZero(), "Source should have a successful fetch")
+	}
+
+	assert.NotContains(t, statuses, exporter.SourceUsage, "Disabled sources should not be fetched")
+
+	rdsClient.Error = errors.New("access denied")
+	collector = exporter.NewCollector(*logger, configuration, "123456789012", "eu-west-3", rdsClient, ec2_mock.EC2Client{}, cloudwatch_mock.CloudwatchClient{}, servicequotas_mock.ServiceQuotasClient{}, pi_mock.PIClient{})
+
+	testutil.CollectAndCount(collector)
+
+	status := collector.SourceStatuses()[exporter.SourceRDS]
+	assert.Error(t, status.LastError, "RDS fetch should fail")
+	assert.True(t, status.LastSuccess.IsZero(), "RDS should have no successful fetch")
+}
+
+func TestSourceStatusesPruned(t *testing.T) {
+	rdsInstance := rds_mock.NewRdsInstance()
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	logger, _ := logger.New(true, "text")
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput}
+
+	configuration := exporter.Configuration{CollectPerformanceInsights: true, PerformanceInsightsTopSQL: 10}
+
+	collector := exporter.NewCollector(*logger, configuration, "123456789012", "eu-west-3", rdsClient, ec2_mock.EC2Client{}, cloudwatch_mock.CloudwatchClient{}, servicequotas_mock.ServiceQuotasClient{}, pi_mock.PIClient{})
+
+	mockDescribeDBInstancesOutput.DBInstances[0].DbiResourceId = aws.String(pi_mock.FailingResourceID)
+
+	testutil.CollectAndCount(collector)
+	require.Contains(t, collector.SourceStatuses(), exporter.SourcePerformanceInsights, "Performance Insights should be fetched")
+	assert.Error(t, collector.SourceStatuses()[exporter.SourcePerformanceInsights].LastError, "Performance Insights fetch should fail")
+
+	mockDescribeDBInstancesOutput.DBInstances[0].PerformanceInsightsEnabled = aws.Bool(false)
+
+	testutil.CollectAndCount(collector)
+	assert.NotContains(t, collector.SourceStatuses(), exporter.SourcePerformanceInsights, "Sources not fetched anymore should be forgotten")
+	assert.Contains(t, collector.SourceStatuses(), exporter.SourceRDS, "Fetched sources should be kept")
+}
+
+func TestFetchErrors(t *testing.T) {
+	rdsInstance := rds_mock.NewRdsInstance()
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	logger, _ := logger.New(true, "text")
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput, Error: errors.New("access denied")}
+
+	configuration := exporter.Configuration{CollectMaintenances: true}
+
+	collector := exporter.NewCollector(*logger, configuration, "123456789012", "eu-west-3", rdsClient, ec2_mock.EC2Client{}, cloudwatch_mock.CloudwatchClient{}, servicequotas_mock.ServiceQuotasClient{}, pi_mock.PIClient{})
+
+	start := time.Now()
+	testutil.CollectAndCount(collector)
+
+	errs := collector.FetchErrors(start)
+	require.Len(t, errs, 1, "RDS fetch should fail")
+	assert.ErrorContains(t, errs[0], "access denied", "Error should contain the RDS error")
+
+	assert.Empty(t, collector.FetchErrors(time.Now()), "Errors before since should be ignored")
+}
+
+func TestCollectorMetrics(t *testing.T) {
+	rdsInstance := rds_mock.NewRdsInstance()
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	logger, _ := logger.New(true, "text")
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput}
+
+	configuration := exporter.Configuration{
+		CollectInstanceTypes: true,
+		CollectMaintenances:  true,
+	}
+
+	collector := exporter.NewCollector(*logger, configuration, "123456789012", "eu-west-3", rdsClient, ec2_mock.EC2Client{}, cloudwatch_mock.CloudwatchClient{}, servicequotas_mock.ServiceQuotasClient{}, pi_mock.PIClient{})
+
+	gauges := gatherGauges(t, collector, "collector", "reason")
+	assert.Equal(t, map[string]float64{exporter.SourceRDS: 1, exporter.SourceEC2: 1}, gauges["rds_exporter_collector_success"], "Enabled collectors should succeed")
+	assert.Contains(t, gauges["rds_exporter_collector_duration_seconds"], exporter.SourceRDS, "RDS fetch duration should be exposed")
+	assert.Empty(t, gauges["rds_exporter_collector_last_error_info"], "Successful collectors should have no error")
+
+	rdsClient.Error = &smithy.GenericAPIError{Code: "AccessDenied", Message: "not authorized"}
+	collector = exporter.NewCollector(*logger, configuration, "123456789012", "eu-west-3", rdsClient, ec2_mock.EC2Client{}, cloudwatch_mock.CloudwatchClient{}, servicequotas_mock.ServiceQuotasClient{}, pi_mock.PIClient{})
+
+	gauges = gatherGauges(t, collector, "collector", "reason")
+	assert.Equal(t, float64(0), gauges["rds_exporter_collector_success"][exporter.SourceRDS], "RDS collector should fail")
+	assert.Equal(t, map[string]float64{exporter.SourceRDS + "/access_denied": 1}, gauges["rds_exporter_collector_last_error_info"], "Error reason should be exposed")
+}
+
+// gatherGauges scrapes the collector once and returns gauge values indexed by metric name, then by values of keyLabels joined with "/"
+// Gauges without any of keyLabels, like gauges of a single instance, are indexed by an empty key
+func gatherGauges(t *testing.T, collector *exporter.RdsCollector, keyLabels ...string) map[string]map[string]float64 {
+	t.Helper()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	families, err := registry.Gather()
+	require.NoError(t, err, "Gather must succeed")
+
+	gauges := make(map[string]map[string]float64)
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if metric.GetGauge() == nil {
+				continue
+			}
+
+			labels := make(map[string]string, len(metric.GetLabel()))
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			var key []string
+
+			for _, name := range keyLabels {
+				if value, found := labels[name]; found {
+					key = append(key, value)
+				}
+			}
+
+			if gauges[family.GetName()] == nil {
+				gauges[family.GetName()] = make(map[string]float64)
+			}
+
+			gauges[family.GetName()][strings.Join(key, "/")] = metric.GetGauge().GetValue()
+		}
+	}
+
+	return gauges
+}
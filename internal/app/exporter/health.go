@@ -0,0 +1,129 @@
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/awserror"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Data sources of the collector, named like the api label of rds_api_call_total metric
+const (
+	SourceCloudwatch          = "cloudwatch"
+	SourceEC2                 = "ec2"
+	SourceMetricStream        = "metricstream" // Cloudwatch metric streams received by the exporter, no AWS API is called
+	SourcePerformanceInsights = "pi"
+	SourceRDS                 = "rds"
+	SourceServiceQuotas       = "servicequotas"
+	SourceUsage               = "usage"
+)
+
+// SourceStatus contains the results of the last fetches of a data source
+type SourceStatus struct {
+	LastSuccess   time.Time // Zero if the source has never been fetched successfully
+	LastError     error     // Error of the last fetch, nil if the last fetch succeeded
+	LastErrorTime time.Time
+	LastDuration  time.Duration // Duration of the last fetch
+}
+
+// recordFetch records the result of a fetch of source started at start, errors are logged with their reason
+func (c *RdsCollector) recordFetch(source string, start time.Time, err error) {
+	if err != nil {
+		c.logger.Error("can't fetch metrics", "collector", source, "reason", getErrorReason(err), "error", err)
+	}
+
+	c.sourcesMutex.Lock()
+	defer c.sourcesMutex.Unlock()
+
+	status := c.sources[source]
+	status.LastDuration = time.Since(start)
+
+	if err != nil {
+		status.LastError = err
+		status.LastErrorTime = time.Now()
+	} else {
+		status.LastError = nil
+		status.LastSuccess = time.Now()
+	}
+
+	c.sources[source] = status
+}
+
+// pruneSources forgets data sources not fetched since start, like Performance Insights once no instance has it enabled
+func (c *RdsCollector) pruneSources(start time.Time) {
+	c.sourcesMutex.Lock()
+	defer c.sourcesMutex.Unlock()
+
+	for source, status := range c.sources {
+		if status.LastSuccess.Before(start) && status.LastErrorTime.Before(start) {
+			delete(c.sources, source)
+		}
+	}
+}
+
+// forgetSource removes the status of a source that is no longer fetched
+func (c *RdsCollector) forgetSource(source string) {
+	c.sourcesMutex.Lock()
+	defer c.sourcesMutex.Unlock()
+
+	delete(c.sources, source)
+}
+
+// SourceStatuses returns the status of fetched data sources, indexed by source name
+func (c *RdsCollector) SourceStatuses() map[string]SourceStatus {
+	c.sourcesMutex.Lock()
+	defer c.sourcesMutex.Unlock()
+
+	statuses := make(map[string]SourceStatus, len(c.sources))
+	for source, status := range c.sources {
+		statuses[source] = status
+	}
+
+	return statuses
+}
+
+// FetchErrors returns errors of data sources that failed since the given time, sorted by source name
+func (c *RdsCollector) FetchErrors(since time.Time) []error {
+	statuses := c.SourceStatuses()
+
+	sources := make([]string, 0, len(statuses))
+	for source := range statuses {
+		sources = append(sources, source)
+	}
+
+	sort.Strings(sources)
+
+	var errs []error
+
+	for _, source := range sources {
+		status := statuses[source]
+		if status.LastError != nil && !status.LastErrorTime.Before(since) {
+			errs = append(errs, fmt.Errorf("can't fetch %s metrics: %w", source, status.LastError))
+		}
+	}
+
+	return errs
+}
+
+// getErrorReason returns the category of a fetch error (eg. access_denied, throttling), with a bounded set of values to be used as label
+func getErrorReason(err error) string {
+	return string(awserror.Classify(err))
+}
+
+// collectSourceMetrics sends duration, success and last error reason of the last fetch of each data source
+func (c *RdsCollector) collectSourceMetrics(ch chan<- prometheus.Metric) {
+	for source, status := range c.SourceStatuses() {
+		ch <- prometheus.MustNewConstMetric(c.collectorDuration, prometheus.GaugeValue, status.LastDuration.Seconds(), c.awsAccountID, c.awsRegion, source)
+
+		success := 1.0
+		if status.LastError != nil {
+			success = 0
+
+			ch <- prometheus.MustNewConstMetric(c.collectorLastError, prometheus.GaugeValue, 1, c.awsAccountID, c.awsRegion, source, getErrorReason(status.LastError))
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.collectorSuccess, prometheus.GaugeValue, success, c.awsAccountID, c.awsRegion, source)
+	}
+}
@@ -2,31 +2,199 @@ package exporter
 
 import (
 	"regexp"
+	"time"
 
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/cloudwatch"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2"
 	"github.com/TeiNam/prometheus-rds-exporter/internal/app/rds"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/rightsizing"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/servicequotas"
+	converter "github.com/TeiNam/prometheus-rds-exporter/internal/app/unit"
 	"golang.org/x/exp/slices"
 )
 
-func getUniqTypeAndIdentifiers(instances map[string]rds.RdsInstanceMetrics) ([]string, []string) {
+// getUniqTypeAndIdentifiers returns instances to query in Cloudwatch and uniq instance types to query in EC2
+// Aurora Serverless v2 class is excluded from instance types since it's not an EC2 instance type
+func getUniqTypeAndIdentifiers(instances map[string]rds.RdsInstanceMetrics) ([]cloudwatch.Instance, []string) {
 	var (
 		instanceTypes       []string
-		instanceIdentifiers []string
+		instanceIdentifiers []cloudwatch.Instance
 	)
 
 	for dbinstanceName := range instances {
 		instanceClass := instances[dbinstanceName].DBInstanceClass
-		if !slices.Contains(instanceTypes, instanceClass) {
+		serverless := rds.IsServerlessInstanceClass(instanceClass)
+
+		if !serverless && !slices.Contains(instanceTypes, instanceClass) {
 			instanceTypes = append(instanceTypes, instanceClass)
 		}
 
-		if !slices.Contains(instanceIdentifiers, dbinstanceName) {
-			instanceIdentifiers = append(instanceIdentifiers, dbinstanceName)
-		}
+		instanceIdentifiers = append(instanceIdentifiers, cloudwatch.Instance{
+			DBIdentifier:     dbinstanceName,
+			Serverless:       serverless,
+			BurstableCPU:     rds.IsBurstableInstanceClass(instanceClass),
+			BurstableStorage: rds.IsBurstableStorageType(instances[dbinstanceName].StorageType),
+		})
 	}
 
 	return instanceIdentifiers, instanceTypes
 }
 
+// getAuroraClusterIdentifiers returns uniq identifiers of clusters having instances using Aurora storage
+func getAuroraClusterIdentifiers(instances map[string]rds.RdsInstanceMetrics) []string {
+	var clusterIdentifiers []string
+
+	for _, instance := range instances {
+		if instance.DBClusterIdentifier == "" || !rds.IsAuroraStorageType(instance.StorageType) {
+			continue
+		}
+
+		if !slices.Contains(clusterIdentifiers, instance.DBClusterIdentifier) {
+			clusterIdentifiers = append(clusterIdentifiers, instance.DBClusterIdentifier)
+		}
+	}
+
+	return clusterIdentifiers
+}
+
+// getPerformanceInsightsInstances returns DbiResourceId of instances having Performance Insights enabled
+func getPerformanceInsightsInstances(instances map[string]rds.RdsInstanceMetrics) map[string]string {
+	resources := make(map[string]string)
+
+	for dbinstanceName, instance := range instances {
+		if instance.PerformanceInsightsEnabled {
+			resources[dbinstanceName] = instance.DbiResourceID
+		}
+	}
+
+	return resources
+}
+
+// getQuotaUsages returns usage of quotas that can be derived from collected metrics, indexed by quota code
+// Usages published in AWS/Usage namespace take precedence over usages derived from instances
+func getQuotaUsages(quotas []servicequotas.Quota, instances map[string]rds.RdsInstanceMetrics, usages cloudwatch.UsageMetrics, usagesCollected bool) map[string]float64 {
+	var allocatedStorage int64
+
+	replicas := make(map[string]float64)
+
+	for _, instance := range instances {
+		// Aurora cluster volumes don't count in the total storage quota
+		if !rds.IsAuroraStorageType(instance.StorageType) {
+			allocatedStorage += instance.AllocatedStorage
+		}
+
+		if instance.SourceDBInstanceIdentifier != "" {
+			replicas[instance.SourceDBInstanceIdentifier]++
+		}
+	}
+
+	var maxReplicas float64
+
+	for _, count := range replicas {
+		maxReplicas = max(maxReplicas, count)
+	}
+
+	result := map[string]float64{
+		servicequotas.DBinstancesQuotacode:            float64(len(instances)),
+		servicequotas.TotalStorageQuotaCode:           float64(allocatedStorage) / converter.GigaBytesToBytes(float64(1)), // Quota is expressed in GiB
+		servicequotas.ReadReplicasPerPrimaryQuotaCode: maxReplicas,
+	}
+
+	for _, quota := range quotas {
+		if !usagesCollected || quota.UsageResource == "" {
+			continue
+		}
+
+		if usage, found := usages.Get(quota.UsageResource, quota.UsageClass); found {
+			result[quota.Code] = usage
+		}
+	}
+
+	return result
+}
+
+// utilizationRatios contains saturation ratios of an instance, ratios are nil when usage or limit is unknown
+type utilizationRatios struct {
+	StorageIops           *float64
+	StorageThroughput     *float64
+	InstanceEBSIops       *float64
+	InstanceEBSThroughput *float64
+	Memory                *float64
+	Storage               *float64
+}
+
+// getUtilizationRatios returns saturation ratios of an instance from its Cloudwatch metrics and the limits of its storage and instance class
+// Storage limits are the lowest of storage and instance class limits, Aurora cluster volumes have no storage limit so only instance class limits apply
+func getUtilizationRatios(instance rds.RdsInstanceMetrics, usage cloudwatch.RdsMetrics, instanceClass ec2.EC2InstanceMetrics) utilizationRatios {
+	var ratios utilizationRatios
+
+	if usage.ReadIOPS != nil && usage.WriteIOPS != nil {
+		iops := *usage.ReadIOPS + *usage.WriteIOPS
+
+		ratios.StorageIops = ratio(iops, effectiveLimit(float64(instance.MaxIops), float64(instanceClass.MaximumIops)))
+		ratios.InstanceEBSIops = ratio(iops, float64(instanceClass.BaselineIops))
+	}
+
+	if usage.ReadThroughput != nil && usage.WriteThroughput != nil {
+		throughput := *usage.ReadThroughput + *usage.WriteThroughput
+
+		ratios.StorageThroughput = ratio(throughput, effectiveLimit(float64(instance.StorageThroughput), instanceClass.MaximumThroughput))
+		ratios.InstanceEBSThroughput = ratio(throughput, instanceClass.BaselineThroughput)
+	}
+
+	if usage.FreeableMemory != nil {
+		ratios.Memory = ratio(float64(instanceClass.Memory)-*usage.FreeableMemory, float64(instanceClass.Memory))
+	}
+
+	// Aurora instances report local storage in FreeStorageSpace, which is not related to the allocated storage
+	if usage.FreeStorageSpace != nil && !rds.IsAuroraStorageType(instance.StorageType) {
+		ratios.Storage = ratio(float64(instance.AllocatedStorage)-*usage.FreeStorageSpace, float64(instance.AllocatedStorage))
+	}
+
+	return ratios
+}
+
+// effectiveLimit returns the lowest known limit, 0 means unknown or unlimited
+func effectiveLimit(limits ...float64) float64 {
+	var result float64
+
+	for _, limit := range limits {
+		if limit > 0 && (result == 0 || limit < result) {
+			result = limit
+		}
+	}
+
+	return result
+}
+
+// ratio returns usage divided by limit or nil if limit is unknown
+func ratio(usage float64, limit float64) *float64 {
+	if limit <= 0 {
+		return nil
+	}
+
+	result := usage / limit
+
+	return &result
+}
+
+// newRightsizingSample returns the utilization of an instance observed in its Cloudwatch metrics
+func newRightsizingSample(metrics cloudwatch.RdsMetrics, now time.Time) rightsizing.Sample {
+	sample := rightsizing.Sample{
+		Timestamp:           now,
+		CPUUtilization:      metrics.CPUUtilization,
+		SwapUsage:           metrics.SwapUsage,
+		DatabaseConnections: metrics.DatabaseConnections,
+	}
+
+	if metrics.ReadIOPS != nil && metrics.WriteIOPS != nil {
+		iops := *metrics.ReadIOPS + *metrics.WriteIOPS
+		sample.IOPS = &iops
+	}
+
+	return sample
+}
+
 func ClearPrometheusLabel(str string) string {
 	// Prometheus metric names may contain ASCII letters, digits, underscores, and colons.
 	// https://prometheus.io/docs/concepts/data_model/#metric-names-and-labels
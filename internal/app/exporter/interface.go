@@ -3,8 +3,10 @@ package exporter
 import (
 	"context"
 
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/cloudwatch"
 	aws_cloudwatch "github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	aws_ec2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	aws_pi "github.com/aws/aws-sdk-go-v2/service/pi"
 	aws_rds "github.com/aws/aws-sdk-go-v2/service/rds"
 	aws_servicequotas "github.com/aws/aws-sdk-go-v2/service/servicequotas"
 )
@@ -13,6 +15,7 @@ type rdsClient interface {
 	DescribeDBInstances(context.Context, *aws_rds.DescribeDBInstancesInput, ...func(*aws_rds.Options)) (*aws_rds.DescribeDBInstancesOutput, error)
 	DescribePendingMaintenanceActions(context.Context, *aws_rds.DescribePendingMaintenanceActionsInput, ...func(*aws_rds.Options)) (*aws_rds.DescribePendingMaintenanceActionsOutput, error)
 	DescribeDBLogFiles(context.Context, *aws_rds.DescribeDBLogFilesInput, ...func(*aws_rds.Options)) (*aws_rds.DescribeDBLogFilesOutput, error)
+	DescribeDBClusters(context.Context, *aws_rds.DescribeDBClustersInput, ...func(*aws_rds.Options)) (*aws_rds.DescribeDBClustersOutput, error)
 }
 
 type EC2Client interface {
@@ -21,8 +24,19 @@ type EC2Client interface {
 
 type cloudWatchClient interface {
 	GetMetricData(context.Context, *aws_cloudwatch.GetMetricDataInput, ...func(*aws_cloudwatch.Options)) (*aws_cloudwatch.GetMetricDataOutput, error)
+	ListMetrics(context.Context, *aws_cloudwatch.ListMetricsInput, ...func(*aws_cloudwatch.Options)) (*aws_cloudwatch.ListMetricsOutput, error)
 }
 
 type servicequotasClient interface {
-	GetServiceQuota(context.Context, *aws_servicequotas.GetServiceQuotaInput, ...func(*aws_servicequotas.Options)) (*aws_servicequotas.GetServiceQuotaOutput, error)
+	ListServiceQuotas(context.Context, *aws_servicequotas.ListServiceQuotasInput, ...func(*aws_servicequotas.Options)) (*aws_servicequotas.ListServiceQuotasOutput, error)
+	ListAWSDefaultServiceQuotas(context.Context, *aws_servicequotas.ListAWSDefaultServiceQuotasInput, ...func(*aws_servicequotas.Options)) (*aws_servicequotas.ListAWSDefaultServiceQuotasOutput, error)
+}
+
+type piClient interface {
+	GetResourceMetrics(context.Context, *aws_pi.GetResourceMetricsInput, ...func(*aws_pi.Options)) (*aws_pi.GetResourceMetricsOutput, error)
+	DescribeDimensionKeys(context.Context, *aws_pi.DescribeDimensionKeysInput, ...func(*aws_pi.Options)) (*aws_pi.DescribeDimensionKeysOutput, error)
+}
+
+type metricStream interface {
+	GetRDSInstanceMetrics(awsAccountID string, awsRegion string, dbIdentifiers []string) cloudwatch.CloudWatchMetrics
 }
@@ -0,0 +1,219 @@
+package exporter
+
+import (
+	"sort"
+	"time"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/cloudwatch"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/rds"
+)
+
+// InventoryInstance contains data of an instance fetched by the last refresh of its collector
+// It's served by the inventory API, collector data is mapped on dedicated types so internal changes don't alter the API
+type InventoryInstance struct {
+	AccountID     string                  `json:"account_id"`
+	Region        string                  `json:"region"`
+	DBIdentifier  string                  `json:"dbidentifier"`
+	RDS           InventoryRDS            `json:"rds"`
+	Cloudwatch    *InventoryCloudwatch    `json:"cloudwatch,omitempty"`
+	InstanceClass *InventoryInstanceClass `json:"instance_class,omitempty"`
+}
+
+// InventoryRDS contains the instance configuration returned by AWS RDS API
+type InventoryRDS struct {
+	ARN                              string            `json:"arn"`
+	Engine                           string            `json:"engine"`
+	EngineVersion                    string            `json:"engine_version"`
+	InstanceClass                    string            `json:"instance_class"`
+	DBClusterIdentifier              string            `json:"db_cluster_identifier,omitempty"`
+	DbiResourceID                    string            `json:"dbi_resource_id"`
+	Role                             string            `json:"role"`
+	SourceDBIdentifier               string            `json:"source_dbidentifier,omitempty"`
+	Status                           string            `json:"status"`
+	EndpointAddress                  string            `json:"endpoint_address,omitempty"`
+	EndpointPort                     int32             `json:"endpoint_port,omitempty"`
+	StorageType                      string            `json:"storage_type"`
+	AllocatedStorageBytes            int64             `json:"allocated_storage_bytes"`
+	MaxAllocatedStorageBytes         int64             `json:"max_allocated_storage_bytes"`
+	MaxIops                          int64             `json:"max_iops"`
+	StorageThroughputBytes           int64             `json:"storage_throughput_bytes"`
+	LogFilesSizeBytes                *int64            `json:"log_files_size_bytes,omitempty"`
+	PendingMaintenance               string            `json:"pending_maintenance"`
+	PendingModifiedValues            bool              `json:"pending_modified_values"`
+	BackupRetentionPeriodSeconds     int32             `json:"backup_retention_period_seconds"`
+	DeletionProtection               bool              `json:"deletion_protection"`
+	PubliclyAccessible               bool              `json:"publicly_accessible"`
+	PerformanceInsightsEnabled       bool              `json:"performance_insights_enabled"`
+	MultiAZ                          bool              `json:"multi_az"`
+	IAMDatabaseAuthenticationEnabled bool              `json:"iam_database_authentication_enabled"`
+	CACertificateIdentifier          string            `json:"ca_certificate_identifier"`
+	CertificateValidTill             *time.Time        `json:"certificate_valid_till,omitempty"`
+	AgeSeconds                       *float64          `json:"age_seconds,omitempty"`
+	ServerlessMinCapacity            *float64          `json:"serverless_min_capacity,omitempty"`
+	ServerlessMaxCapacity            *float64          `json:"serverless_max_capacity,omitempty"`
+	Tags                             map[string]string `json:"tags"`
+}
+
+// InventoryCloudwatch contains the latest Cloudwatch datapoints of the instance, named after Cloudwatch metrics and in Cloudwatch units
+type InventoryCloudwatch struct {
+	ACUUtilization             *float64   `json:"acu_utilization,omitempty"`
+	AuroraBinlogReplicaLag     *float64   `json:"aurora_binlog_replica_lag,omitempty"`
+	BinLogDiskUsage            *float64   `json:"bin_log_disk_usage,omitempty"`
+	BufferCacheHitRatio        *float64   `json:"buffer_cache_hit_ratio,omitempty"`
+	BurstBalance               *float64   `json:"burst_balance,omitempty"`
+	CPUCreditBalance           *float64   `json:"cpu_credit_balance,omitempty"`
+	CPUUtilization             *float64   `json:"cpu_utilization,omitempty"`
+	DatabaseConnections        *float64   `json:"database_connections,omitempty"`
+	DBLoad                     *float64   `json:"db_load,omitempty"`
+	DBLoadCPU                  *float64   `json:"db_load_cpu,omitempty"`
+	DBLoadNonCPU               *float64   `json:"db_load_non_cpu,omitempty"`
+	Deadlocks                  *float64   `json:"deadlocks,omitempty"`
+	EBSByteBalance             *float64   `json:"ebs_byte_balance,omitempty"`
+	EBSIOBalance               *float64   `json:"ebs_io_balance,omitempty"`
+	EngineUptime               *float64   `json:"engine_uptime,omitempty"`
+	FreeableMemory             *float64   `json:"freeable_memory,omitempty"`
+	FreeStorageSpace           *float64   `json:"free_storage_space,omitempty"`
+	MaximumUsedTransactionIDs  *float64   `json:"maximum_used_transaction_ids,omitempty"`
+	NumBinaryLogFiles          *float64   `json:"num_binary_log_files,omitempty"`
+	Queries                    *float64   `json:"queries,omitempty"`
+	ReadIOPS                   *float64   `json:"read_iops,omitempty"`
+	ReadThroughput             *float64   `json:"read_throughput,omitempty"`
+	ReplicaLag                 *float64   `json:"replica_lag,omitempty"`
+	ReplicationSlotDiskUsage   *float64   `json:"replication_slot_disk_usage,omitempty"`
+	ServerlessDatabaseCapacity *float64   `json:"serverless_database_capacity,omitempty"`
+	SumBinaryLogSize           *float64   `json:"sum_binary_log_size,omitempty"`
+	SwapUsage                  *float64   `json:"swap_usage,omitempty"`
+	TransactionLogsDiskUsage   *float64   `json:"transaction_logs_disk_usage,omitempty"`
+	WriteIOPS                  *float64   `json:"write_iops,omitempty"`
+	WriteThroughput            *float64   `json:"write_throughput,omitempty"`
+	LatestTimestamp            *time.Time `json:"latest_timestamp,omitempty"` // Most recent datapoint, including stale datapoints
+}
+
+// InventoryInstanceClass contains the specifications of the underlying EC2 instance class
+type InventoryInstanceClass struct {
+	Vcpu                    int32   `json:"vcpu"`
+	MemoryBytes             int64   `json:"memory_bytes"`
+	MaximumIops             int32   `json:"max_iops"`
+	MaximumThroughputBytes  float64 `json:"max_throughput_bytes"`
+	BaselineIops            int32   `json:"baseline_iops"`
+	BaselineThroughputBytes float64 `json:"baseline_throughput_bytes"`
+	BaselineBandwidthBits   float64 `json:"baseline_bandwidth_bits"`
+	NetworkPerformance      string  `json:"network_performance"`
+}
+
+// Inventory returns instances fetched by the last refresh, sorted by identifier
+// Instances are read from a locked snapshot of the collector metrics, so the inventory can be served while a scrape is running
+func (c *RdsCollector) Inventory() []InventoryInstance {
+	metrics := c.GetMetrics()
+
+	instances := make([]InventoryInstance, 0, len(metrics.RDS.Instances))
+
+	for dbidentifier, instance := range metrics.RDS.Instances {
+		inventoryInstance := InventoryInstance{
+			AccountID:    c.awsAccountID,
+			Region:       c.awsRegion,
+			DBIdentifier: dbidentifier,
+			RDS:          newInventoryRDS(instance),
+		}
+
+		if cloudwatchMetrics, found := metrics.CloudwatchInstances.Instances[dbidentifier]; found && cloudwatchMetrics != nil {
+			inventoryInstance.Cloudwatch = newInventoryCloudwatch(*cloudwatchMetrics)
+		}
+
+		if instanceClass, found := metrics.EC2.Instances[instance.DBInstanceClass]; found {
+			inventoryInstance.InstanceClass = newInventoryInstanceClass(instanceClass)
+		}
+
+		instances = append(instances, inventoryInstance)
+	}
+
+	sort.Slice(instances, func(i, j int) bool { return instances[i].DBIdentifier < instances[j].DBIdentifier })
+
+	return instances
+}
+
+func newInventoryRDS(instance rds.RdsInstanceMetrics) InventoryRDS {
+	return InventoryRDS{
+		ARN:                              instance.Arn,
+		Engine:                           instance.Engine,
+		EngineVersion:                    instance.EngineVersion,
+		InstanceClass:                    instance.DBInstanceClass,
+		DBClusterIdentifier:              instance.DBClusterIdentifier,
+		DbiResourceID:                    instance.DbiResourceID,
+		Role:                             instance.Role,
+		SourceDBIdentifier:               instance.SourceDBInstanceIdentifier,
+		Status:                           rds.GetDBInstanceStatusName(instance.Status),
+		EndpointAddress:                  instance.EndpointAddress,
+		EndpointPort:                     instance.EndpointPort,
+		StorageType:                      instance.StorageType,
+		AllocatedStorageBytes:            instance.AllocatedStorage,
+		MaxAllocatedStorageBytes:         instance.MaxAllocatedStorage,
+		MaxIops:                          instance.MaxIops,
+		StorageThroughputBytes:           instance.StorageThroughput,
+		LogFilesSizeBytes:                instance.LogFilesSize,
+		PendingMaintenance:               instance.PendingMaintenanceAction,
+		PendingModifiedValues:            instance.PendingModifiedValues,
+		BackupRetentionPeriodSeconds:     instance.BackupRetentionPeriod,
+		DeletionProtection:               instance.DeletionProtection,
+		PubliclyAccessible:               instance.PubliclyAccessible,
+		PerformanceInsightsEnabled:       instance.PerformanceInsightsEnabled,
+		MultiAZ:                          instance.MultiAZ,
+		IAMDatabaseAuthenticationEnabled: instance.IAMDatabaseAuthenticationEnabled,
+		CACertificateIdentifier:          instance.CACertificateIdentifier,
+		CertificateValidTill:             instance.CertificateValidTill,
+		AgeSeconds:                       instance.Age,
+		ServerlessMinCapacity:            instance.ServerlessMinCapacity,
+		ServerlessMaxCapacity:            instance.ServerlessMaxCapacity,
+		Tags:                             instance.Tags,
+	}
+}
+
+func newInventoryCloudwatch(metrics cloudwatch.RdsMetrics) *InventoryCloudwatch {
+	return &InventoryCloudwatch{
+		ACUUtilization:             metrics.ACUUtilization,
+		AuroraBinlogReplicaLag:     metrics.AuroraBinlogReplicaLag,
+		BinLogDiskUsage:            metrics.BinLogDiskUsage,
+		BufferCacheHitRatio:        metrics.BufferCacheHitRatio,
+		BurstBalance:               metrics.BurstBalance,
+		CPUCreditBalance:           metrics.CPUCreditBalance,
+		CPUUtilization:             metrics.CPUUtilization,
+		DatabaseConnections:        metrics.DatabaseConnections,
+		DBLoad:                     metrics.DBLoad,
+		DBLoadCPU:                  metrics.DBLoadCPU,
+		DBLoadNonCPU:               metrics.DBLoadNonCPU,
+		Deadlocks:                  metrics.Deadlocks,
+		EBSByteBalance:             metrics.EBSByteBalance,
+		EBSIOBalance:               metrics.EBSIOBalance,
+		EngineUptime:               metrics.EngineUptime,
+		FreeableMemory:             metrics.FreeableMemory,
+		FreeStorageSpace:           metrics.FreeStorageSpace,
+		MaximumUsedTransactionIDs:  metrics.MaximumUsedTransactionIDs,
+		NumBinaryLogFiles:          metrics.NumBinaryLogFiles,
+		Queries:                    metrics.Queries,
+		ReadIOPS:                   metrics.ReadIOPS,
+		ReadThroughput:             metrics.ReadThroughput,
+		ReplicaLag:                 metrics.ReplicaLag,
+		ReplicationSlotDiskUsage:   metrics.ReplicationSlotDiskUsage,
+		ServerlessDatabaseCapacity: metrics.ServerlessDatabaseCapacity,
+		SumBinaryLogSize:           metrics.SumBinaryLogSize,
+		SwapUsage:                  metrics.SwapUsage,
+		TransactionLogsDiskUsage:   metrics.TransactionLogsDiskUsage,
+		WriteIOPS:                  metrics.WriteIOPS,
+		WriteThroughput:            metrics.WriteThroughput,
+		LatestTimestamp:            metrics.LatestTimestamp,
+	}
+}
+
+func newInventoryInstanceClass(instanceClass ec2.EC2InstanceMetrics) *InventoryInstanceClass {
+	return &InventoryInstanceClass{
+		Vcpu:                    instanceClass.Vcpu,
+		MemoryBytes:             instanceClass.Memory,
+		MaximumIops:             instanceClass.MaximumIops,
+		MaximumThroughputBytes:  instanceClass.MaximumThroughput,
+		BaselineIops:            instanceClass.BaselineIops,
+		BaselineThroughputBytes: instanceClass.BaselineThroughput,
+		BaselineBandwidthBits:   instanceClass.BaselineBandwidth,
+		NetworkPerformance:      instanceClass.NetworkPerformance,
+	}
+}
@@ -0,0 +1,110 @@
+package exporter_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/exporter"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/infra/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_rds "github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cloudwatch_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/cloudwatch/mock"
+	ec2_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2/mock"
+	pi_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/pi/mock"
+	rds_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/rds/mock"
+	servicequotas_mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/servicequotas/mock"
+	aws_cloudwatch_types "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	aws_rds_types "github.com/aws/aws-sdk-go-v2/service/rds/types"
+)
+
+func newInventoryCollector(t *testing.T, region string, instances ...aws_rds_types.DBInstance) *exporter.RdsCollector {
+	t.Helper()
+
+	logger, err := logger.New(true, "text")
+	require.NoError(t, err, "Logger must be initialized")
+
+	rdsClient := rds_mock.RDSClient{DescribeDBInstancesOutput: &aws_rds.DescribeDBInstancesOutput{DBInstances: instances}}
+	configuration := exporter.Configuration{CollectInstanceMetrics: true}
+
+	cloudWatchClient := cloudwatch_mock.CloudwatchClient{Metrics: []aws_cloudwatch_types.MetricDataResult{
+		{Id: aws.String("cpuutilization_0"), Values: []float64{10}},
+		{Id: aws.String("cpuutilization_1"), Values: []float64{10}},
+	}}
+
+	collector := exporter.NewCollector(*logger, configuration, "123456789012", region, rdsClient, ec2_mock.EC2Client{}, cloudWatchClient, servicequotas_mock.ServiceQuotasClient{}, pi_mock.PIClient{})
+	testutil.CollectAndCount(collector)
+
+	return collector
+}
+
+func TestInventory(t *testing.T) {
+	postgres := rds_mock.NewRdsInstance()
+	mysql := rds_mock.NewRdsInstance()
+	mysql.Engine = aws.String("mysql")
+
+	collector := newInventoryCollector(t, "eu-west-3", *postgres, *mysql)
+
+	instances := collector.Inventory()
+	require.Len(t, instances, 2, "All instances should be listed")
+	assert.Less(t, instances[0].DBIdentifier, instances[1].DBIdentifier, "Instances should be sorted by identifier")
+
+	for _, instance := range instances {
+		assert.Equal(t, "123456789012", instance.AccountID, "Account should match")
+		assert.Equal(t, "eu-west-3", instance.Region, "Region should match")
+		require.NotNil(t, instance.Cloudwatch, "Cloudwatch metrics should be merged")
+		assert.Equal(t, aws.Float64(10), instance.Cloudwatch.CPUUtilization, "CPU utilization should match")
+	}
+
+	body, err := json.Marshal(instances[0])
+	require.NoError(t, err, "Instance must be encoded")
+
+	var decoded struct {
+		AccountID  string         `json:"account_id"`
+		RDS        map[string]any `json:"rds"`
+		Cloudwatch map[string]any `json:"cloudwatch"`
+		Class      map[string]any `json:"instance_class"`
+	}
+
+	require.NoError(t, json.Unmarshal(body, &decoded), "Instance must be JSON")
+	assert.Equal(t, "123456789012", decoded.AccountID, "Account should be served")
+	assert.Equal(t, "available", decoded.RDS["status"], "Status should be served by name")
+	assert.Equal(t, instances[0].RDS.Engine, decoded.RDS["engine"], "Engine should be served")
+	assert.Contains(t, decoded.RDS, "allocated_storage_bytes", "RDS fields should be snake case with units")
+	assert.NotContains(t, decoded.RDS, "Engine", "Internal field names should not be served")
+	assert.Equal(t, float64(10), decoded.Cloudwatch["cpu_utilization"], "Cloudwatch metrics should be snake case")
+	assert.NotContains(t, decoded.Cloudwatch, "Timestamps", "Internal datapoint timestamps should not be served")
+	assert.Nil(t, decoded.Class, "Unknown instance class should be omitted")
+}
+
+func TestInventoryDuringScrape(t *testing.T) {
+	collector := newInventoryCollector(t, "eu-west-3", *rds_mock.NewRdsInstance())
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 5; i++ {
+			testutil.CollectAndCount(collector)
+		}
+	}()
+
+	// Read the inventory while scrapes are running, data races are reported by go test -race
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+
+		instances := collector.Inventory()
+		assert.Len(t, instances, 1, "Instance of the last refresh should be listed")
+
+		_, err := json.Marshal(instances)
+		assert.NoError(t, err, "Inventory should be encoded during scrapes")
+	}
+}
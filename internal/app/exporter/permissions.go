@@ -0,0 +1,41 @@
+package exporter
+
+import (
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/cloudwatch"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/iam"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/pi"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/rds"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/servicequotas"
+)
+
+// RequiredPermissions returns AWS IAM statements required by the collectors enabled in the configuration, on resources of the AWS partition
+// Aurora cluster metrics are fetched from Cloudwatch API even if instance metrics are received from a metric stream
+func RequiredPermissions(configuration Configuration, partition string) []iam.Statement {
+	statements := rds.Permissions(rds.Configuration{
+		CollectLogsSize:     configuration.CollectLogsSize,
+		CollectMaintenances: configuration.CollectMaintenances,
+	}, partition)
+
+	if configuration.CollectInstanceMetrics {
+		statements = append(statements, cloudwatch.InstancePermissions()...)
+	}
+
+	if configuration.CollectUsages {
+		statements = append(statements, cloudwatch.UsagePermissions()...)
+	}
+
+	if configuration.CollectQuotas {
+		statements = append(statements, servicequotas.Permissions()...)
+	}
+
+	if configuration.CollectInstanceTypes {
+		statements = append(statements, ec2.Permissions()...)
+	}
+
+	if configuration.CollectPerformanceInsights {
+		statements = append(statements, pi.Permissions(partition)...)
+	}
+
+	return statements
+}
@@ -0,0 +1,70 @@
+package exporter_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/exporter"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getActions(statements []iam.Statement) []string {
+	var actions []string
+
+	for _, statement := range iam.NewPolicy(statements).Statement {
+		actions = append(actions, statement.Action...)
+	}
+
+	return actions
+}
+
+func TestRequiredPermissionsWithDisabledCollectors(t *testing.T) {
+	actions := getActions(exporter.RequiredPermissions(exporter.Configuration{}, iam.DefaultPartition))
+
+	assert.ElementsMatch(t, []string{"rds:DescribeDBInstances", "rds:DescribeDBClusters"}, actions, "Only RDS instances and clusters should be described")
+}
+
+func TestRequiredPermissionsWithEnabledCollectors(t *testing.T) {
+	actions := getActions(exporter.RequiredPermissions(exporter.Configuration{
+		CollectInstanceMetrics: true,
+		CollectUsages:          true,
+		CollectQuotas:          true,
+	}, iam.DefaultPartition))
+
+	assert.Contains(t, actions, "cloudwatch:ListMetrics", "Usages should be discovered")
+	assert.Contains(t, actions, "servicequotas:ListServiceQuotas", "Quotas should be listed")
+	assert.NotContains(t, actions, "pi:GetResourceMetrics", "Performance Insights is disabled")
+	assert.NotContains(t, actions, "rds:DescribeDBLogFiles", "Logs size is disabled")
+}
+
+func TestRequiredPermissionsPartition(t *testing.T) {
+	policy := iam.NewPolicy(exporter.RequiredPermissions(exporter.Configuration{CollectPerformanceInsights: true}, "aws-cn"))
+
+	var resources []string
+	for _, statement := range policy.Statement {
+		resources = append(resources, statement.Resource...)
+	}
+
+	assert.ElementsMatch(t, []string{"arn:aws-cn:rds:*:*:db:*", "arn:aws-cn:rds:*:*:cluster:*", "arn:aws-cn:pi:*:*:metrics/rds/*"}, resources, "Resources should belong to the partition")
+}
+
+// TestPolicyFile ensures the policy published in configs/aws is generated with all collectors enabled
+func TestPolicyFile(t *testing.T) {
+	expected, err := os.ReadFile("../../../configs/aws/policy.json")
+	require.NoError(t, err, "Policy file should be readable")
+
+	document, err := iam.NewPolicy(exporter.RequiredPermissions(exporter.Configuration{
+		CollectInstanceMetrics:     true,
+		CollectInstanceTypes:       true,
+		CollectLogsSize:            true,
+		CollectMaintenances:        true,
+		CollectPerformanceInsights: true,
+		CollectQuotas:              true,
+		CollectUsages:              true,
+	}, iam.DefaultPartition)).JSON()
+	require.NoError(t, err, "Policy should be encoded")
+
+	assert.Equal(t, string(expected), string(document), "configs/aws/policy.json is outdated, regenerate it with 'prometheus-rds-exporter iam-policy --collect-performance-insights'")
+}
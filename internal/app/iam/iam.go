@@ -0,0 +1,141 @@
+// Package iam generates AWS IAM policies from the permissions declared by collectors
+package iam
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+const (
+	policyVersion = "2012-10-17"
+	allowEffect   = "Allow"
+
+	// DefaultPartition is the AWS partition of commercial regions
+	DefaultPartition = "aws"
+)
+
+// partitions maps region prefixes to their AWS partition, ordered from the most specific prefix
+var partitions = []struct {
+	regionPrefix string
+	partition    string
+}{
+	{regionPrefix: "cn-", partition: "aws-cn"},
+	{regionPrefix: "us-gov-", partition: "aws-us-gov"},
+	{regionPrefix: "us-isob-", partition: "aws-iso-b"},
+	{regionPrefix: "us-iso-", partition: "aws-iso"},
+	{regionPrefix: "eu-isoe-", partition: "aws-iso-e"},
+}
+
+// AllResources is the resource of actions that don't support resource-level permissions
+var AllResources = []string{"*"}
+
+// Statement grants actions on resources, statements with the same Sid are merged in policies
+type Statement struct {
+	Sid      string   `json:"Sid"`
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource []string `json:"Resource"`
+}
+
+// Policy is an AWS IAM policy document
+type Policy struct {
+	Version   string      `json:"Version"`
+	Statement []Statement `json:"Statement"`
+}
+
+// Partition returns the AWS partition of a region (eg. aws-cn for cn-north-1)
+func Partition(region string) string {
+	for _, p := range partitions {
+		if strings.HasPrefix(region, p.regionPrefix) {
+			return p.partition
+		}
+	}
+
+	return DefaultPartition
+}
+
+// ResourceARN returns the ARN of resources of a service in all regions and accounts of a partition (eg. arn:aws:rds:*:*:db:*)
+func ResourceARN(partition string, service string, resource string) string {
+	return fmt.Sprintf("arn:%s:%s:*:*:%s", partition, service, resource)
+}
+
+// Allow returns a statement allowing actions on resources
+func Allow(sid string, actions []string, resources []string) Statement {
+	return Statement{
+		Sid:      sid,
+		Effect:   allowEffect,
+		Action:   actions,
+		Resource: resources,
+	}
+}
+
+// NewPolicy returns a policy of the statements
+// Statements sharing a Sid are merged and duplicated actions or resources are removed, statements keep their first declaration order
+func NewPolicy(statements []Statement) Policy {
+	policy := Policy{Version: policyVersion}
+
+	for _, statement := range statements {
+		index := slices.IndexFunc(policy.Statement, func(s Statement) bool { return s.Sid == statement.Sid })
+		if index < 0 {
+			policy.Statement = append(policy.Statement, Statement{Sid: statement.Sid, Effect: statement.Effect})
+			index = len(policy.Statement) - 1
+		}
+
+		policy.Statement[index].Action = appendUniq(policy.Statement[index].Action, statement.Action)
+		policy.Statement[index].Resource = appendUniq(policy.Statement[index].Resource, statement.Resource)
+	}
+
+	return policy
+}
+
+// JSON returns the policy document
+func (p Policy) JSON() ([]byte, error) {
+	document, err := json.MarshalIndent(p, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("can't encode policy: %w", err)
+	}
+
+	return append(document, '\n'), nil
+}
+
+// Terraform returns the policy as a Terraform aws_iam_policy_document data source
+func (p Policy) Terraform(name string) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "data \"aws_iam_policy_document\" %q {\n", name)
+
+	for _, statement := range p.Statement {
+		builder.WriteString("  statement {\n")
+		fmt.Fprintf(&builder, "    sid       = %q\n", statement.Sid)
+		fmt.Fprintf(&builder, "    effect    = %q\n", statement.Effect)
+		fmt.Fprintf(&builder, "    actions   = %s\n", terraformList(statement.Action))
+		fmt.Fprintf(&builder, "    resources = %s\n", terraformList(statement.Resource))
+		builder.WriteString("  }\n")
+	}
+
+	builder.WriteString("}\n")
+
+	return builder.String()
+}
+
+func terraformList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func appendUniq(values []string, additions []string) []string {
+	for _, addition := range additions {
+		if !slices.Contains(values, addition) {
+			values = append(values, addition)
+		}
+	}
+
+	return values
+}
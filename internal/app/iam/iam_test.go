@@ -0,0 +1,66 @@
+package iam_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPolicy(t *testing.T) {
+	policy := iam.NewPolicy([]iam.Statement{
+		iam.Allow("AllowMetrics", []string{"cloudwatch:GetMetricData"}, iam.AllResources),
+		iam.Allow("AllowInstances", []string{"rds:DescribeDBInstances"}, []string{"arn:aws:rds:*:*:db:*"}),
+		iam.Allow("AllowMetrics", []string{"cloudwatch:GetMetricData", "cloudwatch:ListMetrics"}, iam.AllResources),
+	})
+
+	require.Len(t, policy.Statement, 2, "Statements with the same Sid should be merged")
+	assert.Equal(t, "AllowMetrics", policy.Statement[0].Sid, "Statements should keep their declaration order")
+	assert.Equal(t, []string{"cloudwatch:GetMetricData", "cloudwatch:ListMetrics"}, policy.Statement[0].Action, "Actions should be deduplicated")
+	assert.Equal(t, []string{"*"}, policy.Statement[0].Resource, "Resources should be deduplicated")
+	assert.Equal(t, []string{"*"}, iam.AllResources, "Merging statements must not modify declared resources")
+
+	document, err := policy.JSON()
+	require.NoError(t, err, "Policy should be encoded")
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(document, &decoded), "Policy should be valid JSON")
+	assert.Equal(t, "2012-10-17", decoded["Version"], "Policy version mismatch")
+}
+
+func TestTerraform(t *testing.T) {
+	policy := iam.NewPolicy([]iam.Statement{
+		iam.Allow("AllowInstanceTypeDescriptions", []string{"ec2:DescribeInstanceTypes"}, iam.AllResources),
+	})
+
+	expected := `data "aws_iam_policy_document" "exporter" {
+  statement {
+    sid       = "AllowInstanceTypeDescriptions"
+    effect    = "Allow"
+    actions   = ["ec2:DescribeInstanceTypes"]
+    resources = ["*"]
+  }
+}
+`
+
+	assert.Equal(t, expected, policy.Terraform("exporter"), "Terraform snippet mismatch")
+}
+
+func TestPartition(t *testing.T) {
+	testCases := map[string]string{
+		"eu-west-3":      "aws",
+		"us-east-1":      "aws",
+		"cn-north-1":     "aws-cn",
+		"us-gov-west-1":  "aws-us-gov",
+		"us-iso-east-1":  "aws-iso",
+		"us-isob-east-1": "aws-iso-b",
+	}
+
+	for region, partition := range testCases {
+		assert.Equal(t, partition, iam.Partition(region), "Partition of %s mismatch", region)
+	}
+
+	assert.Equal(t, "arn:aws-us-gov:rds:*:*:db:*", iam.ResourceARN("aws-us-gov", "rds", "db:*"), "ARN mismatch")
+}
@@ -0,0 +1,151 @@
+package metricstream
+
+import (
+	"compress/gzip"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// bodyReadTimeout is the maximum duration to read a delivery, the web server read timeout is too short for the largest deliveries
+// AWS Firehose HTTP endpoint delivery timeout is at most 180 seconds
+const bodyReadTimeout = 180 * time.Second
+
+// firehoseRequest is the payload sent by AWS Firehose to HTTP endpoints
+// https://docs.aws.amazon.com/firehose/latest/dev/httpdeliveryrequestresponse.html
+type firehoseRequest struct {
+	RequestID string `json:"requestId"`
+	Timestamp int64  `json:"timestamp"`
+	Records   []struct {
+		Data []byte `json:"data"`
+	} `json:"records"`
+}
+
+type firehoseResponse struct {
+	RequestID    string `json:"requestId"`
+	Timestamp    int64  `json:"timestamp"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// NewHandler returns an HTTP handler receiving AWS Firehose deliveries of a Cloudwatch metric stream
+func NewHandler(logger slog.Logger, store *Store, configuration Configuration) (*Handler, error) {
+	if configuration.Format != FormatJSON && configuration.Format != FormatOpenTelemetry {
+		return nil, fmt.Errorf("can't receive '%s' metric stream: %w", configuration.Format, errUnknownFormat)
+	}
+
+	return &Handler{
+		logger:        &logger,
+		store:         store,
+		configuration: configuration,
+	}, nil
+}
+
+type Handler struct {
+	logger        *slog.Logger
+	store         *Store
+	configuration Configuration
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Amz-Firehose-Request-Id")
+
+	if r.Method != http.MethodPost {
+		h.reply(w, http.StatusMethodNotAllowed, requestID, "method not allowed")
+
+		return
+	}
+
+	if !h.isAuthorized(r) {
+		h.logger.Warn("reject metric stream delivery", "request_id", requestID, "reason", errInvalidAccessKey)
+		h.reply(w, http.StatusUnauthorized, requestID, errInvalidAccessKey.Error())
+
+		return
+	}
+
+	err := http.NewResponseController(w).SetReadDeadline(time.Now().Add(bodyReadTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.Warn("can't extend metric stream delivery read deadline", "request_id", requestID, "reason", err)
+	}
+
+	request, err := decodeFirehoseRequest(w, r)
+	if err != nil {
+		h.logger.Error("can't decode metric stream delivery", "request_id", requestID, "reason", err)
+		h.reply(w, http.StatusBadRequest, requestID, err.Error())
+
+		return
+	}
+
+	if request.RequestID != "" {
+		requestID = request.RequestID
+	}
+
+	for _, record := range request.Records {
+		err := h.store.Receive(h.configuration.Format, record.Data)
+		if err != nil {
+			h.logger.Error("can't decode metric stream record", "request_id", requestID, "reason", err)
+			h.reply(w, http.StatusBadRequest, requestID, err.Error())
+
+			return
+		}
+	}
+
+	h.logger.Debug("metric stream delivery received", "request_id", requestID, "records", len(request.Records))
+	h.reply(w, http.StatusOK, requestID, "")
+}
+
+// isAuthorized checks the access key when one is configured on the AWS Firehose HTTP endpoint
+func (h *Handler) isAuthorized(r *http.Request) bool {
+	if h.configuration.AccessKey == "" {
+		return true
+	}
+
+	accessKey := r.Header.Get(firehoseAccessKeyName)
+
+	return subtle.ConstantTimeCompare([]byte(accessKey), []byte(h.configuration.AccessKey)) == 1
+}
+
+// reply sends the response expected by AWS Firehose, any status other than 200 triggers a retry
+func (h *Handler) reply(w http.ResponseWriter, status int, requestID string, errorMessage string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	response := firehoseResponse{
+		RequestID:    requestID,
+		Timestamp:    time.Now().UnixMilli(),
+		ErrorMessage: errorMessage,
+	}
+
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.logger.Error("can't write metric stream response", "request_id", requestID, "reason", err)
+	}
+}
+
+func decodeFirehoseRequest(w http.ResponseWriter, r *http.Request) (firehoseRequest, error) {
+	var (
+		request firehoseRequest
+		body    io.Reader = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	)
+
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return request, fmt.Errorf("can't decompress request: %w", err)
+		}
+		defer reader.Close()
+
+		body = io.LimitReader(reader, maxRequestBodySize)
+	}
+
+	err := json.NewDecoder(body).Decode(&request)
+	if err != nil {
+		return request, fmt.Errorf("can't decode request: %w", err)
+	}
+
+	return request, nil
+}
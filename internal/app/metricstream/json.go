@@ -0,0 +1,58 @@
+package metricstream
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// jsonDatapoint is a datapoint of metric streams using JSON output format
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch-metric-streams-formats-json.html
+type jsonDatapoint struct {
+	AccountID  string            `json:"account_id"`
+	Region     string            `json:"region"`
+	Namespace  string            `json:"namespace"`
+	MetricName string            `json:"metric_name"`
+	Dimensions map[string]string `json:"dimensions"`
+	Timestamp  int64             `json:"timestamp"` // Milliseconds since epoch
+	Value      struct {
+		Sum   float64 `json:"sum"`
+		Count float64 `json:"count"`
+	} `json:"value"`
+}
+
+// decodeJSON returns datapoints of a record, each record contains newline delimited JSON datapoints
+func decodeJSON(data []byte) ([]Datapoint, error) {
+	var datapoints []Datapoint
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	for {
+		var d jsonDatapoint
+
+		err := decoder.Decode(&d)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("can't decode JSON datapoint: %w", err)
+		}
+
+		datapoints = append(datapoints, Datapoint{
+			AccountID:  d.AccountID,
+			Region:     d.Region,
+			Namespace:  d.Namespace,
+			MetricName: d.MetricName,
+			Dimensions: d.Dimensions,
+			Timestamp:  time.UnixMilli(d.Timestamp),
+			Sum:        d.Value.Sum,
+			Count:      d.Value.Count,
+		})
+	}
+
+	return datapoints, nil
+}
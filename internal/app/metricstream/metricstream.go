@@ -0,0 +1,260 @@
+// Package metricstream implements a receiver for AWS Cloudwatch Metric Streams delivered by AWS Firehose HTTP endpoints
+package metricstream
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/cloudwatch"
+)
+
+const (
+	FormatJSON          string = "json"
+	FormatOpenTelemetry string = "opentelemetry0.7"
+
+	rdsNamespace          string = "AWS/RDS"
+	dbInstanceDimension   string = "DBInstanceIdentifier"
+	maxRequestBodySize    int64  = 64 * 1024 * 1024 // AWS Firehose HTTP endpoints buffer is limited to 64 MiB
+	firehoseAccessKeyName string = "X-Amz-Firehose-Access-Key"
+
+	defaultRetention = time.Hour   // Retention of instances without datapoints when max staleness is disabled
+	pruneInterval    = time.Minute // Minimum delay between removals of instances without recent datapoints
+)
+
+var (
+	errUnknownFormat    = errors.New("unknown metric stream format")
+	errInvalidAccessKey = errors.New("invalid access key")
+)
+
+type Configuration struct {
+	Format    string // Metric stream output format (json or opentelemetry0.7)
+	AccessKey string // Optional access key configured on the AWS Firehose HTTP endpoint
+}
+
+// Datapoint is a Cloudwatch datapoint received from a metric stream
+type Datapoint struct {
+	AccountID  string
+	Region     string
+	Namespace  string
+	MetricName string
+	Dimensions map[string]string
+	Timestamp  time.Time
+	Sum        float64
+	Count      float64
+}
+
+// Average returns the datapoint average value, the statistic used when polling Cloudwatch API
+func (d Datapoint) Average() (float64, bool) {
+	if d.Count == 0 {
+		return 0, false
+	}
+
+	return d.Sum / d.Count, true
+}
+
+// dbIdentifier returns the RDS instance identifier of instance level datapoints
+func (d Datapoint) dbIdentifier() (string, bool) {
+	if d.Namespace != rdsNamespace || len(d.Dimensions) != 1 {
+		return "", false
+	}
+
+	dbIdentifier, found := d.Dimensions[dbInstanceDimension]
+
+	return dbIdentifier, found && dbIdentifier != ""
+}
+
+type Statistics struct {
+	Datapoints float64
+	Errors     float64
+	Evictions  float64 // Instances removed because they didn't receive datapoints during the retention
+}
+
+// NewStore returns an empty store for metric stream datapoints
+// Datapoints older than maxStaleness, or one hour if disabled, are not returned
+// Instances without datapoints during this retention are removed, like deleted instances
+func NewStore(maxStaleness time.Duration) *Store {
+	retention := maxStaleness
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+
+	return &Store{
+		retention: retention,
+		accounts:  make(map[string]map[string]*cloudwatch.RdsMetrics),
+	}
+}
+
+// Store keeps the latest RDS instance metrics received from metric streams by AWS account and region
+type Store struct {
+	mu         sync.RWMutex
+	retention  time.Duration
+	lastPrune  time.Time
+	accounts   map[string]map[string]*cloudwatch.RdsMetrics
+	statistics Statistics
+}
+
+func (s *Store) GetStatistics() Statistics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.statistics
+}
+
+// Add records the datapoint if it's an RDS instance metric more recent than the stored one
+// Datapoints of other namespaces or metrics not exported by the collector are ignored
+func (s *Store) Add(datapoint Datapoint) {
+	dbIdentifier, found := datapoint.dbIdentifier()
+	if !found {
+		return
+	}
+
+	value, found := datapoint.Average()
+	if !found {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := storeKey(datapoint.AccountID, datapoint.Region)
+
+	instances, found := s.accounts[key]
+	if !found {
+		instances = make(map[string]*cloudwatch.RdsMetrics)
+		s.accounts[key] = instances
+	}
+
+	instance, found := instances[dbIdentifier]
+	if !found {
+		instance = &cloudwatch.RdsMetrics{}
+	}
+
+	// Metric streams may deliver datapoints out of order
+	if previous, found := instance.Timestamps[datapoint.MetricName]; found && previous.After(datapoint.Timestamp) {
+		return
+	}
+
+	// Update only fails on metrics unknown by the collector
+	err := instance.UpdateWithTimestamp(datapoint.MetricName, value, datapoint.Timestamp)
+	if err != nil {
+		return
+	}
+
+	instances[dbIdentifier] = instance
+	s.statistics.Datapoints++
+
+	s.prune(time.Now())
+}
+
+// prune removes instances without datapoints more recent than the retention, and accounts without instances
+// Streams may send any account and region, so entries are pruned on write to bound the memory usage
+func (s *Store) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < pruneInterval {
+		return
+	}
+
+	s.lastPrune = now
+
+	for key, instances := range s.accounts {
+		for dbIdentifier, instance := range instances {
+			if s.expired(instance, now) {
+				delete(instances, dbIdentifier)
+				s.statistics.Evictions++
+			}
+		}
+
+		if len(instances) == 0 {
+			delete(s.accounts, key)
+		}
+	}
+}
+
+// expired returns true if the instance has no datapoint more recent than the retention
+func (s *Store) expired(instance *cloudwatch.RdsMetrics, now time.Time) bool {
+	return instance.LatestTimestamp == nil || now.Sub(*instance.LatestTimestamp) > s.retention
+}
+
+// GetRDSInstanceMetrics returns a copy of metrics received for the specified instances
+// Instances are pruned on write only, so the retention is also checked here in case streams stopped delivering
+func (s *Store) GetRDSInstanceMetrics(awsAccountID string, awsRegion string, dbIdentifiers []string) cloudwatch.CloudWatchMetrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	metrics := make(map[string]*cloudwatch.RdsMetrics)
+	instances := s.accounts[storeKey(awsAccountID, awsRegion)]
+	now := time.Now()
+
+	for _, dbIdentifier := range dbIdentifiers {
+		instance, found := instances[dbIdentifier]
+		if !found || s.expired(instance, now) {
+			continue
+		}
+
+		metrics[dbIdentifier] = s.copyFreshMetrics(instance, now)
+	}
+
+	return cloudwatch.CloudWatchMetrics{Instances: metrics}
+}
+
+// copyFreshMetrics returns a copy of instance metrics without stale datapoints
+func (s *Store) copyFreshMetrics(instance *cloudwatch.RdsMetrics, now time.Time) *cloudwatch.RdsMetrics {
+	metrics := &cloudwatch.RdsMetrics{}
+
+	for metricName, timestamp := range instance.Timestamps {
+		if now.Sub(timestamp) > s.retention {
+			continue
+		}
+
+		value, found := instance.Get(metricName)
+		if !found {
+			continue
+		}
+
+		_ = metrics.UpdateWithTimestamp(metricName, value, timestamp)
+	}
+
+	// Keep track of the latest datapoint, including stale ones, like Cloudwatch API fetcher
+	if instance.LatestTimestamp != nil {
+		latestTimestamp := *instance.LatestTimestamp
+		metrics.LatestTimestamp = &latestTimestamp
+	}
+
+	return metrics
+}
+
+// Receive decodes a Firehose record and stores its datapoints
+func (s *Store) Receive(format string, data []byte) error {
+	var (
+		datapoints []Datapoint
+		err        error
+	)
+
+	switch format {
+	case FormatJSON:
+		datapoints, err = decodeJSON(data)
+	case FormatOpenTelemetry:
+		datapoints, err = decodeOpenTelemetry(data)
+	default:
+		err = fmt.Errorf("can't decode '%s' records: %w", format, errUnknownFormat)
+	}
+
+	if err != nil {
+		s.mu.Lock()
+		s.statistics.Errors++
+		s.mu.Unlock()
+
+		return err
+	}
+
+	for _, datapoint := range datapoints {
+		s.Add(datapoint)
+	}
+
+	return nil
+}
+
+func storeKey(awsAccountID string, awsRegion string) string {
+	return awsAccountID + "/" + awsRegion
+}
@@ -0,0 +1,271 @@
+package metricstream_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/metricstream"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/infra/logger"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	accountID = "123456789012"
+	region    = "eu-west-3"
+)
+
+// newFirehoseRequest returns an AWS Firehose HTTP endpoint delivery containing the records
+func newFirehoseRequest(t *testing.T, records ...[]byte) *http.Request {
+	t.Helper()
+
+	type record struct {
+		Data []byte `json:"data"`
+	}
+
+	payload := struct {
+		RequestID string   `json:"requestId"`
+		Timestamp int64    `json:"timestamp"`
+		Records   []record `json:"records"`
+	}{RequestID: "request1", Timestamp: time.Now().UnixMilli()}
+
+	for _, data := range records {
+		payload.Records = append(payload.Records, record{Data: data})
+	}
+
+	body, err := json.Marshal(payload)
+	require.NoError(t, err, "Firehose request must be encoded")
+
+	return httptest.NewRequest(http.MethodPost, "/metric-stream", bytes.NewReader(body))
+}
+
+func newHandler(t *testing.T, store *metricstream.Store, configuration metricstream.Configuration) *metricstream.Handler {
+	t.Helper()
+
+	logger, err := logger.New(false, "text")
+	require.NoError(t, err, "Logger must be created")
+
+	handler, err := metricstream.NewHandler(*logger, store, configuration)
+	require.NoError(t, err, "Handler must be created")
+
+	return handler
+}
+
+func TestJSONFormat(t *testing.T) {
+	timestamp := time.Now().Truncate(time.Millisecond)
+	record := []byte(`{"account_id":"123456789012","region":"eu-west-3","namespace":"AWS/RDS","metric_name":"CPUUtilization","dimensions":{"DBInstanceIdentifier":"db1"},"timestamp":` + jsonTimestamp(timestamp) + `,"value":{"max":40,"min":10,"sum":50,"count":2},"unit":"Percent"}
+{"account_id":"123456789012","region":"eu-west-3","namespace":"AWS/RDS","metric_name":"CPUUtilization","dimensions":{"EngineName":"postgres"},"timestamp":` + jsonTimestamp(timestamp) + `,"value":{"max":40,"min":10,"sum":50,"count":2},"unit":"Percent"}
+{"account_id":"123456789012","region":"eu-west-3","namespace":"AWS/EC2","metric_name":"CPUUtilization","dimensions":{"DBInstanceIdentifier":"db1"},"timestamp":` + jsonTimestamp(timestamp) + `,"value":{"max":99,"min":99,"sum":99,"count":1},"unit":"Percent"}
+`)
+
+	store := metricstream.NewStore(0)
+	handler := newHandler(t, store, metricstream.Configuration{Format: metricstream.FormatJSON})
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, newFirehoseRequest(t, record))
+
+	require.Equal(t, http.StatusOK, response.Code, "Delivery must be accepted")
+	assert.Contains(t, response.Body.String(), `"requestId":"request1"`, "Response must contain request ID")
+
+	metrics := store.GetRDSInstanceMetrics(accountID, region, []string{"db1", "db2"})
+	require.Len(t, metrics.Instances, 1, "Only instances having datapoints must be returned")
+	assert.Equal(t, aws.Float64(25), metrics.Instances["db1"].CPUUtilization, "Average must be computed from sum and count")
+	assert.Equal(t, timestamp, metrics.Instances["db1"].Timestamps["CPUUtilization"], "Datapoint timestamp mismatch")
+	assert.Equal(t, float64(1), store.GetStatistics().Datapoints, "Only instance datapoints of AWS/RDS namespace must be stored")
+
+	otherRegion := store.GetRDSInstanceMetrics(accountID, "us-east-1", []string{"db1"})
+	assert.Empty(t, otherRegion.Instances, "Metrics must be isolated by region")
+}
+
+func TestOpenTelemetryFormat(t *testing.T) {
+	timestamp := time.Now()
+	record := newOpenTelemetryRecord(timestamp, "ReadIOPS", `{"DBInstanceIdentifier":"db1"}`, 300, 3)
+
+	store := metricstream.NewStore(0)
+	handler := newHandler(t, store, metricstream.Configuration{Format: metricstream.FormatOpenTelemetry})
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, newFirehoseRequest(t, record))
+
+	require.Equal(t, http.StatusOK, response.Code, "Delivery must be accepted")
+
+	metrics := store.GetRDSInstanceMetrics(accountID, region, []string{"db1"})
+	require.Contains(t, metrics.Instances, "db1", "Instance metrics must be stored")
+	assert.Equal(t, aws.Float64(100), metrics.Instances["db1"].ReadIOPS, "Average must be computed from sum and count")
+	assert.Equal(t, timestamp.UnixNano(), metrics.Instances["db1"].Timestamps["ReadIOPS"].UnixNano(), "Datapoint timestamp mismatch")
+}
+
+func TestOutOfOrderDatapoints(t *testing.T) {
+	now := time.Now()
+	store := metricstream.NewStore(0)
+
+	store.Add(metricstream.Datapoint{AccountID: accountID, Region: region, Namespace: "AWS/RDS", MetricName: "FreeableMemory", Dimensions: map[string]string{"DBInstanceIdentifier": "db1"}, Timestamp: now, Sum: 10, Count: 1})
+	store.Add(metricstream.Datapoint{AccountID: accountID, Region: region, Namespace: "AWS/RDS", MetricName: "FreeableMemory", Dimensions: map[string]string{"DBInstanceIdentifier": "db1"}, Timestamp: now.Add(-time.Minute), Sum: 20, Count: 1})
+
+	metrics := store.GetRDSInstanceMetrics(accountID, region, []string{"db1"})
+	assert.Equal(t, aws.Float64(10), metrics.Instances["db1"].FreeableMemory, "Older datapoint must not override the latest one")
+}
+
+func TestStaleDatapoints(t *testing.T) {
+	now := time.Now()
+	store := metricstream.NewStore(5 * time.Minute)
+
+	store.Add(metricstream.Datapoint{AccountID: accountID, Region: region, Namespace: "AWS/RDS", MetricName: "FreeableMemory", Dimensions: map[string]string{"DBInstanceIdentifier": "db1"}, Timestamp: now, Sum: 10, Count: 1})
+	store.Add(metricstream.Datapoint{AccountID: accountID, Region: region, Namespace: "AWS/RDS", MetricName: "SwapUsage", Dimensions: map[string]string{"DBInstanceIdentifier": "db1"}, Timestamp: now.Add(-time.Hour), Sum: 20, Count: 1})
+
+	metrics := store.GetRDSInstanceMetrics(accountID, region, []string{"db1"})
+	assert.Equal(t, aws.Float64(10), metrics.Instances["db1"].FreeableMemory, "Fresh datapoint must be kept")
+	assert.Nil(t, metrics.Instances["db1"].SwapUsage, "Stale datapoint must be dropped")
+}
+
+func TestEviction(t *testing.T) {
+	now := time.Now()
+	store := metricstream.NewStore(5 * time.Minute)
+
+	store.Add(metricstream.Datapoint{AccountID: "210987654321", Region: "us-east-1", Namespace: "AWS/RDS", MetricName: "FreeableMemory", Dimensions: map[string]string{"DBInstanceIdentifier": "deleted"}, Timestamp: now.Add(-time.Hour), Sum: 10, Count: 1})
+	store.Add(metricstream.Datapoint{AccountID: accountID, Region: region, Namespace: "AWS/RDS", MetricName: "FreeableMemory", Dimensions: map[string]string{"DBInstanceIdentifier": "db1"}, Timestamp: now, Sum: 10, Count: 1})
+
+	assert.Equal(t, float64(1), store.GetStatistics().Evictions, "Instance without recent datapoints must be evicted")
+	assert.Empty(t, store.GetRDSInstanceMetrics("210987654321", "us-east-1", []string{"deleted"}).Instances, "Evicted instance must not be returned")
+	assert.Contains(t, store.GetRDSInstanceMetrics(accountID, region, []string{"db1"}).Instances, "db1", "Fresh instance must be kept")
+}
+
+func TestRetentionWithoutDeliveries(t *testing.T) {
+	now := time.Now()
+	store := metricstream.NewStore(0)
+
+	store.Add(metricstream.Datapoint{AccountID: accountID, Region: region, Namespace: "AWS/RDS", MetricName: "FreeableMemory", Dimensions: map[string]string{"DBInstanceIdentifier": "db1"}, Timestamp: now, Sum: 10, Count: 1})
+
+	// Datapoints added right after a prune stay in the store until the next one
+	store.Add(metricstream.Datapoint{AccountID: accountID, Region: region, Namespace: "AWS/RDS", MetricName: "SwapUsage", Dimensions: map[string]string{"DBInstanceIdentifier": "db1"}, Timestamp: now.Add(-2 * time.Hour), Sum: 20, Count: 1})
+	store.Add(metricstream.Datapoint{AccountID: accountID, Region: region, Namespace: "AWS/RDS", MetricName: "FreeableMemory", Dimensions: map[string]string{"DBInstanceIdentifier": "stopped"}, Timestamp: now.Add(-2 * time.Hour), Sum: 10, Count: 1})
+
+	metrics := store.GetRDSInstanceMetrics(accountID, region, []string{"db1", "stopped"})
+	assert.NotContains(t, metrics.Instances, "stopped", "Instance without datapoints during the default retention must not be returned")
+	require.Contains(t, metrics.Instances, "db1", "Fresh instance must be returned")
+	assert.Equal(t, aws.Float64(10), metrics.Instances["db1"].FreeableMemory, "Fresh datapoint must be kept")
+	assert.Nil(t, metrics.Instances["db1"].SwapUsage, "Datapoint older than the default retention must be dropped")
+}
+
+func TestAccessKey(t *testing.T) {
+	store := metricstream.NewStore(0)
+	handler := newHandler(t, store, metricstream.Configuration{Format: metricstream.FormatJSON, AccessKey: "secret"})
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, newFirehoseRequest(t))
+	assert.Equal(t, http.StatusUnauthorized, response.Code, "Delivery without access key must be rejected")
+
+	request := newFirehoseRequest(t)
+	request.Header.Set("X-Amz-Firehose-Access-Key", "secret")
+
+	response = httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code, "Delivery with access key must be accepted")
+}
+
+func TestSlowDelivery(t *testing.T) {
+	store := metricstream.NewStore(0)
+
+	server := httptest.NewUnstartedServer(newHandler(t, store, metricstream.Configuration{Format: metricstream.FormatJSON}))
+	server.Config.ReadTimeout = 100 * time.Millisecond
+	server.Start()
+	t.Cleanup(server.Close)
+
+	body, err := io.ReadAll(newFirehoseRequest(t).Body)
+	require.NoError(t, err, "Firehose request must be read")
+
+	reader, writer := io.Pipe()
+
+	go func() {
+		_, _ = writer.Write(body[:1])
+		time.Sleep(3 * server.Config.ReadTimeout)
+		_, _ = writer.Write(body[1:])
+		_ = writer.Close()
+	}()
+
+	response, err := http.Post(server.URL, "application/json", reader) //nolint:noctx
+	require.NoError(t, err, "Delivery must be sent")
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode, "Deliveries slower than the web server read timeout must be accepted")
+}
+
+func TestInvalidRecord(t *testing.T) {
+	store := metricstream.NewStore(0)
+	handler := newHandler(t, store, metricstream.Configuration{Format: metricstream.FormatJSON})
+
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, newFirehoseRequest(t, []byte("not json")))
+
+	assert.Equal(t, http.StatusBadRequest, response.Code, "Invalid record must be rejected")
+	assert.Contains(t, response.Body.String(), "errorMessage", "Response must contain error message")
+	assert.Equal(t, float64(1), store.GetStatistics().Errors, "Error must be counted")
+}
+
+func TestUnknownFormat(t *testing.T) {
+	logger, err := logger.New(false, "text")
+	require.NoError(t, err, "Logger must be created")
+
+	_, err = metricstream.NewHandler(*logger, metricstream.NewStore(0), metricstream.Configuration{Format: "csv"})
+	assert.Error(t, err, "Unknown format must be rejected")
+}
+
+func jsonTimestamp(timestamp time.Time) string {
+	encoded, _ := json.Marshal(timestamp.UnixMilli())
+
+	return string(encoded)
+}
+
+// newOpenTelemetryRecord returns a length delimited OpenTelemetry 0.7 ExportMetricsServiceRequest containing one datapoint
+func newOpenTelemetryRecord(timestamp time.Time, metricName string, dimensions string, sum float64, count uint64) []byte {
+	appendMessage := func(b []byte, number protowire.Number, message []byte) []byte {
+		b = protowire.AppendTag(b, number, protowire.BytesType)
+
+		return protowire.AppendBytes(b, message)
+	}
+
+	appendString := func(b []byte, number protowire.Number, value string) []byte {
+		return appendMessage(b, number, []byte(value))
+	}
+
+	stringKeyValue := func(key string, value string) []byte {
+		return appendString(appendString(nil, 1, key), 2, value)
+	}
+
+	attribute := func(key string, value string) []byte {
+		return appendMessage(appendString(nil, 1, key), 2, appendString(nil, 1, value))
+	}
+
+	var datapoint []byte
+	datapoint = appendMessage(datapoint, 1, stringKeyValue("Namespace", "AWS/RDS"))
+	datapoint = appendMessage(datapoint, 1, stringKeyValue("MetricName", metricName))
+	datapoint = appendMessage(datapoint, 1, stringKeyValue("Dimensions", dimensions))
+	datapoint = protowire.AppendTag(datapoint, 3, protowire.Fixed64Type)
+	datapoint = protowire.AppendFixed64(datapoint, uint64(timestamp.UnixNano()))
+	datapoint = protowire.AppendTag(datapoint, 4, protowire.Fixed64Type)
+	datapoint = protowire.AppendFixed64(datapoint, count)
+	datapoint = protowire.AppendTag(datapoint, 5, protowire.Fixed64Type)
+	datapoint = protowire.AppendFixed64(datapoint, math.Float64bits(sum))
+
+	summary := appendMessage(nil, 1, datapoint)
+	metric := appendMessage(appendString(nil, 1, "amazonaws.com/AWS/RDS/"+metricName), 11, summary)
+	libraryMetrics := appendMessage(nil, 2, metric)
+
+	var resource []byte
+	resource = appendMessage(resource, 1, attribute("cloud.provider", "aws"))
+	resource = appendMessage(resource, 1, attribute("cloud.account.id", accountID))
+	resource = appendMessage(resource, 1, attribute("cloud.region", region))
+
+	resourceMetrics := appendMessage(appendMessage(nil, 1, resource), 2, libraryMetrics)
+	request := appendMessage(nil, 1, resourceMetrics)
+
+	return protowire.AppendBytes(nil, request)
+}
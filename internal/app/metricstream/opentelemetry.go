@@ -0,0 +1,278 @@
+package metricstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of OpenTelemetry 0.7 protocol messages used by Cloudwatch metric streams
+// https://github.com/open-telemetry/opentelemetry-proto/tree/v0.7.0/opentelemetry/proto
+const (
+	exportRequestResourceMetrics  protowire.Number = 1
+	resourceMetricsResource       protowire.Number = 1
+	resourceMetricsLibraryMetrics protowire.Number = 2
+	resourceAttributes            protowire.Number = 1
+	keyValueKey                   protowire.Number = 1
+	keyValueValue                 protowire.Number = 2
+	anyValueString                protowire.Number = 1
+	libraryMetricsMetrics         protowire.Number = 2
+	metricDoubleSummary           protowire.Number = 11
+	summaryDataPoints             protowire.Number = 1
+	summaryDataPointLabels        protowire.Number = 1
+	summaryDataPointTimeUnixNano  protowire.Number = 3
+	summaryDataPointCount         protowire.Number = 4
+	summaryDataPointSum           protowire.Number = 5
+	stringKeyValueKey             protowire.Number = 1
+	stringKeyValueValue           protowire.Number = 2
+	accountIDAttribute            string           = "cloud.account.id"
+	regionAttribute               string           = "cloud.region"
+	namespaceLabel                string           = "Namespace"
+	metricNameLabel               string           = "MetricName"
+	dimensionsLabel               string           = "Dimensions"
+)
+
+// field is a decoded protocol buffer field, bytes is set for length delimited fields and value for numeric fields
+type field struct {
+	number protowire.Number
+	bytes  []byte
+	value  uint64
+}
+
+// decodeOpenTelemetry returns datapoints of a record, each record contains length delimited ExportMetricsServiceRequest messages
+func decodeOpenTelemetry(data []byte) ([]Datapoint, error) {
+	var datapoints []Datapoint
+
+	for len(data) > 0 {
+		message, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return nil, fmt.Errorf("can't read OpenTelemetry message: %w", protowire.ParseError(n))
+		}
+
+		data = data[n:]
+
+		err := consumeFields(message, func(f field) error {
+			if f.number != exportRequestResourceMetrics {
+				return nil
+			}
+
+			resourceDatapoints, err := decodeResourceMetrics(f.bytes)
+			datapoints = append(datapoints, resourceDatapoints...)
+
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("can't decode OpenTelemetry message: %w", err)
+		}
+	}
+
+	return datapoints, nil
+}
+
+// decodeResourceMetrics returns datapoints with the AWS account and region of the resource
+func decodeResourceMetrics(data []byte) ([]Datapoint, error) {
+	var (
+		attributes     map[string]string
+		libraryMetrics [][]byte
+		datapoints     []Datapoint
+	)
+
+	err := consumeFields(data, func(f field) error {
+		var err error
+
+		switch f.number {
+		case resourceMetricsResource:
+			attributes, err = decodeResource(f.bytes)
+		case resourceMetricsLibraryMetrics:
+			libraryMetrics = append(libraryMetrics, f.bytes)
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, metrics := range libraryMetrics {
+		err := consumeFields(metrics, func(f field) error {
+			if f.number != libraryMetricsMetrics {
+				return nil
+			}
+
+			metricDatapoints, err := decodeMetric(f.bytes)
+			datapoints = append(datapoints, metricDatapoints...)
+
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range datapoints {
+		datapoints[i].AccountID = attributes[accountIDAttribute]
+		datapoints[i].Region = attributes[regionAttribute]
+	}
+
+	return datapoints, nil
+}
+
+// decodeResource returns string attributes of the resource
+func decodeResource(data []byte) (map[string]string, error) {
+	attributes := make(map[string]string)
+
+	err := consumeFields(data, func(f field) error {
+		if f.number != resourceAttributes {
+			return nil
+		}
+
+		var key, value string
+
+		err := consumeFields(f.bytes, func(f field) error {
+			switch f.number {
+			case keyValueKey:
+				key = string(f.bytes)
+			case keyValueValue:
+				return consumeFields(f.bytes, func(f field) error {
+					if f.number == anyValueString {
+						value = string(f.bytes)
+					}
+
+					return nil
+				})
+			}
+
+			return nil
+		})
+
+		attributes[key] = value
+
+		return err
+	})
+
+	return attributes, err
+}
+
+// decodeMetric returns datapoints of the metric, Cloudwatch metric streams only use DoubleSummary metrics
+func decodeMetric(data []byte) ([]Datapoint, error) {
+	var datapoints []Datapoint
+
+	err := consumeFields(data, func(f field) error {
+		if f.number != metricDoubleSummary {
+			return nil
+		}
+
+		return consumeFields(f.bytes, func(f field) error {
+			if f.number != summaryDataPoints {
+				return nil
+			}
+
+			datapoint, err := decodeSummaryDataPoint(f.bytes)
+			if err != nil {
+				return err
+			}
+
+			datapoints = append(datapoints, datapoint)
+
+			return nil
+		})
+	})
+
+	return datapoints, err
+}
+
+// decodeSummaryDataPoint returns the datapoint, Cloudwatch metric identity is stored in labels
+func decodeSummaryDataPoint(data []byte) (Datapoint, error) {
+	var datapoint Datapoint
+
+	labels := make(map[string]string)
+
+	err := consumeFields(data, func(f field) error {
+		switch f.number {
+		case summaryDataPointLabels:
+			var key, value string
+
+			err := consumeFields(f.bytes, func(f field) error {
+				switch f.number {
+				case stringKeyValueKey:
+					key = string(f.bytes)
+				case stringKeyValueValue:
+					value = string(f.bytes)
+				}
+
+				return nil
+			})
+
+			labels[key] = value
+
+			return err
+		case summaryDataPointTimeUnixNano:
+			datapoint.Timestamp = time.Unix(0, int64(f.value))
+		case summaryDataPointCount:
+			datapoint.Count = float64(f.value)
+		case summaryDataPointSum:
+			datapoint.Sum = math.Float64frombits(f.value)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return datapoint, err
+	}
+
+	datapoint.Namespace = labels[namespaceLabel]
+	datapoint.MetricName = labels[metricNameLabel]
+
+	if dimensions, found := labels[dimensionsLabel]; found && dimensions != "" {
+		err := json.Unmarshal([]byte(dimensions), &datapoint.Dimensions)
+		if err != nil {
+			return datapoint, fmt.Errorf("can't decode dimensions: %w", err)
+		}
+	}
+
+	return datapoint, nil
+}
+
+// consumeFields calls fn for each field of the protocol buffer message
+func consumeFields(data []byte, fn func(field) error) error {
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+
+		data = data[n:]
+		f := field{number: number}
+
+		switch wireType {
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(data)
+		case protowire.VarintType:
+			f.value, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed64Type:
+			f.value, n = protowire.ConsumeFixed64(data)
+		case protowire.Fixed32Type:
+			var value uint32
+			value, n = protowire.ConsumeFixed32(data)
+			f.value = uint64(value)
+		default:
+			n = protowire.ConsumeFieldValue(number, wireType, data)
+		}
+
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+
+		data = data[n:]
+
+		err := fn(f)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package pi
+
+import (
+	aws_pi_types "github.com/aws/aws-sdk-go-v2/service/pi/types"
+)
+
+// getLatestValue returns the most recent non empty value of the data points
+func getLatestValue(dataPoints []aws_pi_types.DataPoint) (float64, bool) {
+	for i := len(dataPoints) - 1; i >= 0; i-- {
+		if dataPoints[i].Value != nil {
+			return *dataPoints[i].Value, true
+		}
+	}
+
+	return 0, false
+}
+
+// truncateStatement limits the SQL statement length to keep Prometheus label values readable
+func truncateStatement(statement string) string {
+	runes := []rune(statement)
+	if len(runes) <= maxSQLStatementLength {
+		return statement
+	}
+
+	return string(runes[:maxSQLStatementLength]) + truncatedStatementMark
+}
@@ -0,0 +1,103 @@
+// Package mocks contains mock for Performance Insights client
+package mocks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/pi"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_pi "github.com/aws/aws-sdk-go-v2/service/pi"
+	aws_pi_types "github.com/aws/aws-sdk-go-v2/service/pi/types"
+)
+
+// Defines expected values for the mock and tests
+//
+//nolint:golint,gomnd
+var (
+	WaitEventCPU = pi.WaitEventMetrics{
+		Name:   "CPU",
+		Type:   "CPU",
+		DBLoad: 1.5,
+	}
+	WaitEventIO = pi.WaitEventMetrics{
+		Name:   "IO:DataFileRead",
+		Type:   "IO",
+		DBLoad: 0.5,
+	}
+	TopSQL = pi.SQLMetrics{
+		ID:        "B2FD6EB7AB0F2E8AC1DDAE3D0D8D26A1D7D4A5E1",
+		Statement: "SELECT * FROM users WHERE id = ?",
+		DBLoad:    1.2,
+	}
+)
+
+// FailingResourceID is a resource ID on which Performance Insights calls fail
+const FailingResourceID = "db-FAILING"
+
+var errNotAuthorized = errors.New("NotAuthorizedException: Performance Insights is not enabled")
+
+type PIClient struct{}
+
+// GetResourceMetrics returns the total DB load followed by DB load grouped by wait events
+func (m PIClient) GetResourceMetrics(ctx context.Context, input *aws_pi.GetResourceMetricsInput, optFns ...func(*aws_pi.Options)) (*aws_pi.GetResourceMetricsOutput, error) {
+	if aws.ToString(input.Identifier) == FailingResourceID {
+		return nil, errNotAuthorized
+	}
+
+	now := time.Now()
+	previous := now.Add(-time.Minute)
+
+	return &aws_pi.GetResourceMetricsOutput{
+		MetricList: []aws_pi_types.MetricKeyDataPoints{
+			{
+				Key: &aws_pi_types.ResponseResourceMetricKey{Metric: aws.String(pi.DBLoadMetric)},
+				DataPoints: []aws_pi_types.DataPoint{
+					{Timestamp: &now, Value: aws.Float64(WaitEventCPU.DBLoad + WaitEventIO.DBLoad)},
+				},
+			},
+			{
+				Key: &aws_pi_types.ResponseResourceMetricKey{
+					Metric: aws.String(pi.DBLoadMetric),
+					Dimensions: map[string]string{
+						pi.WaitEventNameDimension: WaitEventCPU.Name,
+						pi.WaitEventTypeDimension: WaitEventCPU.Type,
+					},
+				},
+				DataPoints: []aws_pi_types.DataPoint{
+					{Timestamp: &previous, Value: aws.Float64(42)},
+					{Timestamp: &now, Value: aws.Float64(WaitEventCPU.DBLoad)},
+				},
+			},
+			{
+				Key: &aws_pi_types.ResponseResourceMetricKey{
+					Metric: aws.String(pi.DBLoadMetric),
+					Dimensions: map[string]string{
+						pi.WaitEventNameDimension: WaitEventIO.Name,
+						pi.WaitEventTypeDimension: WaitEventIO.Type,
+					},
+				},
+				DataPoints: []aws_pi_types.DataPoint{
+					{Timestamp: &previous, Value: aws.Float64(WaitEventIO.DBLoad)},
+					{Timestamp: &now, Value: nil},
+				},
+			},
+		},
+	}, nil
+}
+
+// DescribeDimensionKeys returns the top SQL digests
+func (m PIClient) DescribeDimensionKeys(ctx context.Context, input *aws_pi.DescribeDimensionKeysInput, optFns ...func(*aws_pi.Options)) (*aws_pi.DescribeDimensionKeysOutput, error) {
+	return &aws_pi.DescribeDimensionKeysOutput{
+		Keys: []aws_pi_types.DimensionKeyDescription{
+			{
+				Dimensions: map[string]string{
+					pi.SQLIDDimension:        TopSQL.ID,
+					pi.SQLStatementDimension: TopSQL.Statement,
+				},
+				Total: aws.Float64(TopSQL.DBLoad),
+			},
+		},
+	}, nil
+}
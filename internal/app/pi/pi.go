@@ -0,0 +1,220 @@
+// Package pi implements methods to retrieve AWS Performance Insights information
+package pi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/iam"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	aws_pi "github.com/aws/aws-sdk-go-v2/service/pi"
+	aws_pi_types "github.com/aws/aws-sdk-go-v2/service/pi/types"
+)
+
+const (
+	DBLoadMetric           string = "db.load.avg"
+	WaitEventGroup         string = "db.wait_event"
+	WaitEventNameDimension string = "db.wait_event.name"
+	WaitEventTypeDimension string = "db.wait_event.type"
+	SQLGroup               string = "db.sql_tokenized"
+	SQLIDDimension         string = "db.sql_tokenized.id"
+	SQLStatementDimension  string = "db.sql_tokenized.statement"
+
+	Minute                 int32  = 60
+	lookbackPeriod                = 5 * time.Minute
+	maxWaitEvents          int32  = 10  // Number of wait events returned by instance
+	minTopSQL              int32  = 1   // AWS API limit
+	maxTopSQL              int32  = 25  // AWS API limit
+	maxSQLStatementLength  int    = 200 // Truncate SQL statements to keep label values readable
+	truncatedStatementMark string = "..."
+)
+
+type Configuration struct {
+	TopSQL int32
+}
+
+type WaitEventMetrics struct {
+	Name   string
+	Type   string
+	DBLoad float64
+}
+
+type SQLMetrics struct {
+	ID        string
+	Statement string
+	DBLoad    float64
+}
+
+type InstanceMetrics struct {
+	WaitEvents []WaitEventMetrics
+	TopSQL     []SQLMetrics
+}
+
+type Metrics struct {
+	Instances map[string]InstanceMetrics
+}
+
+type Statistics struct {
+	PIAPICall float64
+}
+
+type PIClient interface {
+	GetResourceMetrics(ctx context.Context, params *aws_pi.GetResourceMetricsInput, optFns ...func(*aws_pi.Options)) (*aws_pi.GetResourceMetricsOutput, error)
+	DescribeDimensionKeys(ctx context.Context, params *aws_pi.DescribeDimensionKeysInput, optFns ...func(*aws_pi.Options)) (*aws_pi.DescribeDimensionKeysOutput, error)
+}
+
+// Permissions returns AWS IAM statements required by the fetcher on resources of the AWS partition
+func Permissions(partition string) []iam.Statement {
+	return []iam.Statement{
+		iam.Allow("AllowPerformanceInsightsMetrics", []string{"pi:GetResourceMetrics", "pi:DescribeDimensionKeys"}, []string{iam.ResourceARN(partition, "pi", "metrics/rds/*")}),
+	}
+}
+
+func NewFetcher(client PIClient, configuration Configuration) *PIFetcher {
+	return &PIFetcher{
+		client:        client,
+		configuration: configuration,
+	}
+}
+
+type PIFetcher struct {
+	client        PIClient
+	statistics    Statistics
+	configuration Configuration
+}
+
+func (p *PIFetcher) GetStatistics() Statistics {
+	return p.statistics
+}
+
+// GetInstancesMetrics returns DB load details for the specified instances
+// instances maps DB identifiers to their DbiResourceId, the identifier used by Performance Insights API
+// Instances failing (eg. Performance Insights just disabled or throttled calls) are skipped, metrics of other instances are returned with the joined errors
+func (p *PIFetcher) GetInstancesMetrics(instances map[string]string) (Metrics, error) {
+	metrics := make(map[string]InstanceMetrics)
+
+	endTime := time.Now()
+	startTime := endTime.Add(-lookbackPeriod)
+
+	var errs []error
+
+	for dbIdentifier, resourceID := range instances {
+		waitEvents, err := p.getWaitEvents(resourceID, startTime, endTime)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("can't fetch wait events for %s: %w", dbIdentifier, err))
+
+			continue
+		}
+
+		topSQL, err := p.getTopSQL(resourceID, startTime, endTime)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("can't fetch top SQL for %s: %w", dbIdentifier, err))
+
+			continue
+		}
+
+		metrics[dbIdentifier] = InstanceMetrics{
+			WaitEvents: waitEvents,
+			TopSQL:     topSQL,
+		}
+	}
+
+	return Metrics{Instances: metrics}, errors.Join(errs...)
+}
+
+// getWaitEvents returns the latest DB load value of the instance grouped by wait event
+func (p *PIFetcher) getWaitEvents(resourceID string, startTime time.Time, endTime time.Time) ([]WaitEventMetrics, error) {
+	input := &aws_pi.GetResourceMetricsInput{
+		ServiceType:     aws_pi_types.ServiceTypeRds,
+		Identifier:      aws.String(resourceID),
+		StartTime:       aws.Time(startTime),
+		EndTime:         aws.Time(endTime),
+		PeriodInSeconds: aws.Int32(Minute),
+		MetricQueries: []aws_pi_types.MetricQuery{
+			{
+				Metric: aws.String(DBLoadMetric),
+				GroupBy: &aws_pi_types.DimensionGroup{
+					Group: aws.String(WaitEventGroup),
+					Limit: aws.Int32(maxWaitEvents),
+				},
+			},
+		},
+	}
+
+	p.statistics.PIAPICall++
+
+	output, err := p.client.GetResourceMetrics(context.TODO(), input)
+	if err != nil {
+		return nil, fmt.Errorf("error calling GetResourceMetrics: %w", err)
+	}
+
+	var waitEvents []WaitEventMetrics
+
+	for _, metric := range output.MetricList {
+		// The total DB load is returned along with the grouped metrics, it's already exported by Cloudwatch metrics
+		if metric.Key == nil || metric.Key.Dimensions == nil {
+			continue
+		}
+
+		value, found := getLatestValue(metric.DataPoints)
+		if !found {
+			continue
+		}
+
+		waitEvents = append(waitEvents, WaitEventMetrics{
+			Name:   metric.Key.Dimensions[WaitEventNameDimension],
+			Type:   metric.Key.Dimensions[WaitEventTypeDimension],
+			DBLoad: value,
+		})
+	}
+
+	return waitEvents, nil
+}
+
+// getTopSQL returns the SQL digests generating the most DB load on the instance
+func (p *PIFetcher) getTopSQL(resourceID string, startTime time.Time, endTime time.Time) ([]SQLMetrics, error) {
+	limit := p.configuration.TopSQL
+	switch {
+	case limit < minTopSQL:
+		limit = minTopSQL
+	case limit > maxTopSQL:
+		limit = maxTopSQL
+	}
+
+	input := &aws_pi.DescribeDimensionKeysInput{
+		ServiceType: aws_pi_types.ServiceTypeRds,
+		Identifier:  aws.String(resourceID),
+		StartTime:   aws.Time(startTime),
+		EndTime:     aws.Time(endTime),
+		Metric:      aws.String(DBLoadMetric),
+		GroupBy: &aws_pi_types.DimensionGroup{
+			Group: aws.String(SQLGroup),
+			Limit: aws.Int32(limit),
+		},
+	}
+
+	p.statistics.PIAPICall++
+
+	output, err := p.client.DescribeDimensionKeys(context.TODO(), input)
+	if err != nil {
+		return nil, fmt.Errorf("error calling DescribeDimensionKeys: %w", err)
+	}
+
+	var topSQL []SQLMetrics
+
+	for _, key := range output.Keys {
+		if key.Total == nil {
+			continue
+		}
+
+		topSQL = append(topSQL, SQLMetrics{
+			ID:        key.Dimensions[SQLIDDimension],
+			Statement: truncateStatement(key.Dimensions[SQLStatementDimension]),
+			DBLoad:    *key.Total,
+		})
+	}
+
+	return topSQL, nil
+}
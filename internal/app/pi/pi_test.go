@@ -0,0 +1,43 @@
+package pi_test
+
+import (
+	"testing"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/pi"
+	mock "github.com/TeiNam/prometheus-rds-exporter/internal/app/pi/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetInstancesMetrics(t *testing.T) {
+	client := mock.PIClient{}
+	instances := map[string]string{"db1": "db-ABCDEFGHIJKLMNOPQRSTUVWXYZ"}
+
+	fetcher := pi.NewFetcher(client, pi.Configuration{TopSQL: 10})
+	result, err := fetcher.GetInstancesMetrics(instances)
+
+	require.NoError(t, err, "GetInstancesMetrics must succeed")
+	assert.Equal(t, float64(2), fetcher.GetStatistics().PIAPICall, "Two calls to Performance Insights API")
+
+	waitEvents := result.Instances["db1"].WaitEvents
+	require.Len(t, waitEvents, 2, "Total DB load must be ignored")
+	assert.Equal(t, mock.WaitEventCPU, waitEvents[0], "Latest wait event value should be used")
+	assert.Equal(t, mock.WaitEventIO, waitEvents[1], "Empty data points should be ignored")
+
+	topSQL := result.Instances["db1"].TopSQL
+	require.Len(t, topSQL, 1, "Top SQL mismatch")
+	assert.Equal(t, mock.TopSQL, topSQL[0], "Top SQL mismatch")
+}
+
+func TestGetInstancesMetricsPartialFailure(t *testing.T) {
+	client := mock.PIClient{}
+	instances := map[string]string{"db1": "db-ABCDEFGHIJKLMNOPQRSTUVWXYZ", "db2": mock.FailingResourceID}
+
+	fetcher := pi.NewFetcher(client, pi.Configuration{TopSQL: 10})
+	result, err := fetcher.GetInstancesMetrics(instances)
+
+	require.Error(t, err, "Failing instance must be reported")
+	assert.Contains(t, err.Error(), "db2", "Error must contain the failing instance")
+	assert.Contains(t, result.Instances, "db1", "Metrics of other instances must be returned")
+	assert.NotContains(t, result.Instances, "db2", "Failing instance must be skipped")
+}
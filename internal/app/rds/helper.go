@@ -37,6 +37,17 @@ func GetDBInstanceStatusCode(status string) int {
 	return instanceStatus
 }
 
+// GetDBInstanceStatusName returns the RDS status of an instance status code
+func GetDBInstanceStatusName(code int) string {
+	for status, statusCode := range instanceStatuses {
+		if statusCode == code {
+			return status
+		}
+	}
+
+	return "unknown"
+}
+
 // getStorageMetrics returns storage metrics following AWS rules
 func getStorageMetrics(storageType string, allocatedStorage int64, rawIops int64, rawStorageThroughput int64) (int64, int64) {
 	// IOPS and throughput depends of the RDS storage class type and the allocated storage
@@ -44,6 +55,11 @@ func getStorageMetrics(storageType string, allocatedStorage int64, rawIops int64
 	var iops, storageThroughput int64
 
 	switch storageType {
+	case AuroraStandardStorageType, AuroraIOOptimizedStorageType:
+		// Aurora cluster volumes scale automatically and have no IOPS or throughput limit, only instance class limits apply
+		// https://docs.aws.amazon.com/AmazonRDS/latest/AuroraUserGuide/Aurora.Overview.StorageReliability.html
+		iops = 0
+		storageThroughput = 0
 	case "gp2":
 		/*
 			Baseline IOPS performance scales linearly between a minimum of 100 and a maximum of 16,000 at a rate of 3 IOPS per GiB of volume size. IOPS performance is provisioned as follows:
@@ -107,6 +123,57 @@ func getStorageMetrics(storageType string, allocatedStorage int64, rawIops int64
 	return iops, storageThroughput
 }
 
+// IsServerlessInstanceClass returns true for Aurora Serverless v2 instance class
+func IsServerlessInstanceClass(instanceClass string) bool {
+	return instanceClass == ServerlessInstanceClass
+}
+
+// IsAuroraStorageType returns true for Aurora cluster volume storage types
+func IsAuroraStorageType(storageType string) bool {
+	return storageType == AuroraStandardStorageType || storageType == AuroraIOOptimizedStorageType
+}
+
+// AuroraStoragePricing contains Aurora storage prices in USD, they depend on the AWS region
+// https://aws.amazon.com/rds/aurora/pricing/
+type AuroraStoragePricing struct {
+	StandardStoragePerGB    float64 // Aurora Standard storage price per GB-month
+	StandardIOPerMillion    float64 // Aurora Standard price per million I/O requests
+	IOOptimizedStoragePerGB float64 // Aurora I/O-Optimized storage price per GB-month, I/O requests are free
+}
+
+// AuroraStorageMonthlyCosts returns estimated monthly storage costs of an Aurora cluster volume with Aurora Standard and Aurora I/O-Optimized storage
+// ioPerInterval is the number of billed I/O requests per 5 minutes interval, as reported by VolumeReadIOPs and VolumeWriteIOPs metrics
+// Instance costs, which are higher with Aurora I/O-Optimized, are not included
+func AuroraStorageMonthlyCosts(pricing AuroraStoragePricing, volumeBytes float64, ioPerInterval float64) (float64, float64) {
+	volumeGB := volumeBytes / converter.GigaBytesToBytes(float64(1))
+	monthlyIO := ioPerInterval * volumeIOIntervalsPerHour * hoursPerMonth
+
+	standard := volumeGB*pricing.StandardStoragePerGB + monthlyIO/ioPerMillion*pricing.StandardIOPerMillion
+	ioOptimized := volumeGB * pricing.IOOptimizedStoragePerGB
+
+	return standard, ioOptimized
+}
+
+// IsBurstableInstanceClass returns true for burstable performance instance classes (db.t*) using CPU credits
+func IsBurstableInstanceClass(instanceClass string) bool {
+	return strings.HasPrefix(instanceClass, BurstableInstanceClassPrefix)
+}
+
+// IsBurstableStorageType returns true for storage types using I/O credits
+func IsBurstableStorageType(storageType string) bool {
+	return storageType == "gp2"
+}
+
+// ServerlessMemory returns the approximate memory in bytes provided by Aurora capacity units
+func ServerlessMemory(capacity float64) float64 {
+	return capacity * serverlessMemoryPerACU
+}
+
+// ServerlessVCPU returns the approximate number of vCPU provided by Aurora capacity units
+func ServerlessVCPU(capacity float64) float64 {
+	return capacity * serverlessVCPUPerACU
+}
+
 // getRoleInCluster returns role and source of the specified instance in the the cluster
 func getRoleInCluster(instance *aws_rds_types.DBInstance) (string, string) {
 	var (
@@ -17,6 +17,8 @@ type RDSClient struct {
 	DescribePendingMaintenanceActionsOutput *aws_rds.DescribePendingMaintenanceActionsOutput
 	DescribeDBLogFilesOutput                *aws_rds.DescribeDBLogFilesOutput
 	DescribeDBLogFilesOutputError           error
+	DescribeDBClustersOutput                *aws_rds.DescribeDBClustersOutput
+	DescribeDBClustersOutputError           error
 	Error                                   error
 }
 
@@ -34,6 +36,18 @@ func (m RDSClient) DescribeDBLogFiles(ctx context.Context, input *aws_rds.Descri
 	return m.DescribeDBLogFilesOutput, m.DescribeDBLogFilesOutputError
 }
 
+func (m RDSClient) DescribeDBClusters(context.Context, *aws_rds.DescribeDBClustersInput, ...func(*aws_rds.Options)) (*aws_rds.DescribeDBClustersOutput, error) {
+	if m.DescribeDBClustersOutputError != nil {
+		return nil, m.DescribeDBClustersOutputError
+	}
+
+	if m.DescribeDBClustersOutput == nil {
+		return &aws_rds.DescribeDBClustersOutput{}, nil
+	}
+
+	return m.DescribeDBClustersOutput, nil
+}
+
 func (m RDSClient) DescribeDBInstances(context.Context, *aws_rds.DescribeDBInstancesInput, ...func(*aws_rds.Options)) (*aws_rds.DescribeDBInstancesOutput, error) {
 	return m.DescribeDBInstancesOutput, nil
 }
@@ -78,6 +92,7 @@ func NewRdsInstance() *aws_rds_types.DBInstance {
 		DBInstanceStatus:           aws.String("available"),
 		DbiResourceId:              aws.String("resource1"),
 		DeletionProtection:         aws.Bool(true),
+		Endpoint:                   &aws_rds_types.Endpoint{Address: aws.String(DBInstanceIdentifier + ".abcdefghijkl.eu-west-3.rds.amazonaws.com"), Port: aws.Int32(5432)},
 		Engine:                     aws.String("postgres"),
 		EngineVersion:              aws.String("14.9"),
 		Iops:                       aws.Int32(3000),
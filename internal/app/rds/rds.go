@@ -8,12 +8,16 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/iam"
 	converter "github.com/TeiNam/prometheus-rds-exporter/internal/app/unit"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	aws_rds "github.com/aws/aws-sdk-go-v2/service/rds"
 	aws_rds_types "github.com/aws/aws-sdk-go-v2/service/rds/types"
 )
 
+// ErrServerlessCapacities is returned along with instance metrics when Aurora Serverless v2 capacities can't be fetched
+var ErrServerlessCapacities = errors.New("can't get serverless capacities")
+
 type Configuration struct {
 	CollectLogsSize     bool
 	CollectMaintenances bool
@@ -32,7 +36,10 @@ type RdsInstanceMetrics struct {
 	Engine                           string
 	EngineVersion                    string
 	DBInstanceClass                  string
+	DBClusterIdentifier              string
 	DbiResourceID                    string
+	EndpointAddress                  string // Empty while the instance is being created
+	EndpointPort                     int32
 	StorageType                      string
 	AllocatedStorage                 int64
 	StorageThroughput                int64
@@ -54,6 +61,13 @@ type RdsInstanceMetrics struct {
 	CertificateValidTill             *time.Time
 	Age                              *float64
 	Tags                             map[string]string
+	ServerlessMinCapacity            *float64 // Minimum Aurora capacity units (ACU) for serverless instances
+	ServerlessMaxCapacity            *float64 // Maximum Aurora capacity units (ACU) for serverless instances
+}
+
+type serverlessScalingConfiguration struct {
+	MinCapacity *float64
+	MaxCapacity *float64
 }
 
 const (
@@ -89,6 +103,15 @@ const (
 	io2StorageThroughputPerIOPS            float64 = 0.256
 	primaryRole                            string  = "primary"
 	replicaRole                            string  = "replica"
+	ServerlessInstanceClass                string  = "db.serverless"
+	BurstableInstanceClassPrefix           string  = "db.t"
+	AuroraStandardStorageType              string  = "aurora"
+	AuroraIOOptimizedStorageType           string  = "aurora-iopt1"
+	hoursPerMonth                          float64 = 730 // AWS pricing convention
+	volumeIOIntervalsPerHour               float64 = 12  // Aurora volume I/O metrics are reported per 5 minutes interval
+	ioPerMillion                           float64 = 1000000
+	serverlessMemoryPerACU                 float64 = 2 * 1024 * 1024 * 1024 // Each ACU provides approximately 2 GiB of memory
+	serverlessVCPUPerACU                   float64 = 0.25                   // Approximation based on memory optimized instance classes (8 GiB per vCPU)
 )
 
 var instanceStatuses = map[string]int{
@@ -107,6 +130,27 @@ type RDSClient interface {
 	DescribeDBInstances(ctx context.Context, params *aws_rds.DescribeDBInstancesInput, optFns ...func(*aws_rds.Options)) (*aws_rds.DescribeDBInstancesOutput, error)
 	DescribePendingMaintenanceActions(context.Context, *aws_rds.DescribePendingMaintenanceActionsInput, ...func(*aws_rds.Options)) (*aws_rds.DescribePendingMaintenanceActionsOutput, error)
 	DescribeDBLogFiles(context.Context, *aws_rds.DescribeDBLogFilesInput, ...func(*aws_rds.Options)) (*aws_rds.DescribeDBLogFilesOutput, error)
+	DescribeDBClusters(context.Context, *aws_rds.DescribeDBClustersInput, ...func(*aws_rds.Options)) (*aws_rds.DescribeDBClustersOutput, error)
+}
+
+// Permissions returns AWS IAM statements required by the fetcher on resources of the AWS partition
+// Clusters are described to get the scaling configuration of Aurora Serverless v2 instances
+func Permissions(configuration Configuration, partition string) []iam.Statement {
+	instanceActions := []string{"rds:DescribeDBInstances"}
+	if configuration.CollectLogsSize {
+		instanceActions = append(instanceActions, "rds:DescribeDBLogFiles")
+	}
+
+	statements := []iam.Statement{
+		iam.Allow("AllowInstanceAndLogDescriptions", instanceActions, []string{iam.ResourceARN(partition, "rds", "db:*")}),
+		iam.Allow("AllowClusterDescriptions", []string{"rds:DescribeDBClusters"}, []string{iam.ResourceARN(partition, "rds", "cluster:*")}),
+	}
+
+	if configuration.CollectMaintenances {
+		statements = append(statements, iam.Allow("AllowMaintenanceDescriptions", []string{"rds:DescribePendingMaintenanceActions"}, iam.AllResources))
+	}
+
+	return statements
 }
 
 func NewFetcher(client RDSClient, configuration Configuration) RDSFetcher {
@@ -201,9 +245,82 @@ func (r *RDSFetcher) GetInstancesMetrics() (Metrics, error) {
 		}
 	}
 
+	err = r.addServerlessCapacities(metrics)
+	if err != nil {
+		// Instance metrics are still valid, only serverless instances miss their capacities
+		return Metrics{Instances: metrics}, fmt.Errorf("%w: %w", ErrServerlessCapacities, err)
+	}
+
 	return Metrics{Instances: metrics}, nil
 }
 
+// addServerlessCapacities adds the cluster's Aurora Serverless v2 scaling configuration to serverless instances
+func (r *RDSFetcher) addServerlessCapacities(metrics map[string]RdsInstanceMetrics) error {
+	hasServerlessInstances := false
+
+	for _, instance := range metrics {
+		if IsServerlessInstanceClass(instance.DBInstanceClass) {
+			hasServerlessInstances = true
+
+			break
+		}
+	}
+
+	// Avoid useless API calls
+	if !hasServerlessInstances {
+		return nil
+	}
+
+	scalingConfigurations, err := r.getServerlessScalingConfigurations()
+	if err != nil {
+		return err
+	}
+
+	for dbIdentifier, instance := range metrics {
+		if !IsServerlessInstanceClass(instance.DBInstanceClass) {
+			continue
+		}
+
+		if scalingConfiguration, found := scalingConfigurations[instance.DBClusterIdentifier]; found {
+			instance.ServerlessMinCapacity = scalingConfiguration.MinCapacity
+			instance.ServerlessMaxCapacity = scalingConfiguration.MaxCapacity
+			metrics[dbIdentifier] = instance
+		}
+	}
+
+	return nil
+}
+
+// getServerlessScalingConfigurations returns Aurora Serverless v2 scaling configuration of clusters
+func (r *RDSFetcher) getServerlessScalingConfigurations() (map[string]serverlessScalingConfiguration, error) {
+	scalingConfigurations := make(map[string]serverlessScalingConfiguration)
+
+	input := &aws_rds.DescribeDBClustersInput{}
+
+	paginator := aws_rds.NewDescribeDBClustersPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		r.statistics.RdsAPICall++
+
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("can't describe clusters: %w", err)
+		}
+
+		for _, cluster := range output.DBClusters {
+			if cluster.DBClusterIdentifier == nil || cluster.ServerlessV2ScalingConfiguration == nil {
+				continue
+			}
+
+			scalingConfigurations[*cluster.DBClusterIdentifier] = serverlessScalingConfiguration{
+				MinCapacity: cluster.ServerlessV2ScalingConfiguration.MinCapacity,
+				MaxCapacity: cluster.ServerlessV2ScalingConfiguration.MaxCapacity,
+			}
+		}
+	}
+
+	return scalingConfigurations, nil
+}
+
 // computeInstanceMetrics returns metrics about the specified instance
 func (r *RDSFetcher) computeInstanceMetrics(dbInstance aws_rds_types.DBInstance, instanceMaintenances map[string]string) (RdsInstanceMetrics, error) {
 	dbIdentifier := dbInstance.DBInstanceIdentifier
@@ -274,6 +391,16 @@ func (r *RDSFetcher) computeInstanceMetrics(dbInstance aws_rds_types.DBInstance,
 		certificateValidTill = dbInstance.CertificateDetails.ValidTill
 	}
 
+	var (
+		endpointAddress string
+		endpointPort    int32
+	)
+
+	if dbInstance.Endpoint != nil {
+		endpointAddress = aws.ToString(dbInstance.Endpoint.Address)
+		endpointPort = aws.ToInt32(dbInstance.Endpoint.Port)
+	}
+
 	tags := make(map[string]string)
 
 	for _, tag := range dbInstance.TagList {
@@ -285,8 +412,11 @@ func (r *RDSFetcher) computeInstanceMetrics(dbInstance aws_rds_types.DBInstance,
 		AllocatedStorage:           converter.GigaBytesToBytes(int64(*dbInstance.AllocatedStorage)),
 		BackupRetentionPeriod:      converter.DaystoSeconds(*dbInstance.BackupRetentionPeriod),
 		DBInstanceClass:            *dbInstance.DBInstanceClass,
+		DBClusterIdentifier:        aws.ToString(dbInstance.DBClusterIdentifier),
 		DbiResourceID:              *dbInstance.DbiResourceId,
 		DeletionProtection:         aws.ToBool(dbInstance.DeletionProtection),
+		EndpointAddress:            endpointAddress,
+		EndpointPort:               endpointPort,
 		Engine:                     *dbInstance.Engine,
 		EngineVersion:              *dbInstance.EngineVersion,
 		LogFilesSize:               logFilesSize,
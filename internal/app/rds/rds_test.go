@@ -1,6 +1,7 @@
 package rds_test
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -47,6 +48,8 @@ func TestGetMetrics(t *testing.T) {
 	assert.Equal(t, *rdsInstance.PerformanceInsightsEnabled, m.PerformanceInsightsEnabled, "PerformanceInsights enabled mismatch")
 	assert.Equal(t, *rdsInstance.PubliclyAccessible, m.PubliclyAccessible, "PubliclyAccessible mismatch")
 	assert.Equal(t, *rdsInstance.DbiResourceId, m.DbiResourceID, "DbiResourceId mismatch")
+	assert.Equal(t, *rdsInstance.Endpoint.Address, m.EndpointAddress, "Endpoint address mismatch")
+	assert.Equal(t, *rdsInstance.Endpoint.Port, m.EndpointPort, "Endpoint port mismatch")
 	assert.Equal(t, *rdsInstance.DBInstanceClass, m.DBInstanceClass, "DBInstanceIdentifier mismatch")
 	assert.Equal(t, *rdsInstance.CACertificateIdentifier, m.CACertificateIdentifier, "CACertificateIdentifier mismatch")
 	assert.Equal(t, *rdsInstance.CertificateDetails.ValidTill, *m.CertificateValidTill, "CertificateValidTill mismatch")
@@ -268,6 +271,12 @@ func TestGetDBInstanceStatusCode(t *testing.T) {
 	}
 }
 
+func TestGetDBInstanceStatusName(t *testing.T) {
+	assert.Equal(t, "available", rds.GetDBInstanceStatusName(rds.InstanceStatusAvailable), "Status name mismatch")
+	assert.Equal(t, "stopped", rds.GetDBInstanceStatusName(rds.InstanceStatusStopped), "Status name mismatch")
+	assert.Equal(t, "unknown", rds.GetDBInstanceStatusName(42), "Unknown status code should be unknown")
+}
+
 func TestPendingModification(t *testing.T) {
 	// Mock RDS instance
 	rdsInstance := mock.NewRdsInstance()
@@ -330,3 +339,95 @@ func TestInstanceAge(t *testing.T) {
 	require.NoError(t, err, "GetInstancesMetrics must succeed")
 	assert.Equal(t, int(expectedAge.Seconds()), int(*metrics.Instances[*rdsInstance.DBInstanceIdentifier].Age), "Age should match expected age")
 }
+
+func TestServerlessInstance(t *testing.T) {
+	rdsInstance := mock.NewRdsInstance()
+	rdsInstance.DBInstanceClass = aws.String("db.serverless")
+	rdsInstance.DBClusterIdentifier = aws.String("cluster1")
+	rdsInstance.Engine = aws.String("aurora-postgresql")
+	rdsInstance.StorageType = aws.String("aurora")
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+	mockDescribeDBClustersOutput := &aws_rds.DescribeDBClustersOutput{DBClusters: []aws_rds_types.DBCluster{
+		{
+			DBClusterIdentifier:              aws.String("cluster1"),
+			ServerlessV2ScalingConfiguration: &aws_rds_types.ServerlessV2ScalingConfigurationInfo{MinCapacity: aws.Float64(0.5), MaxCapacity: aws.Float64(16)},
+		},
+	}}
+
+	client := mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput, DescribeDBClustersOutput: mockDescribeDBClustersOutput}
+	configuration := rds.Configuration{}
+	fetcher := rds.NewFetcher(client, configuration)
+	metrics, err := fetcher.GetInstancesMetrics()
+
+	require.NoError(t, err, "GetInstancesMetrics must succeed")
+
+	m := metrics.Instances[*rdsInstance.DBInstanceIdentifier]
+	assert.Equal(t, "cluster1", m.DBClusterIdentifier, "Cluster identifier mismatch")
+	assert.Equal(t, aws.Float64(0.5), m.ServerlessMinCapacity, "Minimum capacity mismatch")
+	assert.Equal(t, aws.Float64(16), m.ServerlessMaxCapacity, "Maximum capacity mismatch")
+}
+
+func TestServerlessInstanceWithoutCapacities(t *testing.T) {
+	rdsInstance := mock.NewRdsInstance()
+	rdsInstance.DBInstanceClass = aws.String("db.serverless")
+	rdsInstance.DBClusterIdentifier = aws.String("cluster1")
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	client := mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput, DescribeDBClustersOutputError: errors.New("throttled")}
+	fetcher := rds.NewFetcher(client, rds.Configuration{})
+	metrics, err := fetcher.GetInstancesMetrics()
+
+	require.ErrorIs(t, err, rds.ErrServerlessCapacities, "Missing serverless capacities should be reported")
+	require.Contains(t, metrics.Instances, *rdsInstance.DBInstanceIdentifier, "Instance metrics should be kept")
+	assert.Nil(t, metrics.Instances[*rdsInstance.DBInstanceIdentifier].ServerlessMinCapacity, "Serverless capacity should be unknown")
+}
+
+func TestProvisionedInstanceDoesNotDescribeClusters(t *testing.T) {
+	rdsInstance := mock.NewRdsInstance()
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*rdsInstance}}
+
+	client := mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput}
+	fetcher := rds.NewFetcher(client, rds.Configuration{})
+	metrics, err := fetcher.GetInstancesMetrics()
+
+	require.NoError(t, err, "GetInstancesMetrics must succeed")
+	assert.Nil(t, metrics.Instances[*rdsInstance.DBInstanceIdentifier].ServerlessMaxCapacity, "Provisioned instance has no serverless capacity")
+	assert.Equal(t, float64(1), fetcher.GetStatistics().RdsAPICall, "Clusters must not be described without serverless instances")
+}
+
+func TestServerlessCapacityConversion(t *testing.T) {
+	assert.Equal(t, float64(4*1024*1024*1024), rds.ServerlessMemory(2), "Memory mismatch")
+	assert.Equal(t, float64(1), rds.ServerlessVCPU(4), "vCPU mismatch")
+}
+
+func TestAuroraStorageType(t *testing.T) {
+	auroraInstance := mock.NewRdsInstance()
+	auroraInstance.StorageType = aws.String("aurora-iopt1")
+	auroraInstance.AllocatedStorage = aws.Int32(1)
+
+	mockDescribeDBInstancesOutput := &aws_rds.DescribeDBInstancesOutput{DBInstances: []aws_rds_types.DBInstance{*auroraInstance}}
+	client := mock.RDSClient{DescribeDBInstancesOutput: mockDescribeDBInstancesOutput}
+	configuration := rds.Configuration{}
+	fetcher := rds.NewFetcher(client, configuration)
+	metrics, err := fetcher.GetInstancesMetrics()
+
+	require.NoError(t, err, "GetInstancesMetrics must succeed")
+	assert.Equal(t, int64(0), metrics.Instances[*auroraInstance.DBInstanceIdentifier].MaxIops, "Aurora cluster volume has no IOPS limit")
+	assert.Equal(t, int64(0), metrics.Instances[*auroraInstance.DBInstanceIdentifier].StorageThroughput, "Aurora cluster volume has no throughput limit")
+	assert.True(t, rds.IsAuroraStorageType("aurora"), "aurora is an Aurora storage type")
+	assert.False(t, rds.IsAuroraStorageType("gp3"), "gp3 is not an Aurora storage type")
+}
+
+func TestAuroraStorageMonthlyCosts(t *testing.T) {
+	pricing := rds.AuroraStoragePricing{
+		StandardStoragePerGB:    0.10,
+		StandardIOPerMillion:    0.20,
+		IOOptimizedStoragePerGB: 0.225,
+	}
+
+	// 100 GiB volume with 10000 billed I/O requests per 5 minutes (87.6 millions I/O requests per month)
+	standard, ioOptimized := rds.AuroraStorageMonthlyCosts(pricing, converter.GigaBytesToBytes(float64(100)), 10000)
+
+	assert.InDelta(t, 10+87.6*0.20, standard, 0.0001, "Aurora Standard cost mismatch")
+	assert.InDelta(t, 22.5, ioOptimized, 0.0001, "Aurora I/O-Optimized cost mismatch")
+}
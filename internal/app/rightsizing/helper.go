@@ -0,0 +1,168 @@
+package rightsizing
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	percent = 100
+
+	// Default max_connections parameter is computed from the instance class memory
+	// https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/CHAP_Limits.html#RDS_Limits.MaxConnections
+	mysqlMemoryPerConnection    = 12582880
+	postgresMemoryPerConnection = 9531392
+	postgresMaxConnections      = 5000
+
+	// Databases keep freeable memory low by design with their buffer pool and the page cache, so memory pressure is detected by swapping
+	// A small swap usage is normal since the kernel swaps idle pages
+	maxSwapUsage = 256 * 1024 * 1024
+)
+
+// instanceSizes lists instance sizes from the smallest to the largest
+var instanceSizes = []string{"micro", "small", "medium", "large", "xlarge", "2xlarge", "4xlarge", "8xlarge", "12xlarge", "16xlarge", "24xlarge", "32xlarge", "48xlarge"}
+
+// gravitonSiblings maps x86 instance families to the AWS Graviton family with the same vCPU and memory ratio
+var gravitonSiblings = map[string]string{
+	"m5":  "m6g",
+	"m6i": "m6g",
+	"m7i": "m7g",
+	"r5":  "r6g",
+	"r6i": "r6g",
+	"r7i": "r7g",
+	"t3":  "t4g",
+}
+
+// resource describes how a resource is consumed by an instance, reason identifies the resource
+// saturated returns true if a demand saturates an instance class, threshold is the utilization ratio above which a capacity is saturated
+type resource struct {
+	reason    string
+	demand    func(sample Sample, capacity ec2.EC2InstanceMetrics) *float64
+	saturated func(demand float64, capacity ec2.EC2InstanceMetrics, engine string, threshold float64) bool
+}
+
+// aboveThreshold returns a saturation check comparing demands to the threshold of the instance class capacity
+// Resources with unknown capacity can't be checked and are never saturated
+func aboveThreshold(capacity func(capacity ec2.EC2InstanceMetrics, engine string) float64) func(float64, ec2.EC2InstanceMetrics, string, float64) bool {
+	return func(demand float64, instance ec2.EC2InstanceMetrics, engine string, threshold float64) bool {
+		limit := capacity(instance, engine)
+
+		return limit > 0 && demand/limit > threshold
+	}
+}
+
+// resources lists checked resources by priority, demands and capacities use the same unit to compare instance classes
+var resources = []resource{
+	{
+		reason: CPUSaturatedReason,
+		demand: func(sample Sample, capacity ec2.EC2InstanceMetrics) *float64 {
+			if sample.CPUUtilization == nil || capacity.Vcpu == 0 {
+				return nil
+			}
+
+			vcpu := *sample.CPUUtilization / percent * float64(capacity.Vcpu)
+
+			return &vcpu
+		},
+		saturated: aboveThreshold(func(capacity ec2.EC2InstanceMetrics, _ string) float64 {
+			return float64(capacity.Vcpu)
+		}),
+	},
+	{
+		// Swapping instances need more memory whatever the instance class, memory is only a floor of smaller classes
+		reason: MemorySaturatedReason,
+		demand: func(sample Sample, _ ec2.EC2InstanceMetrics) *float64 {
+			return sample.SwapUsage
+		},
+		saturated: func(swapUsage float64, _ ec2.EC2InstanceMetrics, _ string, _ float64) bool {
+			return swapUsage > maxSwapUsage
+		},
+	},
+	{
+		reason: IOPSSaturatedReason,
+		demand: func(sample Sample, _ ec2.EC2InstanceMetrics) *float64 {
+			return sample.IOPS
+		},
+		saturated: aboveThreshold(func(capacity ec2.EC2InstanceMetrics, _ string) float64 {
+			return float64(capacity.MaximumIops)
+		}),
+	},
+	{
+		reason: ConnectionsSaturatedReason,
+		demand: func(sample Sample, _ ec2.EC2InstanceMetrics) *float64 {
+			return sample.DatabaseConnections
+		},
+		saturated: aboveThreshold(func(capacity ec2.EC2InstanceMetrics, engine string) float64 {
+			return maxConnections(engine, capacity.Memory)
+		}),
+	},
+}
+
+// demand contains the average and peak demand of a resource over the window
+type demand struct {
+	average float64
+	peak    float64
+}
+
+// getDemands returns demands of resources observed in samples, indexed by resource reason
+func getDemands(samples []Sample, capacity ec2.EC2InstanceMetrics) map[string]demand {
+	demands := make(map[string]demand)
+
+	for _, r := range resources {
+		var (
+			sum   float64
+			peak  float64
+			count int
+		)
+
+		for _, sample := range samples {
+			value := r.demand(sample, capacity)
+			if value == nil {
+				continue
+			}
+
+			sum += *value
+			peak = max(peak, *value)
+			count++
+		}
+
+		if count > 0 {
+			demands[r.reason] = demand{average: sum / float64(count), peak: peak}
+		}
+	}
+
+	return demands
+}
+
+// maxConnections returns the default max_connections parameter of the engine for the memory of an instance class, 0 if unknown
+func maxConnections(engine string, memory int64) float64 {
+	switch {
+	case strings.Contains(engine, "postgres"):
+		return min(float64(memory)/postgresMemoryPerConnection, postgresMaxConnections)
+	case strings.Contains(engine, "mysql"), engine == "mariadb":
+		return float64(memory) / mysqlMemoryPerConnection
+	default:
+		return 0
+	}
+}
+
+// splitInstanceClass returns family and size of an RDS instance class (eg. db.m5.large)
+func splitInstanceClass(instanceClass string) (string, string, bool) {
+	parts := strings.Split(instanceClass, ".")
+	if len(parts) != 3 || parts[0] != "db" {
+		return "", "", false
+	}
+
+	return parts[1], parts[2], true
+}
+
+func newInstanceClass(family string, size string) string {
+	return fmt.Sprintf("db.%s.%s", family, size)
+}
+
+func indexOfSize(size string) int {
+	return slices.Index(instanceSizes, size)
+}
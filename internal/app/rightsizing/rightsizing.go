@@ -0,0 +1,238 @@
+// Package rightsizing recommends RDS instance classes from the utilization observed over a rolling window
+package rightsizing
+
+import (
+	"sync"
+	"time"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2"
+)
+
+const (
+	CPUSaturatedReason         = "cpu_saturated"
+	MemorySaturatedReason      = "memory_saturated"
+	IOPSSaturatedReason        = "iops_saturated"
+	ConnectionsSaturatedReason = "connections_saturated"
+	UnderutilizedReason        = "underutilized"
+)
+
+// DefaultResolution is the period of AWS Cloudwatch RDS metrics, more frequent samples would not bring new information
+const DefaultResolution = 5 * time.Minute
+
+type Configuration struct {
+	Window     time.Duration // Time window of observed utilization
+	Resolution time.Duration // Minimum interval between two samples of an instance
+	MinSamples int           // Minimum number of samples in the window to recommend an instance class
+	Threshold  float64       // Utilization ratio above which a resource is saturated
+}
+
+// Sample contains the utilization of an instance at a point in time, nil values are unknown
+type Sample struct {
+	Timestamp           time.Time
+	CPUUtilization      *float64 // Percentage of vCPU used
+	SwapUsage           *float64 // Bytes of swap space used
+	IOPS                *float64 // Disk read and write IOPS
+	DatabaseConnections *float64
+}
+
+// Instance describes an instance and the capabilities of its current instance class
+type Instance struct {
+	DBIdentifier  string
+	Engine        string
+	InstanceClass string
+	Capacity      ec2.EC2InstanceMetrics
+}
+
+type Recommendation struct {
+	DBIdentifier     string
+	CurrentClass     string
+	RecommendedClass string
+	Reason           string
+}
+
+// history contains the samples of an instance for its current instance class
+type history struct {
+	instanceClass string
+	samples       []Sample
+}
+
+// Advisor keeps a rolling window of samples per instance and recommends classes of the instance class catalog
+type Advisor struct {
+	mutex         sync.Mutex
+	configuration Configuration
+	catalog       ec2.Catalog
+	histories     map[string]*history
+}
+
+func NewAdvisor(configuration Configuration, catalog ec2.Catalog) *Advisor {
+	return &Advisor{
+		configuration: configuration,
+		catalog:       catalog,
+		histories:     make(map[string]*history),
+	}
+}
+
+// Observe records a sample of the instance
+// Samples taken with a previous instance class are dropped since they don't reflect the current capacity
+func (a *Advisor) Observe(dbidentifier string, instanceClass string, sample Sample) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	h, found := a.histories[dbidentifier]
+	if !found || h.instanceClass != instanceClass {
+		h = &history{instanceClass: instanceClass}
+		a.histories[dbidentifier] = h
+	}
+
+	if n := len(h.samples); n > 0 && sample.Timestamp.Sub(h.samples[n-1].Timestamp) < a.configuration.Resolution {
+		return
+	}
+
+	h.samples = append(h.samples, sample)
+	h.samples = h.samples[a.firstInWindow(h.samples, sample.Timestamp):]
+}
+
+// Prune forgets instances that are not in dbidentifiers
+func (a *Advisor) Prune(dbidentifiers []string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	keep := make(map[string]bool, len(dbidentifiers))
+	for _, dbidentifier := range dbidentifiers {
+		keep[dbidentifier] = true
+	}
+
+	for dbidentifier := range a.histories {
+		if !keep[dbidentifier] {
+			delete(a.histories, dbidentifier)
+		}
+	}
+}
+
+// Recommend returns instance classes recommended for the instance, no recommendation is returned until the window contains enough samples
+// A saturated resource recommends the next larger class, an instance whose peak utilization fits the next smaller class is underutilized
+// Recommended classes belong to the same family or its Graviton sibling
+func (a *Advisor) Recommend(instance Instance, now time.Time) []Recommendation {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	h, found := a.histories[instance.DBIdentifier]
+	if !found || h.instanceClass != instance.InstanceClass {
+		return nil
+	}
+
+	samples := h.samples[a.firstInWindow(h.samples, now):]
+	if len(samples) < a.configuration.MinSamples || len(samples) == 0 {
+		return nil
+	}
+
+	demands := getDemands(samples, instance.Capacity)
+
+	for _, r := range resources {
+		d, found := demands[r.reason]
+		if !found {
+			continue
+		}
+
+		if r.saturated(d.average, instance.Capacity, instance.Engine, a.configuration.Threshold) {
+			return newRecommendations(instance, a.resize(instance.InstanceClass, 1), r.reason)
+		}
+	}
+
+	_, cpuFound := demands[CPUSaturatedReason]
+	_, memoryFound := demands[MemorySaturatedReason]
+
+	if !cpuFound || !memoryFound {
+		return nil
+	}
+
+	var candidates []string
+
+	for _, candidate := range a.resize(instance.InstanceClass, -1) {
+		capacity, _ := a.catalog.InstanceMetrics(candidate)
+
+		if a.fits(demands, capacity, instance.Engine) {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	return newRecommendations(instance, candidates, UnderutilizedReason)
+}
+
+// firstInWindow returns the index of the first sample in the window ending at end
+func (a *Advisor) firstInWindow(samples []Sample, end time.Time) int {
+	start := end.Add(-a.configuration.Window)
+
+	for i, sample := range samples {
+		if !sample.Timestamp.Before(start) {
+			return i
+		}
+	}
+
+	return len(samples)
+}
+
+// fits returns true if peak demands don't saturate the instance class
+func (a *Advisor) fits(demands map[string]demand, capacity ec2.EC2InstanceMetrics, engine string) bool {
+	for _, r := range resources {
+		d, found := demands[r.reason]
+		if !found {
+			continue
+		}
+
+		if r.saturated(d.peak, capacity, engine, a.configuration.Threshold) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// resize returns the closest classes larger (step 1) or smaller (step -1) than instanceClass in the catalog, in the same family or its Graviton sibling
+func (a *Advisor) resize(instanceClass string, step int) []string {
+	family, size, found := splitInstanceClass(instanceClass)
+	if !found {
+		return nil
+	}
+
+	index := indexOfSize(size)
+	if index < 0 {
+		return nil
+	}
+
+	for i := index + step; i >= 0 && i < len(instanceSizes); i += step {
+		var candidates []string
+
+		for _, f := range []string{family, gravitonSiblings[family]} {
+			if f == "" {
+				continue
+			}
+
+			candidate := newInstanceClass(f, instanceSizes[i])
+			if _, found := a.catalog[candidate]; found {
+				candidates = append(candidates, candidate)
+			}
+		}
+
+		if len(candidates) > 0 {
+			return candidates
+		}
+	}
+
+	return nil
+}
+
+func newRecommendations(instance Instance, candidates []string, reason string) []Recommendation {
+	recommendations := make([]Recommendation, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		recommendations = append(recommendations, Recommendation{
+			DBIdentifier:     instance.DBIdentifier,
+			CurrentClass:     instance.InstanceClass,
+			RecommendedClass: candidate,
+			Reason:           reason,
+		})
+	}
+
+	return recommendations
+}
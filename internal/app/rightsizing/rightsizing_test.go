@@ -0,0 +1,168 @@
+package rightsizing_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/ec2"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/rightsizing"
+	converter "github.com/TeiNam/prometheus-rds-exporter/internal/app/unit"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const minSamples = 12
+
+func newAdvisor(t *testing.T) *rightsizing.Advisor {
+	t.Helper()
+
+	catalog, err := ec2.DefaultCatalog()
+	require.NoError(t, err, "Embedded catalog must be valid")
+
+	return rightsizing.NewAdvisor(rightsizing.Configuration{
+		Window:     time.Hour,
+		Resolution: rightsizing.DefaultResolution,
+		MinSamples: minSamples,
+		Threshold:  0.8,
+	}, catalog)
+}
+
+func newInstance(t *testing.T, instanceClass string) rightsizing.Instance {
+	t.Helper()
+
+	catalog, err := ec2.DefaultCatalog()
+	require.NoError(t, err, "Embedded catalog must be valid")
+
+	capacity, found := catalog.InstanceMetrics(instanceClass)
+	require.True(t, found, "Instance class must be in the catalog")
+
+	return rightsizing.Instance{DBIdentifier: "db1", Engine: "postgres", InstanceClass: instanceClass, Capacity: capacity}
+}
+
+// observe records count samples every 5 minutes ending at end
+func observe(advisor *rightsizing.Advisor, instance rightsizing.Instance, sample rightsizing.Sample, count int, end time.Time) {
+	for i := count - 1; i >= 0; i-- {
+		sample.Timestamp = end.Add(-time.Duration(i) * rightsizing.DefaultResolution)
+		advisor.Observe(instance.DBIdentifier, instance.InstanceClass, sample)
+	}
+}
+
+func TestUnderutilizedInstance(t *testing.T) {
+	advisor := newAdvisor(t)
+	instance := newInstance(t, "db.m5.2xlarge") // 8 vCPU, 32 GiB
+	now := time.Now()
+
+	observe(advisor, instance, rightsizing.Sample{
+		CPUUtilization:      aws.Float64(10),
+		SwapUsage:           aws.Float64(converter.MegaBytesToBytes(float64(10))),
+		IOPS:                aws.Float64(100),
+		DatabaseConnections: aws.Float64(20),
+	}, minSamples, now)
+
+	recommendations := advisor.Recommend(instance, now)
+
+	require.Len(t, recommendations, 2, "Same family and Graviton sibling should be recommended")
+	assert.Equal(t, "db.m5.xlarge", recommendations[0].RecommendedClass, "Next smaller class of the family expected")
+	assert.Equal(t, "db.m6g.xlarge", recommendations[1].RecommendedClass, "Graviton sibling expected")
+	assert.Equal(t, rightsizing.UnderutilizedReason, recommendations[0].Reason, "Reason mismatch")
+	assert.Equal(t, "db.m5.2xlarge", recommendations[0].CurrentClass, "Current class mismatch")
+}
+
+func TestSaturatedInstance(t *testing.T) {
+	advisor := newAdvisor(t)
+	instance := newInstance(t, "db.r6g.large") // 2 vCPU, 16 GiB
+	now := time.Now()
+
+	observe(advisor, instance, rightsizing.Sample{
+		CPUUtilization: aws.Float64(30),
+		SwapUsage:      aws.Float64(converter.GigaBytesToBytes(float64(1))),
+	}, minSamples, now)
+
+	recommendations := advisor.Recommend(instance, now)
+
+	require.Len(t, recommendations, 1, "Graviton family has no sibling")
+	assert.Equal(t, "db.r6g.xlarge", recommendations[0].RecommendedClass, "Next larger class expected")
+	assert.Equal(t, rightsizing.MemorySaturatedReason, recommendations[0].Reason, "Memory should be saturated")
+}
+
+func TestPeakPreventsDownsizing(t *testing.T) {
+	advisor := newAdvisor(t)
+	instance := newInstance(t, "db.m6g.xlarge")
+	now := time.Now()
+
+	sample := rightsizing.Sample{
+		CPUUtilization: aws.Float64(10),
+		SwapUsage:      aws.Float64(0),
+	}
+
+	observe(advisor, instance, sample, minSamples-1, now.Add(-rightsizing.DefaultResolution))
+
+	sample.CPUUtilization = aws.Float64(60)
+	observe(advisor, instance, sample, 1, now)
+
+	assert.Empty(t, advisor.Recommend(instance, now), "A peak above the capacity of the smaller class must prevent downsizing")
+}
+
+func TestSwapPeakPreventsDownsizing(t *testing.T) {
+	advisor := newAdvisor(t)
+	instance := newInstance(t, "db.m6g.xlarge")
+	now := time.Now()
+
+	sample := rightsizing.Sample{
+		CPUUtilization: aws.Float64(10),
+		SwapUsage:      aws.Float64(0),
+	}
+
+	observe(advisor, instance, sample, minSamples-1, now.Add(-rightsizing.DefaultResolution))
+
+	sample.SwapUsage = aws.Float64(converter.GigaBytesToBytes(float64(1)))
+	observe(advisor, instance, sample, 1, now)
+
+	assert.Empty(t, advisor.Recommend(instance, now), "Swapping must prevent downsizing")
+}
+
+func TestNotEnoughSamples(t *testing.T) {
+	advisor := newAdvisor(t)
+	instance := newInstance(t, "db.m6g.xlarge")
+	now := time.Now()
+
+	// Samples taken more often than the resolution are ignored
+	for i := 0; i < minSamples; i++ {
+		advisor.Observe(instance.DBIdentifier, instance.InstanceClass, rightsizing.Sample{
+			Timestamp:      now.Add(time.Duration(i) * time.Second),
+			CPUUtilization: aws.Float64(100),
+			SwapUsage:      aws.Float64(0),
+		})
+	}
+
+	assert.Empty(t, advisor.Recommend(instance, now.Add(time.Minute)), "Recommendations require enough samples")
+}
+
+func TestInstanceClassChangeResetsWindow(t *testing.T) {
+	advisor := newAdvisor(t)
+	instance := newInstance(t, "db.m6g.xlarge")
+	now := time.Now()
+
+	observe(advisor, instance, rightsizing.Sample{CPUUtilization: aws.Float64(100), SwapUsage: aws.Float64(0)}, minSamples, now)
+
+	resized := newInstance(t, "db.m6g.2xlarge")
+	advisor.Observe(resized.DBIdentifier, resized.InstanceClass, rightsizing.Sample{Timestamp: now.Add(rightsizing.DefaultResolution)})
+
+	assert.Empty(t, advisor.Recommend(instance, now), "Samples of the previous class must be dropped")
+	assert.Empty(t, advisor.Recommend(resized, now.Add(rightsizing.DefaultResolution)), "New class has not enough samples")
+}
+
+func TestWindowExpiration(t *testing.T) {
+	advisor := newAdvisor(t)
+	instance := newInstance(t, "db.m6g.xlarge")
+	now := time.Now()
+
+	observe(advisor, instance, rightsizing.Sample{CPUUtilization: aws.Float64(100), SwapUsage: aws.Float64(0)}, minSamples, now)
+
+	assert.NotEmpty(t, advisor.Recommend(instance, now), "Saturated instance should be resized")
+	assert.Empty(t, advisor.Recommend(instance, now.Add(2*time.Hour)), "Samples out of the window must be ignored")
+
+	advisor.Prune([]string{})
+	assert.Empty(t, advisor.Recommend(instance, now), "Pruned instances have no samples")
+}
@@ -5,35 +5,67 @@ import (
 	"context"
 
 	"github.com/TeiNam/prometheus-rds-exporter/internal/app/servicequotas"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	aws_servicequotas "github.com/aws/aws-sdk-go-v2/service/servicequotas"
 	aws_servicequotas_types "github.com/aws/aws-sdk-go-v2/service/servicequotas/types"
 )
 
 // Defines expected values for the mock and tests
 const (
-	UnknownServiceQuota       = float64(42)
-	DBinstancesQuota          = float64(10)
-	TotalStorage              = float64(10)
-	ManualDBInstanceSnapshots = float64(42)
+	DefaultDBinstancesQuota      = float64(40)
+	DBinstancesQuota             = float64(10)
+	TotalStorage                 = float64(10)
+	ManualDBInstanceSnapshots    = float64(42)
+	ReadReplicasPerPrimary       = float64(15)
+	ReadReplicasQuotaCode        = servicequotas.ReadReplicasPerPrimaryQuotaCode
+	ParameterGroups              = float64(50)
+	ParameterGroupsQuotaCode     = "L-DE55804A"
+	ParameterGroupsUsageResource = "DBParameterGroups"
 )
 
 type ServiceQuotasClient struct{}
 
-func (m ServiceQuotasClient) GetServiceQuota(context context.Context, input *aws_servicequotas.GetServiceQuotaInput, optFns ...func(*aws_servicequotas.Options)) (*aws_servicequotas.GetServiceQuotaOutput, error) {
-	value := UnknownServiceQuota
-
-	if *input.ServiceCode == servicequotas.RDSServiceCode {
-		switch *input.QuotaCode {
-		case servicequotas.DBinstancesQuotacode:
-			value = DBinstancesQuota
-		case servicequotas.TotalStorageQuotaCode:
-			value = TotalStorage
-		case servicequotas.ManualDBInstanceSnapshotsQuotaCode:
-			value = ManualDBInstanceSnapshots
-		}
+func newQuota(code string, name string, value float64) aws_servicequotas_types.ServiceQuota {
+	return aws_servicequotas_types.ServiceQuota{
+		ServiceCode: aws.String(servicequotas.RDSServiceCode),
+		QuotaCode:   aws.String(code),
+		QuotaName:   aws.String(name),
+		Unit:        aws.String("None"),
+		Value:       aws.Float64(value),
+	}
+}
+
+// newQuotaWithUsage returns a quota whose usage is published in AWS/Usage namespace
+func newQuotaWithUsage(code string, name string, value float64, resource string) aws_servicequotas_types.ServiceQuota {
+	quota := newQuota(code, name, value)
+	quota.UsageMetric = &aws_servicequotas_types.MetricInfo{
+		MetricNamespace: aws.String("AWS/Usage"),
+		MetricName:      aws.String("ResourceCount"),
+		MetricDimensions: map[string]string{
+			"Service":  "RDS",
+			"Type":     "Resource",
+			"Resource": resource,
+			"Class":    "None",
+		},
 	}
 
-	quota := &aws_servicequotas_types.ServiceQuota{Value: &value}
+	return quota
+}
+
+// ListAWSDefaultServiceQuotas returns AWS default quotas, DB instances quota is overridden by an applied quota
+func (m ServiceQuotasClient) ListAWSDefaultServiceQuotas(context context.Context, input *aws_servicequotas.ListAWSDefaultServiceQuotasInput, optFns ...func(*aws_servicequotas.Options)) (*aws_servicequotas.ListAWSDefaultServiceQuotasOutput, error) {
+	return &aws_servicequotas.ListAWSDefaultServiceQuotasOutput{Quotas: []aws_servicequotas_types.ServiceQuota{
+		newQuotaWithUsage(servicequotas.DBinstancesQuotacode, "DB instances", DefaultDBinstancesQuota, "DBInstances"),
+		newQuotaWithUsage(servicequotas.TotalStorageQuotaCode, "Total storage for all DB instances", TotalStorage, "AllocatedStorage"),
+		newQuotaWithUsage(servicequotas.ManualDBInstanceSnapshotsQuotaCode, "Manual DB instance snapshots", ManualDBInstanceSnapshots, "ManualSnapshots"),
+		newQuota(ReadReplicasQuotaCode, "Read replicas per master", ReadReplicasPerPrimary),
+		newQuotaWithUsage(ParameterGroupsQuotaCode, "Parameter groups", ParameterGroups, ParameterGroupsUsageResource),
+	}}, nil
+}
 
-	return &aws_servicequotas.GetServiceQuotaOutput{Quota: quota}, nil
+// ListServiceQuotas returns quotas applied to the account
+func (m ServiceQuotasClient) ListServiceQuotas(context context.Context, input *aws_servicequotas.ListServiceQuotasInput, optFns ...func(*aws_servicequotas.Options)) (*aws_servicequotas.ListServiceQuotasOutput, error) {
+	return &aws_servicequotas.ListServiceQuotasOutput{Quotas: []aws_servicequotas_types.ServiceQuota{
+		newQuota(servicequotas.DBinstancesQuotacode, "DB instances", DBinstancesQuota),
+	}}, nil
 }
@@ -3,17 +3,16 @@ package servicequotas
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
 
+	"github.com/TeiNam/prometheus-rds-exporter/internal/app/iam"
 	converter "github.com/TeiNam/prometheus-rds-exporter/internal/app/unit"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	aws_servicequotas "github.com/aws/aws-sdk-go-v2/service/servicequotas"
-	"golang.org/x/exp/slog"
-)
-
-var (
-	errNoQuota    = errors.New("no AWS quota with this code")
-	errQuotaError = errors.New("aws return error for this quota")
+	aws_servicequotas_types "github.com/aws/aws-sdk-go-v2/service/servicequotas/types"
+	"golang.org/x/exp/slices"
 )
 
 const (
@@ -23,13 +22,47 @@ const (
 	DBinstancesQuotacode               = "L-7B6409FD" // DB instances
 	TotalStorageQuotaCode              = "L-7ADDB58A" // Total storage for all DB instances
 	ManualDBInstanceSnapshotsQuotaCode = "L-272F1212" // Manual DB instance snapshots
+	ReadReplicasPerPrimaryQuotaCode    = "L-5BC124EF" // Read replicas per master
+
+	// AWS/Usage resource count series tracking RDS quotas usage
+	UsageNamespace  = "AWS/Usage"
+	UsageMetricName = "ResourceCount"
+	UsageNoClass    = "None" // Class dimension value of resources without class
+
+	// AWS/Usage resources of quotas having dedicated metrics
+	DBInstancesUsageResource      = "DBInstances"
+	AllocatedStorageUsageResource = "AllocatedStorage"
+	ManualSnapshotsUsageResource  = "ManualSnapshots"
 )
 
+// DefaultUsageResources are AWS/Usage resources tracking quotas usage, indexed by quota code
+// They are used when AWS doesn't describe the usage metric of the quota or doesn't publish any usage series yet
+var DefaultUsageResources = map[string]string{
+	DBinstancesQuotacode:               DBInstancesUsageResource,
+	TotalStorageQuotaCode:              AllocatedStorageUsageResource,
+	ManualDBInstanceSnapshotsQuotaCode: ManualSnapshotsUsageResource,
+}
+
+// Quota is an AWS RDS service quota
+type Quota struct {
+	Code          string
+	Name          string
+	Unit          string
+	Value         float64
+	UsageResource string // Resource dimension of the AWS/Usage series tracking quota usage (empty if usage is not published)
+	UsageClass    string // Class dimension of the AWS/Usage series tracking quota usage
+}
+
 // Metrics contains the quotas to be monitored for the AWS RDS service
 type Metrics struct {
 	DBinstances               float64
 	TotalStorage              float64
 	ManualDBInstanceSnapshots float64
+	Quotas                    []Quota
+}
+
+type Configuration struct {
+	AllowList []string // Quota codes exported as generic quota metrics (empty: all quotas)
 }
 
 type Statistics struct {
@@ -37,73 +70,176 @@ type Statistics struct {
 }
 
 type ServiceQuotasClient interface {
-	GetServiceQuota(ctx context.Context, input *aws_servicequotas.GetServiceQuotaInput, optFns ...func(*aws_servicequotas.Options)) (*aws_servicequotas.GetServiceQuotaOutput, error)
+	ListServiceQuotas(ctx context.Context, input *aws_servicequotas.ListServiceQuotasInput, optFns ...func(*aws_servicequotas.Options)) (*aws_servicequotas.ListServiceQuotasOutput, error)
+	ListAWSDefaultServiceQuotas(ctx context.Context, input *aws_servicequotas.ListAWSDefaultServiceQuotasInput, optFns ...func(*aws_servicequotas.Options)) (*aws_servicequotas.ListAWSDefaultServiceQuotasOutput, error)
+}
+
+// Permissions returns AWS IAM statements required by the fetcher
+func Permissions() []iam.Statement {
+	return []iam.Statement{
+		iam.Allow("AllowQuotaDescriptions", []string{"servicequotas:ListServiceQuotas", "servicequotas:ListAWSDefaultServiceQuotas"}, iam.AllResources),
+	}
 }
 
-func NewFetcher(client ServiceQuotasClient) *serviceQuotaFetcher {
+func NewFetcher(client ServiceQuotasClient, logger slog.Logger, configuration Configuration) *serviceQuotaFetcher {
 	return &serviceQuotaFetcher{
-		client: client,
+		client:        client,
+		logger:        &logger,
+		configuration: configuration,
 	}
 }
 
 type serviceQuotaFetcher struct {
-	logger     *slog.Logger
-	client     ServiceQuotasClient
-	statistics Statistics
+	logger        *slog.Logger
+	client        ServiceQuotasClient
+	statistics    Statistics
+	configuration Configuration
 }
 
 func (s *serviceQuotaFetcher) GetStatistics() Statistics {
 	return s.statistics
 }
 
-// GetQuota retrieves and returns the AWS quota value for the specified serviceCode and quotaCode
-func (s *serviceQuotaFetcher) getQuota(serviceCode string, quotaCode string) (float64, error) {
-	params := &aws_servicequotas.GetServiceQuotaInput{
-		ServiceCode: &serviceCode,
-		QuotaCode:   &quotaCode,
+// getDefaultQuotas returns AWS default quota values of the service
+func (s *serviceQuotaFetcher) getDefaultQuotas(serviceCode string) ([]aws_servicequotas_types.ServiceQuota, error) {
+	var quotas []aws_servicequotas_types.ServiceQuota
+
+	input := &aws_servicequotas.ListAWSDefaultServiceQuotasInput{ServiceCode: aws.String(serviceCode)}
+
+	paginator := aws_servicequotas.NewListAWSDefaultServiceQuotasPaginator(s.client, input)
+	for paginator.HasMorePages() {
+		s.statistics.UsageAPICall++
+
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("can't list %s default service quotas: %w", serviceCode, err)
+		}
+
+		quotas = append(quotas, output.Quotas...)
 	}
 
-	s.statistics.UsageAPICall++
+	return quotas, nil
+}
 
-	result, err := s.client.GetServiceQuota(context.TODO(), params)
-	if err != nil {
-		return 0, fmt.Errorf("can't get %s/%s service quota: %w", serviceCode, quotaCode, err)
+// getAppliedQuotas returns quota values applied to the AWS account, only quotas having an applied value are returned
+func (s *serviceQuotaFetcher) getAppliedQuotas(serviceCode string) ([]aws_servicequotas_types.ServiceQuota, error) {
+	var quotas []aws_servicequotas_types.ServiceQuota
+
+	input := &aws_servicequotas.ListServiceQuotasInput{ServiceCode: aws.String(serviceCode)}
+
+	paginator := aws_servicequotas.NewListServiceQuotasPaginator(s.client, input)
+	for paginator.HasMorePages() {
+		s.statistics.UsageAPICall++
+
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("can't list %s service quotas: %w", serviceCode, err)
+		}
+
+		quotas = append(quotas, output.Quotas...)
+	}
+
+	return quotas, nil
+}
+
+// isAllowed returns true if the quota must be collected
+func (s *serviceQuotaFetcher) isAllowed(quotaCode string) bool {
+	return len(s.configuration.AllowList) == 0 || slices.Contains(s.configuration.AllowList, quotaCode)
+}
+
+// addQuotas indexes quotas by code, overriding previous values
+func (s *serviceQuotaFetcher) addQuotas(quotas map[string]Quota, serviceQuotas []aws_servicequotas_types.ServiceQuota) {
+	for _, quota := range serviceQuotas {
+		// AWS response payload could contains errors (eg. missing permission)
+		if quota.ErrorReason != nil {
+			s.logger.Error("AWS quota error", "quotaCode", aws.ToString(quota.QuotaCode), "errorCode", quota.ErrorReason.ErrorCode, "message", aws.ToString(quota.ErrorReason.ErrorMessage))
+
+			continue
+		}
+
+		if quota.QuotaCode == nil || quota.Value == nil {
+			continue
+		}
+
+		previous := quotas[*quota.QuotaCode]
+		resource, class := getUsageDimensions(quota.UsageMetric)
+
+		// Applied quotas may not describe their usage metric
+		if resource == "" {
+			resource, class = previous.UsageResource, previous.UsageClass
+		}
+
+		if defaultResource, found := DefaultUsageResources[*quota.QuotaCode]; found && resource == "" {
+			resource, class = defaultResource, UsageNoClass
+		}
+
+		quotas[*quota.QuotaCode] = Quota{
+			Code:          *quota.QuotaCode,
+			Name:          aws.ToString(quota.QuotaName),
+			Unit:          aws.ToString(quota.Unit),
+			Value:         *quota.Value,
+			UsageResource: resource,
+			UsageClass:    class,
+		}
 	}
+}
 
-	// AWS response payload could contains errors (eg. missing permission)
-	if result.Quota.ErrorReason != nil {
-		s.logger.Error("AWS quota error: ", "errorCode", result.Quota.ErrorReason.ErrorCode, "message", *result.Quota.ErrorReason.ErrorMessage)
+// getUsageDimensions returns resource and class of the AWS/Usage resource count series tracking a quota usage
+func getUsageDimensions(metric *aws_servicequotas_types.MetricInfo) (string, string) {
+	if metric == nil || aws.ToString(metric.MetricNamespace) != UsageNamespace || aws.ToString(metric.MetricName) != UsageMetricName {
+		return "", ""
+	}
 
-		return 0, errQuotaError
+	resource := metric.MetricDimensions["Resource"]
+	if resource == "" {
+		return "", ""
 	}
 
-	if result.Quota == nil {
-		return 0, fmt.Errorf("no quota for %s/%s: %w", serviceCode, quotaCode, errNoQuota)
+	class := metric.MetricDimensions["Class"]
+	if class == "" {
+		class = UsageNoClass
 	}
 
-	return *result.Quota.Value, nil
+	return resource, class
 }
 
 // GetRDSQuotas retrieves quotas for the AWS RDS service
+// Applied quota values override AWS default values
 func (s *serviceQuotaFetcher) GetRDSQuotas() (Metrics, error) {
-	DBinstances, err := s.getQuota(RDSServiceCode, DBinstancesQuotacode)
+	defaultQuotas, err := s.getDefaultQuotas(RDSServiceCode)
 	if err != nil {
-		return Metrics{}, fmt.Errorf("can't fetch DBinstance quota: %w", err)
+		return Metrics{}, err
 	}
 
-	totalStorage, err := s.getQuota(RDSServiceCode, TotalStorageQuotaCode)
+	appliedQuotas, err := s.getAppliedQuotas(RDSServiceCode)
 	if err != nil {
-		return Metrics{}, fmt.Errorf("can't fetch total storage quota: %w", err)
+		return Metrics{}, err
 	}
 
-	manualDBInstanceSnapshots, err := s.getQuota(RDSServiceCode, ManualDBInstanceSnapshotsQuotaCode)
-	if err != nil {
-		return Metrics{}, fmt.Errorf("can't fetch manual db instance snapshots quota: %w", err)
+	quotasByCode := make(map[string]Quota)
+	s.addQuotas(quotasByCode, defaultQuotas)
+	s.addQuotas(quotasByCode, appliedQuotas)
+
+	metrics := Metrics{}
+
+	for code, quota := range quotasByCode {
+		if s.isAllowed(code) {
+			metrics.Quotas = append(metrics.Quotas, quota)
+		}
+
+		switch code {
+		case DBinstancesQuotacode:
+			metrics.DBinstances = quota.Value
+		case TotalStorageQuotaCode:
+			metrics.TotalStorage = converter.GigaBytesToBytes(quota.Value)
+		case ManualDBInstanceSnapshotsQuotaCode:
+			metrics.ManualDBInstanceSnapshots = quota.Value
+		}
 	}
 
-	return Metrics{
-		DBinstances:               DBinstances,
-		TotalStorage:              converter.GigaBytesToBytes(totalStorage),
-		ManualDBInstanceSnapshots: manualDBInstanceSnapshots,
-	}, nil
+	sort.Slice(metrics.Quotas, func(i, j int) bool {
+		return metrics.Quotas[i].Code < metrics.Quotas[j].Code
+	})
+
+	return metrics, nil
 }
@@ -1,6 +1,7 @@
 package servicequotas_test
 
 import (
+	"log/slog"
 	"testing"
 
 	"github.com/TeiNam/prometheus-rds-exporter/internal/app/servicequotas"
@@ -14,9 +15,30 @@ import (
 func TestGetRDSQuotas(t *testing.T) {
 	client := mock.ServiceQuotasClient{}
 
-	result, err := servicequotas.NewFetcher(client).GetRDSQuotas()
+	fetcher := servicequotas.NewFetcher(client, slog.Logger{}, servicequotas.Configuration{})
+	result, err := fetcher.GetRDSQuotas()
 	require.NoError(t, err, "GetRDSQuotas must succeed")
 	assert.Equal(t, mock.DBinstancesQuota, result.DBinstances, "DbInstance quota is incorrect")
 	assert.Equal(t, converter.GigaBytesToBytes(mock.TotalStorage), result.TotalStorage, "Total storage quota is incorrect")
 	assert.Equal(t, mock.ManualDBInstanceSnapshots, result.ManualDBInstanceSnapshots, "Manual db instance snapshot quota is incorrect")
+	assert.Equal(t, float64(2), fetcher.GetStatistics().UsageAPICall, "One call to list default quotas and one call to list applied quotas")
+
+	require.Len(t, result.Quotas, 5, "All quotas must be returned")
+	assert.Equal(t, servicequotas.Quota{Code: mock.ReadReplicasQuotaCode, Name: "Read replicas per master", Unit: "None", Value: mock.ReadReplicasPerPrimary}, result.Quotas[1], "Quotas must be sorted by code")
+}
+
+func TestQuotasAllowList(t *testing.T) {
+	client := mock.ServiceQuotasClient{}
+
+	configuration := servicequotas.Configuration{AllowList: []string{mock.ParameterGroupsQuotaCode, servicequotas.DBinstancesQuotacode}}
+	result, err := servicequotas.NewFetcher(client, slog.Logger{}, configuration).GetRDSQuotas()
+	require.NoError(t, err, "GetRDSQuotas must succeed")
+
+	require.Len(t, result.Quotas, 2, "Only allowed quotas must be returned")
+	assert.Equal(t, mock.DBinstancesQuota, result.Quotas[0].Value, "Applied quota must override default quota")
+	assert.Equal(t, "DBInstances", result.Quotas[0].UsageResource, "Applied quota must keep default quota usage metric")
+	assert.Equal(t, mock.ParameterGroups, result.Quotas[1].Value, "Parameter groups quota is incorrect")
+	assert.Equal(t, mock.ParameterGroupsUsageResource, result.Quotas[1].UsageResource, "Parameter groups usage resource is incorrect")
+	assert.Equal(t, "None", result.Quotas[1].UsageClass, "Parameter groups usage class is incorrect")
+	assert.Equal(t, mock.ManualDBInstanceSnapshots, result.ManualDBInstanceSnapshots, "Legacy quotas must not depend on allow list")
 }
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+	"sort"
+)
+
+// DiscoveryPath is the path of the Prometheus HTTP service discovery endpoint
+const DiscoveryPath = "/sd"
+
+// targetGroup is a Prometheus HTTP service discovery target group
+// https://prometheus.io/docs/prometheus/latest/http_sd/
+type targetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// discoveryHandler serves instance endpoints as Prometheus HTTP service discovery target groups
+type discoveryHandler struct {
+	inventory InventoryFunc
+}
+
+// NewDiscoveryHandler returns the handler of Prometheus HTTP service discovery, instances are read on each request
+func NewDiscoveryHandler(inventory InventoryFunc) http.Handler {
+	return discoveryHandler{inventory: inventory}
+}
+
+func (h discoveryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	instances, err := selectInstances(r, h.inventory)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	sort.SliceStable(instances, func(i, j int) bool { return instances[i].Engine < instances[j].Engine })
+
+	// Each instance is a target group, since dbidentifier and role labels differ between instances of an engine
+	// Groups are sorted by engine, engine query parameters select the instances of an engine family
+	groups := make([]targetGroup, 0, len(instances))
+
+	for _, instance := range instances {
+		// Instances being created have no endpoint yet
+		if instance.Endpoint == "" {
+			continue
+		}
+
+		groups = append(groups, targetGroup{Targets: []string{instance.Endpoint}, Labels: instance.Labels})
+	}
+
+	writeJSON(w, groups)
+}
@@ -0,0 +1,38 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoveryHandler(t *testing.T) {
+	handler := webserver.NewDiscoveryHandler(newInventory())
+
+	var groups []struct {
+		Targets []string          `json:"targets"`
+		Labels  map[string]string `json:"labels"`
+	}
+
+	code := getJSON(t, handler, webserver.DiscoveryPath, &groups)
+	assert.Equal(t, http.StatusOK, code, "Discovery should succeed")
+	require.Len(t, groups, 3, "Instances without endpoint should be skipped")
+	assert.Equal(t, "aurora-postgresql", groups[0].Labels["engine"], "Target groups should be sorted by engine")
+	assert.Equal(t, "mysql", groups[1].Labels["engine"], "Target groups should be sorted by engine")
+
+	code = getJSON(t, handler, webserver.DiscoveryPath+"?engine=postgres&engine=aurora-postgresql", &groups)
+	assert.Equal(t, http.StatusOK, code, "Engine family discovery should succeed")
+	require.Len(t, groups, 2, "Each instance of the engine family should be a target group")
+
+	code = getJSON(t, handler, webserver.DiscoveryPath+"?engine=mysql", &groups)
+	assert.Equal(t, http.StatusOK, code, "Filtered discovery should succeed")
+	require.Len(t, groups, 1, "Only matching instances should be discovered")
+	assert.Equal(t, []string{"db-mysql.eu-west-3.rds.amazonaws.com:3306"}, groups[0].Targets, "Target should be the instance endpoint")
+	assert.Equal(t, map[string]string{"dbidentifier": "db-mysql", "engine": "mysql"}, groups[0].Labels, "Labels mismatch")
+
+	code = getJSON(t, handler, webserver.DiscoveryPath+"?tag=Team", &groups)
+	assert.Equal(t, http.StatusBadRequest, code, "Invalid tag filter should fail")
+}
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const (
+	HealthPath    = "/healthz"
+	ReadinessPath = "/readyz"
+)
+
+// CheckStatus is the result of a readiness check
+type CheckStatus struct {
+	Ready       bool       `json:"ready"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// RegionReadiness is the readiness of the collector of an AWS region, detailed by data source
+type RegionReadiness struct {
+	Region      string                 `json:"region"`
+	AccountID   string                 `json:"account_id"`
+	Ready       bool                   `json:"ready"`
+	Credentials CheckStatus            `json:"credentials"`
+	Sources     map[string]CheckStatus `json:"sources"`
+}
+
+// ReadinessCheck returns the readiness of a region collector
+type ReadinessCheck func(ctx context.Context) RegionReadiness
+
+type readiness struct {
+	Ready   bool              `json:"ready"`
+	Regions []RegionReadiness `json:"regions"`
+}
+
+// healthHandler reports the exporter process is alive
+type healthHandler struct{}
+
+func (h healthHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("OK\n"))
+}
+
+// readinessHandler reports the exporter is ready when all region collectors are ready
+type readinessHandler struct {
+	checks func() []ReadinessCheck
+}
+
+// NewReadinessHandler returns the handler reporting readiness of region collectors as JSON, checks are read on each request
+func NewReadinessHandler(checks func() []ReadinessCheck) http.Handler {
+	return readinessHandler{checks: checks}
+}
+
+func (h readinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	checks := h.checks()
+
+	response := readiness{
+		Ready:   len(checks) > 0,
+		Regions: make([]RegionReadiness, 0, len(checks)),
+	}
+
+	for _, check := range checks {
+		region := check(r.Context())
+
+		response.Ready = response.Ready && region.Ready
+		response.Regions = append(response.Regions, region)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if !response.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	_ = json.NewEncoder(w).Encode(response)
+}
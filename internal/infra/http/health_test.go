@@ -0,0 +1,54 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReadinessCheck(region string, ready bool) webserver.ReadinessCheck {
+	return func(_ context.Context) webserver.RegionReadiness {
+		return webserver.RegionReadiness{
+			Region:      region,
+			AccountID:   "123456789012",
+			Ready:       ready,
+			Credentials: webserver.CheckStatus{Ready: true},
+			Sources:     map[string]webserver.CheckStatus{"rds": {Ready: ready}},
+		}
+	}
+}
+
+func TestReadinessHandler(t *testing.T) {
+	testCases := []struct {
+		name     string
+		checks   []webserver.ReadinessCheck
+		expected int
+	}{
+		{"all regions ready", []webserver.ReadinessCheck{newReadinessCheck("eu-west-1", true), newReadinessCheck("eu-west-3", true)}, http.StatusOK},
+		{"one region not ready", []webserver.ReadinessCheck{newReadinessCheck("eu-west-1", true), newReadinessCheck("eu-west-3", false)}, http.StatusServiceUnavailable},
+		{"no region", nil, http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checks := func() []webserver.ReadinessCheck { return tc.checks }
+
+			code, body := scrape(t, webserver.NewReadinessHandler(checks), "/readyz")
+			assert.Equal(t, tc.expected, code, "Status code mismatch")
+
+			var response struct {
+				Ready   bool                        `json:"ready"`
+				Regions []webserver.RegionReadiness `json:"regions"`
+			}
+
+			require.NoError(t, json.Unmarshal([]byte(body), &response), "Body must be JSON")
+			assert.Equal(t, tc.expected == http.StatusOK, response.Ready, "Readiness mismatch")
+			assert.Len(t, response.Regions, len(tc.checks), "All regions should be detailed")
+		})
+	}
+}
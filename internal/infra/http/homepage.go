@@ -5,53 +5,150 @@ import (
 	"fmt"
 	"html/template"
 	"net/http"
+	"time"
 )
 
+// Status is the operational status of the exporter rendered on the homepage
+type Status struct {
+	Regions       []RegionStatus
+	Collectors    []Setting // Collectors and whether they are enabled
+	Configuration []Setting // Effective configuration, secrets are redacted
+}
+
+// RegionStatus is the status of the collector of an AWS account and region
+type RegionStatus struct {
+	Region    string
+	AccountID string
+	Errors    float64
+	Sources   []SourceStatus
+	APICalls  []Count
+	Engines   []Count // Number of instances per engine
+}
+
+// SourceStatus is the result of the last fetch of a data source
+type SourceStatus struct {
+	Name        string
+	LastRefresh time.Time // Zero if the source has never been fetched successfully
+	Duration    time.Duration
+	Error       string
+}
+
+type Setting struct {
+	Name  string
+	Value string
+}
+
+type Count struct {
+	Name  string
+	Value float64
+}
+
+// StatusFunc returns the current status of the exporter
+type StatusFunc func() Status
+
 type homeHandler struct {
-	content []byte
+	template    *template.Template
+	information homeInformation
+	status      StatusFunc
 }
 
 type homeInformation struct {
 	Version    string
 	MetricPath string
+	Status     Status
 }
 
-func NewHomePage(version string, metricPath string) (*homeHandler, error) {
-	context := homeInformation{
-		Version:    version,
-		MetricPath: metricPath,
-	}
-
-	content := `<html>
+const homepageTemplate = `<html>
 	<head>
 		<title>Prometheus RDS Exporter</title>
+		<style>
+			body { font-family: sans-serif; }
+			table { border-collapse: collapse; margin-bottom: 1em; }
+			th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; }
+			.error { color: #c00; }
+		</style>
 	</head>
 	<body>
 		<h1>Prometheus RDS Exporter ({{ .Version }})</h1>
-		<p><a href='{{ .MetricPath }}'>Metrics</a></p>
+		<p><a href='{{ .MetricPath }}'>Metrics</a> - <a href='/healthz'>Health</a> - <a href='/readyz'>Readiness</a></p>
+		{{- range .Status.Regions }}
+		<h2>{{ .Region }} (account {{ .AccountID }})</h2>
+		<p>Errors: {{ .Errors }}</p>
+		<table>
+			<tr><th>Source</th><th>Last refresh</th><th>Duration</th><th>Error</th></tr>
+			{{- range .Sources }}
+			<tr><td>{{ .Name }}</td><td>{{ if .LastRefresh.IsZero }}never{{ else }}{{ .LastRefresh.Format "2006-01-02 15:04:05 MST" }}{{ end }}</td><td>{{ .Duration }}</td><td class="error">{{ .Error }}</td></tr>
+			{{- else }}
+			<tr><td colspan="4">Not fetched yet, metrics are fetched on scrape</td></tr>
+			{{- end }}
+		</table>
+		<table>
+			<tr><th>API</th><th>Calls</th></tr>
+			{{- range .APICalls }}
+			<tr><td>{{ .Name }}</td><td>{{ .Value }}</td></tr>
+			{{- end }}
+		</table>
+		<table>
+			<tr><th>Engine</th><th>Instances</th></tr>
+			{{- range .Engines }}
+			<tr><td>{{ .Name }}</td><td>{{ .Value }}</td></tr>
+			{{- end }}
+		</table>
+		{{- end }}
+		{{- if .Status.Collectors }}
+		<h2>Collectors</h2>
+		<table>
+			{{- range .Status.Collectors }}
+			<tr><td>{{ .Name }}</td><td>{{ .Value }}</td></tr>
+			{{- end }}
+		</table>
+		{{- end }}
+		{{- if .Status.Configuration }}
+		<h2>Configuration</h2>
+		<table>
+			{{- range .Status.Configuration }}
+			<tr><td>{{ .Name }}</td><td>{{ .Value }}</td></tr>
+			{{- end }}
+		</table>
+		{{- end }}
 	</body>
 </html>`
 
-	homepage := homeHandler{}
-
-	tmpl, err := template.New("homepage").Parse(content)
-	if err != nil {
-		return &homepage, fmt.Errorf("failed to load template: %w", err)
+// NewHomePage returns the homepage handler, status is rendered on each request if not nil
+func NewHomePage(version string, metricPath string, status StatusFunc) (*homeHandler, error) {
+	homepage := homeHandler{
+		information: homeInformation{
+			Version:    version,
+			MetricPath: metricPath,
+		},
+		status: status,
 	}
 
-	renderedHTMLBuffer := new(bytes.Buffer)
-
-	err = tmpl.Execute(renderedHTMLBuffer, context)
+	tmpl, err := template.New("homepage").Parse(homepageTemplate)
 	if err != nil {
-		return &homepage, fmt.Errorf("failed to render homepage: %w", err)
+		return &homepage, fmt.Errorf("failed to load template: %w", err)
 	}
 
-	homepage.content = renderedHTMLBuffer.Bytes()
+	homepage.template = tmpl
 
 	return &homepage, nil
 }
 
 func (h homeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	information := h.information
+	if h.status != nil {
+		information.Status = h.status()
+	}
+
+	renderedHTMLBuffer := new(bytes.Buffer)
+
+	err := h.template.Execute(renderedHTMLBuffer, information)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to render homepage: %s", err), http.StatusInternalServerError)
+
+		return
+	}
+
 	w.Header().Add("Content-Type", "text/html; charset=UTF-8")
-	_, _ = w.Write(h.content) // nosemgrep: go.lang.security.audit.xss.no-direct-write-to-responsewriter.no-direct-write-to-responsewriter // h.content is rendered by html/template in constructor
+	_, _ = w.Write(renderedHTMLBuffer.Bytes()) // nosemgrep: go.lang.security.audit.xss.no-direct-write-to-responsewriter.no-direct-write-to-responsewriter // content is rendered by html/template
 }
@@ -0,0 +1,51 @@
+package http_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHomePageStatus(t *testing.T) {
+	status := func() webserver.Status {
+		return webserver.Status{
+			Regions: []webserver.RegionStatus{
+				{
+					Region:    "eu-west-3",
+					AccountID: "123456789012",
+					Sources: []webserver.SourceStatus{
+						{Name: "rds", LastRefresh: time.Now(), Duration: 120 * time.Millisecond},
+						{Name: "servicequotas", Error: "<AccessDenied>"},
+					},
+					APICalls: []webserver.Count{{Name: "rds", Value: 3}},
+					Engines:  []webserver.Count{{Name: "postgres", Value: 2}},
+				},
+			},
+			Collectors:    []webserver.Setting{{Name: "quotas", Value: "true"}},
+			Configuration: []webserver.Setting{{Name: "metric-stream-access-key", Value: "<redacted>"}},
+		}
+	}
+
+	homepage, err := webserver.NewHomePage("1.0.0", "/metrics", status)
+	require.NoError(t, err, "Homepage must be initialized")
+
+	code, body := scrape(t, homepage, "/")
+	assert.Equal(t, http.StatusOK, code, "Homepage should succeed")
+	assert.Contains(t, body, "eu-west-3 (account 123456789012)", "Homepage should contain regions")
+	assert.Contains(t, body, "<td>120ms</td>", "Homepage should contain source durations")
+	assert.Contains(t, body, "never", "Sources never fetched successfully should be reported")
+	assert.Contains(t, body, "&lt;AccessDenied&gt;", "Errors should be escaped")
+	assert.Contains(t, body, "<td>postgres</td><td>2</td>", "Homepage should contain instance count per engine")
+	assert.Contains(t, body, "&lt;redacted&gt;", "Homepage should contain configuration")
+
+	homepage, err = webserver.NewHomePage("1.0.0", "/metrics", nil)
+	require.NoError(t, err, "Homepage without status must be initialized")
+
+	code, body = scrape(t, homepage, "/")
+	assert.Equal(t, http.StatusOK, code, "Homepage without status should succeed")
+	assert.Contains(t, body, "href='/metrics'", "Homepage should link metrics")
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// InventoryPath is the path of the instance inventory API, an instance is served on <InventoryPath>/<dbidentifier>
+const InventoryPath = "/api/v1/instances"
+
+// InventoryInstance is an instance fetched by the last refresh of its collector
+type InventoryInstance struct {
+	AccountID    string
+	Region       string
+	DBIdentifier string
+	Engine       string
+	Tags         map[string]string
+	Endpoint     string            // Address and port of the instance endpoint, empty while the instance is being created
+	Labels       map[string]string // Labels of the instance service discovery target
+	Details      any               // Instance data served as JSON by the inventory API
+}
+
+// InventoryFunc returns instances of all collectors, sorted by identifier
+type InventoryFunc func() []InventoryInstance
+
+// inventoryFilter selects instances by account, region, engines and tags, empty values match all instances
+type inventoryFilter struct {
+	account string
+	region  string
+	engines []string
+	tags    map[string]string
+}
+
+func newInventoryFilter(r *http.Request) (inventoryFilter, error) {
+	query := r.URL.Query()
+
+	filter := inventoryFilter{
+		account: query.Get("account"),
+		region:  query.Get("region"),
+		engines: query["engine"],
+		tags:    make(map[string]string),
+	}
+
+	for _, tag := range query["tag"] {
+		key, value, found := strings.Cut(tag, "=")
+		if !found || key == "" {
+			return filter, fmt.Errorf("invalid tag filter '%s', expected tag=<key>=<value>", tag)
+		}
+
+		filter.tags[key] = value
+	}
+
+	return filter, nil
+}
+
+func (f inventoryFilter) match(instance InventoryInstance) bool {
+	if (f.account != "" && instance.AccountID != f.account) || (f.region != "" && instance.Region != f.region) || !f.matchEngine(instance.Engine) {
+		return false
+	}
+
+	for key, value := range f.tags {
+		if tag, found := instance.Tags[key]; !found || tag != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchEngine returns true if engine is one of the selected engines, so an engine family (eg. postgres and aurora-postgresql) is selected with one request
+func (f inventoryFilter) matchEngine(engine string) bool {
+	if len(f.engines) == 0 {
+		return true
+	}
+
+	for _, e := range f.engines {
+		if e == engine {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selectInstances returns instances matching the request filters
+func selectInstances(r *http.Request, inventory InventoryFunc) ([]InventoryInstance, error) {
+	filter, err := newInventoryFilter(r)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := []InventoryInstance{}
+
+	for _, instance := range inventory() {
+		if filter.match(instance) {
+			instances = append(instances, instance)
+		}
+	}
+
+	return instances, nil
+}
+
+type inventoryResponse struct {
+	Instances []any `json:"instances"`
+}
+
+// inventoryHandler serves instances of collectors as JSON without calling AWS APIs
+type inventoryHandler struct {
+	inventory InventoryFunc
+}
+
+// NewInventoryHandler returns the handler of the instance inventory API, instances are read on each request
+func NewInventoryHandler(inventory InventoryFunc) http.Handler {
+	return inventoryHandler{inventory: inventory}
+}
+
+func (h inventoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	instances, err := selectInstances(r, h.inventory)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	dbidentifier := strings.Trim(strings.TrimPrefix(r.URL.Path, InventoryPath), "/")
+
+	details := []any{}
+
+	for _, instance := range instances {
+		if dbidentifier == "" || instance.DBIdentifier == dbidentifier {
+			details = append(details, instance.Details)
+		}
+	}
+
+	if dbidentifier == "" {
+		writeJSON(w, inventoryResponse{Instances: details})
+
+		return
+	}
+
+	switch len(details) {
+	case 0:
+		http.Error(w, fmt.Sprintf("instance '%s' not found", dbidentifier), http.StatusNotFound)
+	case 1:
+		writeJSON(w, details[0])
+	default:
+		http.Error(w, fmt.Sprintf("instance '%s' exists in several accounts or regions, use account and region parameters", dbidentifier), http.StatusConflict)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,111 @@
+package http_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type instanceDetails struct {
+	DBIdentifier string `json:"dbidentifier"`
+	Region       string `json:"region"`
+	Engine       string `json:"engine"`
+}
+
+func newInventoryInstance(region string, dbidentifier string, engine string, endpoint string) webserver.InventoryInstance {
+	return webserver.InventoryInstance{
+		AccountID:    "123456789012",
+		Region:       region,
+		DBIdentifier: dbidentifier,
+		Engine:       engine,
+		Tags:         map[string]string{"Team": "sre", "Environment": "unittest"},
+		Endpoint:     endpoint,
+		Labels:       map[string]string{"dbidentifier": dbidentifier, "engine": engine},
+		Details:      instanceDetails{DBIdentifier: dbidentifier, Region: region, Engine: engine},
+	}
+}
+
+func newInventory() webserver.InventoryFunc {
+	instances := []webserver.InventoryInstance{
+		newInventoryInstance("eu-west-3", "db-mysql", "mysql", "db-mysql.eu-west-3.rds.amazonaws.com:3306"),
+		newInventoryInstance("eu-west-3", "db-postgres", "postgres", "db-postgres.eu-west-3.rds.amazonaws.com:5432"),
+		newInventoryInstance("eu-west-1", "db-postgres", "aurora-postgresql", "db-postgres.eu-west-1.rds.amazonaws.com:5432"),
+		newInventoryInstance("eu-west-1", "db-creating", "postgres", ""),
+	}
+
+	return func() []webserver.InventoryInstance { return instances }
+}
+
+func getJSON(t *testing.T, handler http.Handler, url string, response any) int {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+
+	if recorder.Code == http.StatusOK {
+		body, err := io.ReadAll(recorder.Result().Body)
+		require.NoError(t, err, "Body must be readable")
+		require.NoError(t, json.Unmarshal(body, response), "Body must be JSON")
+	}
+
+	return recorder.Code
+}
+
+func TestInventoryHandler(t *testing.T) {
+	handler := webserver.NewInventoryHandler(newInventory())
+
+	var list struct {
+		Instances []instanceDetails `json:"instances"`
+	}
+
+	code := getJSON(t, handler, webserver.InventoryPath, &list)
+	assert.Equal(t, http.StatusOK, code, "List should succeed")
+	assert.Len(t, list.Instances, 4, "All instances should be listed")
+
+	code = getJSON(t, handler, webserver.InventoryPath+"?region=eu-west-3&engine=mysql", &list)
+	assert.Equal(t, http.StatusOK, code, "Filtered list should succeed")
+	require.Len(t, list.Instances, 1, "Only matching instances should be listed")
+	assert.Equal(t, "db-mysql", list.Instances[0].DBIdentifier, "MySQL instance expected")
+
+	code = getJSON(t, handler, webserver.InventoryPath+"?engine=postgres&engine=aurora-postgresql", &list)
+	assert.Equal(t, http.StatusOK, code, "Engine family list should succeed")
+	assert.Len(t, list.Instances, 3, "Instances of all selected engines should be listed")
+
+	code = getJSON(t, handler, webserver.InventoryPath+"?tag=Team=sre&tag=Environment=unittest", &list)
+	assert.Equal(t, http.StatusOK, code, "Tag filtered list should succeed")
+	assert.Len(t, list.Instances, 4, "All instances have matching tags")
+
+	code = getJSON(t, handler, webserver.InventoryPath+"?tag=Team=dba", &list)
+	assert.Equal(t, http.StatusOK, code, "Tag filtered list should succeed")
+	assert.Empty(t, list.Instances, "No instance has matching tags")
+
+	code = getJSON(t, handler, webserver.InventoryPath+"?tag=Team", &list)
+	assert.Equal(t, http.StatusBadRequest, code, "Invalid tag filter should fail")
+
+	var instance instanceDetails
+
+	code = getJSON(t, handler, webserver.InventoryPath+"/db-mysql", &instance)
+	assert.Equal(t, http.StatusOK, code, "Instance should be found")
+	assert.Equal(t, "mysql", instance.Engine, "Engine should match")
+	assert.Equal(t, "eu-west-3", instance.Region, "Region should match")
+
+	code = getJSON(t, handler, webserver.InventoryPath+"/db-postgres", &instance)
+	assert.Equal(t, http.StatusConflict, code, "Instance in several regions should be ambiguous")
+
+	code = getJSON(t, handler, webserver.InventoryPath+"/db-postgres?region=eu-west-1", &instance)
+	assert.Equal(t, http.StatusOK, code, "Instance should be found in region")
+	assert.Equal(t, "eu-west-1", instance.Region, "Region should match")
+
+	code = getJSON(t, handler, webserver.InventoryPath+"/unknown", &instance)
+	assert.Equal(t, http.StatusNotFound, code, "Unknown instance should not be found")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, webserver.InventoryPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code, "Only GET should be allowed")
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// DefaultProbeModule is the module used when the probe request has no module parameter
+	DefaultProbeModule = "default"
+
+	// ProbeCollectorTTL is the duration after which collectors that were not probed are removed
+	ProbeCollectorTTL = time.Hour
+
+	// MaxProbeCollectors limits the number of kept collectors, the least recently probed collector is removed first
+	MaxProbeCollectors = 1000
+)
+
+// ErrUnknownProbeModule is returned by probe collector factories when the requested module is not configured
+var ErrUnknownProbeModule = errors.New("unknown probe module")
+
+// ProbeCollectorFactory returns the collector of a target (eg. AWS IAM role ARN) in a region configured by module
+type ProbeCollectorFactory func(target string, region string, module string) (prometheus.Collector, error)
+
+type probeKey struct {
+	target string
+	region string
+	module string
+}
+
+// probeEntry is a collector created once for concurrent probes of the same key
+type probeEntry struct {
+	ready     chan struct{} // Closed once the factory returned
+	collector prometheus.Collector
+	err       error
+	lastProbe time.Time // Protected by the handler mutex
+}
+
+// probeHandler serves metrics of the target, region and module query parameters
+// Collectors are created on first probe and kept so their state (eg. counters, AWS credentials) is reused by following probes
+type probeHandler struct {
+	logger        *slog.Logger
+	defaultRegion string
+	factory       ProbeCollectorFactory
+	mutex         sync.Mutex
+	collectors    map[probeKey]*probeEntry
+}
+
+// NewProbeHandler returns the handler of probe requests, defaultRegion is used when the probe request has no region parameter
+func NewProbeHandler(logger slog.Logger, defaultRegion string, factory ProbeCollectorFactory) http.Handler {
+	return &probeHandler{
+		logger:        &logger,
+		defaultRegion: defaultRegion,
+		factory:       factory,
+		collectors:    make(map[probeKey]*probeEntry),
+	}
+}
+
+func (h *probeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := probeKey{
+		target: r.URL.Query().Get("target"),
+		region: r.URL.Query().Get("region"),
+		module: r.URL.Query().Get("module"),
+	}
+
+	if key.target == "" {
+		http.Error(w, "target parameter is missing", http.StatusBadRequest)
+
+		return
+	}
+
+	if key.region == "" {
+		key.region = h.defaultRegion
+	}
+
+	if key.module == "" {
+		key.module = DefaultProbeModule
+	}
+
+	collector, err := h.getCollector(key)
+	if errors.Is(err, ErrUnknownProbeModule) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if err != nil {
+		h.logger.Error("can't initialize probe collector", "target", key.target, "region", key.region, "module", key.module, "reason", err)
+		http.Error(w, fmt.Sprintf("can't initialize collector of target '%s' in region '%s': %s", key.target, key.region, err), http.StatusInternalServerError)
+
+		return
+	}
+
+	registry := prometheus.NewRegistry()
+
+	err = registry.Register(collector)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("can't register collector: %s", err), http.StatusInternalServerError)
+
+		return
+	}
+
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		ErrorLog:      errorLogger{logger: h.logger},
+		ErrorHandling: promhttp.ContinueOnError,
+	}).ServeHTTP(w, r)
+}
+
+// getCollector returns the collector of key, creating it on first call
+// Collectors are created outside the lock, so slow AWS role assumptions only delay probes of the same key
+// Collectors failing to initialize are not kept so next probes retry
+func (h *probeHandler) getCollector(key probeKey) (prometheus.Collector, error) {
+	now := time.Now()
+
+	h.mutex.Lock()
+
+	entry, found := h.collectors[key]
+	if !found {
+		h.evict(now)
+
+		entry = &probeEntry{ready: make(chan struct{})}
+		h.collectors[key] = entry
+	}
+
+	entry.lastProbe = now
+	h.mutex.Unlock()
+
+	if !found {
+		entry.collector, entry.err = h.factory(key.target, key.region, key.module)
+		if entry.err != nil {
+			h.mutex.Lock()
+			if h.collectors[key] == entry {
+				delete(h.collectors, key)
+			}
+			h.mutex.Unlock()
+		}
+
+		close(entry.ready)
+	}
+
+	<-entry.ready
+
+	return entry.collector, entry.err
+}
+
+// evict removes collectors not probed during ProbeCollectorTTL, then the least recently probed collectors above MaxProbeCollectors
+// Probe parameters are arbitrary, so the number of collectors must be bounded
+func (h *probeHandler) evict(now time.Time) {
+	for key, entry := range h.collectors {
+		if now.Sub(entry.lastProbe) > ProbeCollectorTTL {
+			delete(h.collectors, key)
+		}
+	}
+
+	for len(h.collectors) >= MaxProbeCollectors {
+		var (
+			oldestKey probeKey
+			oldest    *probeEntry
+		)
+
+		for key, entry := range h.collectors {
+			if oldest == nil || entry.lastProbe.Before(oldest.lastProbe) {
+				oldestKey, oldest = key, entry
+			}
+		}
+
+		delete(h.collectors, oldestKey)
+	}
+}
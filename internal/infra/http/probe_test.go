@@ -0,0 +1,153 @@
+package http_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/infra/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errAssumeRole = errors.New("access denied")
+
+func TestProbeHandler(t *testing.T) {
+	log, err := logger.New(false, "text")
+	require.NoError(t, err, "Logger must be initialized")
+
+	calls := 0
+	factory := func(target string, region string, module string) (prometheus.Collector, error) {
+		calls++
+
+		switch {
+		case module != webserver.DefaultProbeModule:
+			return nil, fmt.Errorf("%w: %s", webserver.ErrUnknownProbeModule, module)
+		case target == "denied":
+			return nil, errAssumeRole
+		}
+
+		return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "rds_test_target",
+			ConstLabels: prometheus.Labels{"target": target, "aws_region": region},
+		}, func() float64 { return 1 }), nil
+	}
+
+	handler := webserver.NewProbeHandler(*log, "eu-west-3", factory)
+
+	code, body := scrape(t, handler, "/probe?target=arn:aws:iam::123456789012:role/rds&region=eu-west-1")
+	assert.Equal(t, http.StatusOK, code, "Probe should succeed")
+	assert.Contains(t, body, `aws_region="eu-west-1",target="arn:aws:iam::123456789012:role/rds"`, "Probe should contain target metrics")
+	assert.NotContains(t, body, "go_goroutines", "Probe should not contain process metrics")
+
+	code, _ = scrape(t, handler, "/probe?target=arn:aws:iam::123456789012:role/rds&region=eu-west-1")
+	assert.Equal(t, http.StatusOK, code, "Second probe should succeed")
+	assert.Equal(t, 1, calls, "Collector should be reused by following probes")
+
+	code, body = scrape(t, handler, "/probe?target=arn:aws:iam::123456789012:role/rds")
+	assert.Equal(t, http.StatusOK, code, "Probe without region should succeed")
+	assert.Contains(t, body, `aws_region="eu-west-3"`, "Probe without region should use default region")
+
+	code, _ = scrape(t, handler, "/probe?region=eu-west-1")
+	assert.Equal(t, http.StatusBadRequest, code, "Probe without target should fail")
+
+	code, _ = scrape(t, handler, "/probe?target=arn:aws:iam::123456789012:role/rds&module=unknown")
+	assert.Equal(t, http.StatusBadRequest, code, "Probe with unknown module should fail")
+
+	code, _ = scrape(t, handler, "/probe?target=denied")
+	assert.Equal(t, http.StatusInternalServerError, code, "Probe of a failing target should fail")
+
+	calls = 0
+	scrape(t, handler, "/probe?target=denied")
+	assert.Equal(t, 1, calls, "Failing collectors should not be kept")
+}
+
+func newTargetCollector(target string) prometheus.Collector {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "rds_test_target",
+		ConstLabels: prometheus.Labels{"target": target},
+	}, func() float64 { return 1 })
+}
+
+func TestProbeHandlerConcurrency(t *testing.T) {
+	log, err := logger.New(false, "text")
+	require.NoError(t, err, "Logger must be initialized")
+
+	var slowCalls atomic.Int32
+
+	release := make(chan struct{})
+	factory := func(target string, region string, module string) (prometheus.Collector, error) {
+		if target == "slow" {
+			slowCalls.Add(1)
+			<-release
+		}
+
+		return newTargetCollector(target), nil
+	}
+
+	handler := webserver.NewProbeHandler(*log, "eu-west-3", factory)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			code, _ := scrape(t, handler, "/probe?target=slow")
+			assert.Equal(t, http.StatusOK, code, "Slow probe should succeed")
+		}()
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		code, _ := scrape(t, handler, "/probe?target=fast")
+		assert.Equal(t, http.StatusOK, code, "Fast probe should succeed")
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("Slow collector creation must not block probes of other targets")
+	}
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), slowCalls.Load(), "Concurrent probes of the same target should create one collector")
+}
+
+func TestProbeHandlerEviction(t *testing.T) {
+	log, err := logger.New(false, "text")
+	require.NoError(t, err, "Logger must be initialized")
+
+	calls := make(map[string]int)
+	factory := func(target string, region string, module string) (prometheus.Collector, error) {
+		calls[target]++
+
+		return newTargetCollector(target), nil
+	}
+
+	handler := webserver.NewProbeHandler(*log, "eu-west-3", factory)
+
+	for i := 0; i <= webserver.MaxProbeCollectors; i++ {
+		code, _ := scrape(t, handler, fmt.Sprintf("/probe?target=target%d", i))
+		require.Equal(t, http.StatusOK, code, "Probe should succeed")
+	}
+
+	scrape(t, handler, fmt.Sprintf("/probe?target=target%d", webserver.MaxProbeCollectors))
+	assert.Equal(t, 1, calls[fmt.Sprintf("target%d", webserver.MaxProbeCollectors)], "Recent collectors should be kept")
+
+	scrape(t, handler, "/probe?target=target0")
+	assert.Equal(t, 2, calls["target0"], "Least recently probed collector should be removed above the limit")
+}
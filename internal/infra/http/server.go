@@ -10,11 +10,15 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/TeiNam/prometheus-rds-exporter/internal/infra/build"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/exporter-toolkit/web"
+	"golang.org/x/exp/slices"
 )
 
 const (
@@ -23,7 +27,6 @@ const (
 	IdleTimeout       = 30
 	ReadHeaderTimeout = 2
 	shutdownTimeout   = 5
-	httpErrorExitCode = 4
 )
 
 type Component struct {
@@ -37,6 +40,39 @@ type Config struct {
 	ListenAddress string
 	TLSKeyPath    string
 	TLSCertPath   string
+	WebConfigPath string                  // Prometheus exporter web configuration file enabling TLS and authentication, read again on each connection
+	Targets       func() []Target         // Collectors exposed on MetricPath, read on each request
+	Readiness     func() []ReadinessCheck // Checks of region collectors reported on ReadinessPath, read on each request
+	Status        StatusFunc              // Status of the exporter rendered on the homepage
+	Handlers      map[string]http.Handler // Additional handlers indexed by path
+	PublicPaths   []string                // Paths served without basic authentication of the web configuration file, for clients authenticated otherwise like AWS Firehose
+}
+
+var (
+	// ErrTLSPair is returned when only one of TLS certificate and key is configured
+	ErrTLSPair = errors.New("TLS certificate and key must be set together")
+
+	// ErrTLSConflict is returned when TLS is configured both with a certificate and a web configuration file
+	ErrTLSConflict = errors.New("TLS certificate and web configuration file can't be used together, TLS should be configured in web configuration file")
+)
+
+// ValidateWebConfig checks the web configuration file, its TLS certificates and its users
+func ValidateWebConfig(path string) error {
+	err := web.Validate(path)
+	if err != nil {
+		return fmt.Errorf("invalid web configuration file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// errorLogger logs promhttp errors with the component logger
+type errorLogger struct {
+	logger *slog.Logger
+}
+
+func (l errorLogger) Println(v ...interface{}) {
+	l.logger.Error(fmt.Sprint(v...))
 }
 
 func New(logger slog.Logger, config Config) (component Component) {
@@ -48,53 +84,120 @@ func New(logger slog.Logger, config Config) (component Component) {
 	return
 }
 
-func (c *Component) Start() error {
-	ctx, cancel := context.WithCancel(context.Background())
+// Handler returns the handler routing requests to the homepage, metrics, health, readiness and additional handlers
+func (c *Component) Handler() (http.Handler, error) {
+	homepage, err := NewHomePage(build.Version, c.config.MetricPath, c.config.Status)
+	if err != nil {
+		return nil, fmt.Errorf("hompage initialization failed: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", homepage)
+	metrics := newMetricsHandler(c.config.MetricPath, c.config.Targets, promhttp.HandlerOpts{
+		ErrorLog:      errorLogger{logger: c.logger},
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+	mux.Handle(c.config.MetricPath, metrics)
+
+	if regionPath := strings.TrimSuffix(c.config.MetricPath, "/") + "/"; regionPath != c.config.MetricPath {
+		mux.Handle(regionPath, metrics)
+	}
+
+	mux.Handle(HealthPath, healthHandler{})
+	mux.Handle(ReadinessPath, NewReadinessHandler(c.config.Readiness))
+
+	for path, handler := range c.config.Handlers {
+		mux.Handle(path, handler)
+	}
+
+	return mux, nil
+}
+
+// Start serves requests until ctx is canceled or a stop signal is received
+func (c *Component) Start(ctx context.Context) error {
+	if c.config.WebConfigPath != "" && (c.config.TLSCertPath != "" || c.config.TLSKeyPath != "") {
+		return ErrTLSConflict
+	}
+
+	if (c.config.TLSCertPath == "") != (c.config.TLSKeyPath == "") {
+		return ErrTLSPair
+	}
+
+	handler, err := c.Handler()
+	if err != nil {
+		return err
+	}
+
+	// Requests are canceled once the server is stopped, in-flight requests complete during graceful shutdown
+	baseContext, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	c.server = &http.Server{
 		Addr:              c.config.ListenAddress,
+		Handler:           handler,
 		ReadTimeout:       ReadTimeout * time.Second,
 		WriteTimeout:      WriteTimeout * time.Second,
 		IdleTimeout:       IdleTimeout * time.Second,
 		ReadHeaderTimeout: ReadHeaderTimeout * time.Second,
-		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+		BaseContext:       func(_ net.Listener) context.Context { return baseContext },
 	}
 
-	homepage, err := NewHomePage(build.Version, c.config.MetricPath)
-	if err != nil {
-		return fmt.Errorf("hompage initialization failed: %w", err)
-	}
-
-	http.Handle("/", homepage)
-	http.Handle(c.config.MetricPath, promhttp.Handler())
-
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(
 		signalChan,
-		syscall.SIGHUP,  // kill -SIGHUP XXXX
 		syscall.SIGINT,  // kill -SIGINT XXXX or Ctrl+c
 		syscall.SIGQUIT, // kill -SIGQUIT XXXX
+		syscall.SIGTERM, // kill XXXX, sent by container runtimes and systemd
 	)
 
+	defer signal.Stop(signalChan)
+
+	serverErrors := make(chan error, 1)
+
 	go func() {
 		var err error
 
-		if c.config.TLSCertPath != "" && c.config.TLSKeyPath != "" {
-			c.logger.Info("starting the HTTPS server component")
+		switch {
+		case c.config.WebConfigPath != "":
+			c.logger.Info("starting the web server component with web configuration file", "address", c.config.ListenAddress, "web_config_file", c.config.WebConfigPath)
+
+			var listener net.Listener
+
+			listener, err = net.Listen("tcp", c.config.ListenAddress)
+			if err != nil {
+				break
+			}
+
+			defer listener.Close()
+
+			systemdSocket := false
+			err = web.Serve(&publicPathsListener{Listener: listener, server: c.server, handler: handler, paths: c.config.PublicPaths}, c.server, &web.FlagConfig{
+				WebListenAddresses: &[]string{c.config.ListenAddress},
+				WebSystemdSocket:   &systemdSocket,
+				WebConfigFile:      &c.config.WebConfigPath,
+			}, toolkitLogger{logger: c.logger})
+		case c.config.TLSCertPath != "":
+			c.logger.Info("starting the HTTPS server component", "address", c.config.ListenAddress)
 			err = c.server.ListenAndServeTLS(c.config.TLSCertPath, c.config.TLSKeyPath)
-		} else {
-			c.logger.Info("starting the HTTP server component")
+		default:
+			c.logger.Info("starting the HTTP server component", "address", c.config.ListenAddress)
 			err = c.server.ListenAndServe()
 		}
 
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			c.logger.Error("can't start web server", "reason", err)
-			os.Exit(httpErrorExitCode)
+			serverErrors <- err
 		}
 	}()
 
-	<-signalChan // Wait until program received a stop signal
+	// Wait until program received a stop signal, context is canceled or web server failed
+	select {
+	case err = <-serverErrors:
+		return fmt.Errorf("can't start web server: %w", err)
+	case sig := <-signalChan:
+		c.logger.Info("stop signal received", "signal", sig.String())
+	case <-ctx.Done():
+		c.logger.Info("stop requested")
+	}
 
 	err = c.Stop()
 	if err != nil {
@@ -119,3 +222,63 @@ func (c *Component) Stop() error {
 
 	return nil
 }
+
+// publicPathsListener serves public paths without basic authentication of the web configuration file
+// exporter-toolkit wraps the server handler with authentication right before accepting connections, so public paths are routed around it on first accept
+type publicPathsListener struct {
+	net.Listener
+	once    sync.Once
+	server  *http.Server
+	handler http.Handler
+	paths   []string
+}
+
+func (l *publicPathsListener) Accept() (net.Conn, error) {
+	l.once.Do(func() {
+		authenticated := l.server.Handler
+
+		l.server.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if slices.Contains(l.paths, r.URL.Path) {
+				l.handler.ServeHTTP(w, r)
+
+				return
+			}
+
+			authenticated.ServeHTTP(w, r)
+		})
+	})
+
+	return l.Listener.Accept()
+}
+
+// toolkitLogger forwards logs of exporter-toolkit, written as go-kit key/value pairs, to the component logger
+type toolkitLogger struct {
+	logger *slog.Logger
+}
+
+func (l toolkitLogger) Log(keyvals ...interface{}) error {
+	var (
+		message string
+		attrs   []any
+	)
+
+	logLevel := slog.LevelInfo
+
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		value := keyvals[i+1]
+
+		switch key {
+		case "msg":
+			message = fmt.Sprint(value)
+		case "level":
+			_ = logLevel.UnmarshalText([]byte(fmt.Sprint(value)))
+		default:
+			attrs = append(attrs, key, value)
+		}
+	}
+
+	l.logger.Log(context.Background(), logLevel, message, attrs...)
+
+	return nil
+}
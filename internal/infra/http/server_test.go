@@ -0,0 +1,188 @@
+package http_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+	"github.com/TeiNam/prometheus-rds-exporter/internal/infra/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const metricStreamPath = "/metric-stream"
+
+func newComponent(t *testing.T, config webserver.Config) webserver.Component {
+	t.Helper()
+
+	log, err := logger.New(false, "text")
+	require.NoError(t, err, "Logger must be initialized")
+
+	if config.MetricPath == "" {
+		config.MetricPath = "/metrics"
+	}
+
+	return webserver.New(*log, config)
+}
+
+// freeAddress returns a local address that was available when called
+func freeAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "Local port must be available")
+
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close(), "Listener must be closed")
+
+	return address
+}
+
+func TestStartWithTLSPair(t *testing.T) {
+	testCases := []struct {
+		name     string
+		config   webserver.Config
+		expected error
+	}{
+		{name: "certificate without key", config: webserver.Config{TLSCertPath: "tls.crt"}, expected: webserver.ErrTLSPair},
+		{name: "key without certificate", config: webserver.Config{TLSKeyPath: "tls.key"}, expected: webserver.ErrTLSPair},
+		{name: "certificate with web configuration file", config: webserver.Config{TLSCertPath: "tls.crt", TLSKeyPath: "tls.key", WebConfigPath: "web-config.yaml"}, expected: webserver.ErrTLSConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.config.ListenAddress = freeAddress(t)
+			component := newComponent(t, tc.config)
+
+			err := component.Start(context.Background())
+			assert.ErrorIs(t, err, tc.expected, "Start should fail before listening")
+		})
+	}
+}
+
+func TestStartStopsOnContextCancellation(t *testing.T) {
+	address := freeAddress(t)
+	component := newComponent(t, webserver.Config{ListenAddress: address})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stopped := make(chan error, 1)
+
+	go func() {
+		stopped <- component.Start(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		response, err := http.Get("http://" + address + webserver.HealthPath)
+		if err != nil {
+			return false
+		}
+
+		response.Body.Close()
+
+		return response.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond, "Server should serve requests")
+
+	cancel()
+
+	select {
+	case err := <-stopped:
+		require.NoError(t, err, "Server should stop gracefully")
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "Server should stop when context is canceled")
+	}
+
+	_, err := http.Get("http://" + address + webserver.HealthPath)
+	assert.Error(t, err, "Stopped server should not accept connections")
+}
+
+func TestHandlerRouting(t *testing.T) {
+	metricStream := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	component := newComponent(t, webserver.Config{
+		Targets:  func() []webserver.Target { return []webserver.Target{newTarget("123456789012", "eu-west-3")} },
+		Handlers: map[string]http.Handler{metricStreamPath: metricStream},
+	})
+
+	handler, err := component.Handler()
+	require.NoError(t, err, "Handler must be initialized")
+
+	code, body := scrape(t, handler, "/metrics")
+	assert.Equal(t, http.StatusOK, code, "Metrics path should succeed")
+	assert.Contains(t, body, `aws_region="eu-west-3"`, "Metrics path should serve targets")
+
+	code, body = scrape(t, handler, "/metrics/eu-west-3")
+	assert.Equal(t, http.StatusOK, code, "Region path should succeed")
+	assert.Contains(t, body, `aws_region="eu-west-3"`, "Region path should serve the region target")
+
+	code, _ = scrape(t, handler, metricStreamPath)
+	assert.Equal(t, http.StatusAccepted, code, "Metric stream path should be served by its handler")
+
+	code, _ = scrape(t, handler, webserver.HealthPath)
+	assert.Equal(t, http.StatusOK, code, "Health path should succeed")
+}
+
+func TestPublicPathsWithBasicAuth(t *testing.T) {
+	// bcrypt hash of "fakepassword"
+	webConfig := "basic_auth_users:\n  prometheus: $2y$10$QOauhQNbBCuQDKes6eFzPeMqBSjb7Mr5DUmpZ/VcEd00UAV/LDeSi\n"
+	webConfigPath := filepath.Join(t.TempDir(), "web-config.yaml")
+	require.NoError(t, os.WriteFile(webConfigPath, []byte(webConfig), 0o600), "Web configuration file must be written")
+
+	address := freeAddress(t)
+	metricStream := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	component := newComponent(t, webserver.Config{
+		ListenAddress: address,
+		WebConfigPath: webConfigPath,
+		Handlers:      map[string]http.Handler{metricStreamPath: metricStream},
+		PublicPaths:   []string{metricStreamPath},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+
+	go func() {
+		stopped <- component.Start(ctx)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-stopped, "Server should stop gracefully")
+	})
+
+	request := func(method string, path string, authenticated bool) int {
+		req, err := http.NewRequest(method, "http://"+address+path, nil)
+		require.NoError(t, err, "Request must be created")
+
+		if authenticated {
+			req.SetBasicAuth("prometheus", "fakepassword")
+		}
+
+		response, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return 0
+		}
+
+		response.Body.Close()
+
+		return response.StatusCode
+	}
+
+	require.Eventually(t, func() bool {
+		return request(http.MethodGet, webserver.HealthPath, true) == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond, "Server should serve authenticated requests")
+
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, webserver.HealthPath, false), "Endpoints should require basic authentication")
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/metrics", false), "Metrics should require basic authentication")
+	assert.Equal(t, http.StatusAccepted, request(http.MethodPost, metricStreamPath, false), "Metric stream should be received without basic authentication")
+}
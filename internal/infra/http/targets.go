@@ -0,0 +1,85 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Target is the registry of the collector of an AWS account and region
+type Target struct {
+	AccountID string
+	Region    string
+	Gatherer  prometheus.Gatherer
+}
+
+// metricsHandler serves metrics of all targets and exporter process metrics
+// Metrics of a single account and/or region are served with account and region query parameters or on <metric path>/<region>
+// Targets are read on each request, so collectors can be replaced on configuration reload
+type metricsHandler struct {
+	metricPath string
+	targets    func() []Target
+	opts       promhttp.HandlerOpts
+}
+
+// NewMetricsHandler returns the handler serving metrics of targets on metricPath
+func NewMetricsHandler(metricPath string, targets func() []Target) http.Handler {
+	return newMetricsHandler(metricPath, targets, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+}
+
+func newMetricsHandler(metricPath string, targets func() []Target, opts promhttp.HandlerOpts) *metricsHandler {
+	return &metricsHandler{
+		metricPath: metricPath,
+		targets:    targets,
+		opts:       opts,
+	}
+}
+
+func (h *metricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	region := r.URL.Query().Get("region")
+	account := r.URL.Query().Get("account")
+
+	if pathRegion := strings.Trim(strings.TrimPrefix(r.URL.Path, h.metricPath), "/"); pathRegion != "" {
+		if region != "" && region != pathRegion {
+			http.Error(w, "region in path and region parameter don't match", http.StatusBadRequest)
+
+			return
+		}
+
+		region = pathRegion
+	}
+
+	if region == "" && account == "" {
+		gatherers := prometheus.Gatherers{prometheus.DefaultGatherer}
+		gatherers = append(gatherers, h.selectTargets("", "")...)
+
+		promhttp.HandlerFor(gatherers, h.opts).ServeHTTP(w, r)
+
+		return
+	}
+
+	gatherers := h.selectTargets(account, region)
+	if len(gatherers) == 0 {
+		http.Error(w, fmt.Sprintf("no collector for account '%s' and region '%s'", account, region), http.StatusNotFound)
+
+		return
+	}
+
+	promhttp.HandlerFor(gatherers, h.opts).ServeHTTP(w, r)
+}
+
+// selectTargets returns gatherers of targets matching account and region, empty values match all targets
+func (h *metricsHandler) selectTargets(account string, region string) prometheus.Gatherers {
+	var gatherers prometheus.Gatherers
+
+	for _, target := range h.targets() {
+		if (account == "" || target.AccountID == account) && (region == "" || target.Region == region) {
+			gatherers = append(gatherers, target.Gatherer)
+		}
+	}
+
+	return gatherers
+}
@@ -0,0 +1,67 @@
+package http_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	webserver "github.com/TeiNam/prometheus-rds-exporter/internal/infra/http"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTarget(accountID string, region string) webserver.Target {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "rds_test_target",
+		ConstLabels: prometheus.Labels{"aws_region": region},
+	}, func() float64 { return 1 }))
+
+	return webserver.Target{AccountID: accountID, Region: region, Gatherer: registry}
+}
+
+func scrape(t *testing.T, handler http.Handler, url string) (int, string) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+
+	body, err := io.ReadAll(recorder.Result().Body)
+	require.NoError(t, err, "Body must be readable")
+
+	return recorder.Code, string(body)
+}
+
+func TestMetricsHandlerTargets(t *testing.T) {
+	targets := []webserver.Target{
+		newTarget("123456789012", "eu-west-1"),
+		newTarget("123456789012", "eu-west-3"),
+	}
+
+	handler := webserver.NewMetricsHandler("/metrics", func() []webserver.Target { return targets })
+
+	code, body := scrape(t, handler, "/metrics")
+	assert.Equal(t, http.StatusOK, code, "Combined endpoint should succeed")
+	assert.Contains(t, body, `aws_region="eu-west-1"`, "Combined endpoint should contain all regions")
+	assert.Contains(t, body, `aws_region="eu-west-3"`, "Combined endpoint should contain all regions")
+	assert.Contains(t, body, "go_goroutines", "Combined endpoint should contain process metrics")
+
+	code, body = scrape(t, handler, "/metrics?region=eu-west-1&account=123456789012")
+	assert.Equal(t, http.StatusOK, code, "Target endpoint should succeed")
+	assert.Contains(t, body, `aws_region="eu-west-1"`, "Target endpoint should contain selected region")
+	assert.NotContains(t, body, `aws_region="eu-west-3"`, "Target endpoint should not contain other regions")
+	assert.NotContains(t, body, "go_goroutines", "Target endpoint should not contain process metrics")
+
+	code, body = scrape(t, handler, "/metrics/eu-west-3")
+	assert.Equal(t, http.StatusOK, code, "Region path should succeed")
+	assert.Contains(t, body, `aws_region="eu-west-3"`, "Region path should contain selected region")
+	assert.NotContains(t, body, `aws_region="eu-west-1"`, "Region path should not contain other regions")
+
+	code, _ = scrape(t, handler, "/metrics?region=us-east-1")
+	assert.Equal(t, http.StatusNotFound, code, "Unknown region should not be found")
+
+	code, _ = scrape(t, handler, "/metrics/eu-west-3?region=eu-west-1")
+	assert.Equal(t, http.StatusBadRequest, code, "Conflicting regions should be rejected")
+}
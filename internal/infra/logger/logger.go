@@ -2,11 +2,17 @@
 package logger
 
 import (
+	"io"
 	"log/slog"
 	"os"
 )
 
 func New(debug bool, logFormat string) (*slog.Logger, error) {
+	return NewWithOutput(os.Stdout, debug, logFormat)
+}
+
+// NewWithOutput returns a logger writing to output, used when standard output is reserved for command results
+func NewWithOutput(output io.Writer, debug bool, logFormat string) (*slog.Logger, error) {
 	logLevel := &slog.LevelVar{}
 	if debug {
 		logLevel.Set(slog.LevelDebug)
@@ -16,9 +22,9 @@ func New(debug bool, logFormat string) (*slog.Logger, error) {
 		Level: logLevel,
 	}
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &opts))
+	logger := slog.New(slog.NewTextHandler(output, &opts))
 	if logFormat == "json" {
-		logger = slog.New(slog.NewJSONHandler(os.Stdout, &opts))
+		logger = slog.New(slog.NewJSONHandler(output, &opts))
 	}
 
 	return logger, nil